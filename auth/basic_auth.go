@@ -4,131 +4,168 @@ import (
 	"crypto/subtle"
 	"dameng_exporter/config"
 	"dameng_exporter/logger"
+	"encoding/json"
 	"fmt"
+	"html"
 	"net/http"
 	"strings"
 
 	"golang.org/x/crypto/bcrypt"
 )
 
-// isEncryptedPassword 判断密码是否为bcrypt加密格式
-func isEncryptedPassword(password string) bool {
-	// bcrypt加密后的密码格式: $2a$10$...
-	return strings.HasPrefix(password, "$2a$")
-}
+// authRealm 认证域
+const authRealm = "DAMENG Exporter Metrics"
+
+// reasonNoCredentials 请求未携带认证信息
+const reasonNoCredentials = "no credentials provided"
+
+// jsonPathPrefixes 以 JSON 格式返回认证失败信息的接口路径前缀
+var jsonPathPrefixes = []string{"/api/", "/zabbix/", "/-/loglevel"}
 
-// BasicAuthMiddleware 处理Basic认证的中间件
-func BasicAuthMiddleware(next http.Handler) http.Handler {
+// Require 处理访问认证与授权的中间件：支持用户文件中的多个用户、静态 Bearer 令牌，
+// 以及 basicAuthUsername/basicAuthPassword 配置的单个用户（拥有全部角色）。
+// 未认证返回401，已认证但缺少 role 角色返回403，客户端地址因认证失败过多被锁定时返回429
+func Require(role Role, next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		if !config.Global.GetEnableBasicAuth() {
+		if !config.Global.GetAuthEnabled() {
 			next.ServeHTTP(w, r)
 			return
 		}
 
-		username, password, ok := r.BasicAuth()
-		if !ok {
-			w.Header().Set("WWW-Authenticate", `Basic realm="DAMENG Exporter Metrics"`)
-			w.Header().Set("Content-Type", "text/html; charset=utf-8")
-			w.WriteHeader(http.StatusUnauthorized)
-			fmt.Fprintf(w, `
-				<html>
-					<head>
-						<title>认证失败</title>
-						<style>
-							body { font-family: Arial, sans-serif; margin: 40px; }
-							.error { color: #d32f2f; }
-							.retry { margin-top: 20px; }
-							a { color: #1976d2; text-decoration: none; }
-							a:hover { text-decoration: underline; }
-						</style>
-					</head>
-					<body>
-						<h1 class="error">认证失败</h1>
-						<p>请提供有效的认证信息</p>
-						<div class="retry">
-							<a href="%s">重新登录</a>
-						</div>
-					</body>
-				</html>`, r.URL.Path)
-			logger.Logger.Warn("Basic auth failed: no credentials provided")
+		g := guard
+		client := g.clientIP(r).String()
+		if remaining, locked := g.lockedOut(client); locked {
+			g.denied.WithLabelValues(deniedLockedOut).Inc()
+			w.Header().Set("Retry-After", retryAfter(remaining))
+			writeAuthError(w, r, http.StatusTooManyRequests, "认证失败次数过多，请稍后再试", "too many failed auth attempts, try again later")
 			return
 		}
 
-		// 验证用户名
-		if subtle.ConstantTimeCompare([]byte(username), []byte(config.Global.GetBasicAuthUsername())) != 1 {
-			w.Header().Set("WWW-Authenticate", `Basic realm="DAMENG Exporter Metrics"`)
-			w.Header().Set("Content-Type", "text/html; charset=utf-8")
-			w.WriteHeader(http.StatusUnauthorized)
-			fmt.Fprintf(w, `
-				<html>
-					<head>
-						<title>认证失败</title>
-						<style>
-							body { font-family: Arial, sans-serif; margin: 40px; }
-							.error { color: #d32f2f; }
-							.retry { margin-top: 20px; }
-							a { color: #1976d2; text-decoration: none; }
-							a:hover { text-decoration: underline; }
-						</style>
-					</head>
-					<body>
-						<h1 class="error">认证失败</h1>
-						<p>用户名 '%s' 不正确</p>
-						<div class="retry">
-							<a href="%s">重新登录</a>
-						</div>
-					</body>
-				</html>`, username, r.URL.Path)
-			logger.Logger.Warnf("Basic auth failed: invalid username '%s'", username)
+		p, reason := authenticate(r)
+		if p == nil {
+			g.denied.WithLabelValues(deniedUnauthorized).Inc()
+			// 未携带认证信息（如浏览器首次访问）不计入失败次数
+			if reason != reasonNoCredentials && g.recordFailure(client) {
+				logger.Logger.Warnf("Auth failed for %s %s from %s: %s, client locked out for %v after %d failed attempts",
+					r.Method, r.URL.Path, client, reason, g.lockout, g.limit)
+			} else {
+				logger.Logger.Warnf("Auth failed for %s %s from %s: %s", r.Method, r.URL.Path, client, reason)
+			}
+			writeUnauthorized(w, r, reason)
+			return
+		}
+		g.recordSuccess(client)
+		if !p.has(role) {
+			g.denied.WithLabelValues(deniedForbidden).Inc()
+			logger.Logger.Warnf("Access denied for %s to %s %s: missing role %s", p.name, r.Method, r.URL.Path, role)
+			writeAuthError(w, r, http.StatusForbidden, fmt.Sprintf("用户 '%s' 没有 %s 权限", p.name, role),
+				fmt.Sprintf("%s lacks the %s role", p.name, role))
 			return
 		}
+		next.ServeHTTP(w, r)
+	})
+}
 
-		// 获取配置的密码
-		configuredPassword := config.Global.GetBasicAuthPassword()
-		//logger.Logger.Debugf("Configured password type: %v", isEncryptedPassword(configuredPassword))
-		//logger.Logger.Debugf("Configured password: %s", configuredPassword)
-		//logger.Logger.Debugf("Received password type: %v", isEncryptedPassword(password))
-		//logger.Logger.Debugf("Received password: %s", password)
-
-		// 验证密码
-		var authSuccess bool
-
-		// 如果配置的是加密密码
-		if isEncryptedPassword(configuredPassword) {
-			// 尝试使用bcrypt验证
-			err := bcrypt.CompareHashAndPassword([]byte(configuredPassword), []byte(password))
-			if err == nil {
-				authSuccess = true
-				//logger.Logger.Debugf("Password verified using bcrypt")
-			} else {
-				// 如果bcrypt验证失败，且传入的也是加密密码，尝试直接比较
-				if isEncryptedPassword(password) {
-					authSuccess = subtle.ConstantTimeCompare([]byte(password), []byte(configuredPassword)) == 1
-					//logger.Logger.Debugf("Comparing encrypted passwords directly")
-				}
-			}
-		} else {
-			// 如果配置的是明文密码
-			if isEncryptedPassword(password) {
-				// 如果传入的是加密密码，尝试用传入的密文验证配置的明文
-				err := bcrypt.CompareHashAndPassword([]byte(password), []byte(configuredPassword))
-				authSuccess = err == nil
-				//logger.Logger.Debugf("Verifying plain password against encrypted password")
-			} else {
-				// 如果传入的也是明文，直接比较
-				authSuccess = subtle.ConstantTimeCompare([]byte(password), []byte(configuredPassword)) == 1
-				//logger.Logger.Debugf("Comparing plain passwords directly")
-			}
+// authenticate 根据请求头认证访问者，失败时返回 nil 与原因
+func authenticate(r *http.Request) (*principal, string) {
+	if header := r.Header.Get("Authorization"); len(header) > 7 && strings.EqualFold(header[:7], "Bearer ") {
+		if p := store.authenticateToken(strings.TrimSpace(header[7:])); p != nil {
+			return p, ""
+		}
+		return nil, "invalid bearer token"
+	}
+
+	username, password, ok := r.BasicAuth()
+	if !ok {
+		return nil, reasonNoCredentials
+	}
+	if p, known := store.authenticateUser(username, password); known {
+		if p == nil {
+			return nil, fmt.Sprintf("invalid password for user '%s'", username)
 		}
+		return p, ""
+	}
+
+	// 单用户配置
+	if !config.Global.GetEnableBasicAuth() ||
+		subtle.ConstantTimeCompare([]byte(username), []byte(config.Global.GetBasicAuthUsername())) != 1 {
+		return nil, fmt.Sprintf("invalid username '%s'", username)
+	}
+	if !verifyPassword(username, config.Global.GetBasicAuthPassword(), password) {
+		return nil, fmt.Sprintf("invalid password for user '%s'", username)
+	}
+	return &principal{name: username, roles: map[Role]bool{RoleAdmin: true}}, ""
+}
 
-		if !authSuccess {
-			w.Header().Set("WWW-Authenticate", `Basic realm="DAMENG Exporter Metrics"`)
-			w.Header().Set("Content-Type", "text/html; charset=utf-8")
-			w.WriteHeader(http.StatusUnauthorized)
-			fmt.Fprintf(w, `
+// verifyPassword 校验单用户配置的密码：配置为支持的哈希格式时按对应算法校验明文密码，否则按明文比较。
+// 开启 basicAuthLegacyHashCompare 时兼容旧版本的行为，接受客户端直接发送配置中的 bcrypt 哈希或配置明文的 bcrypt 哈希
+func verifyPassword(username, configuredPassword, password string) bool {
+	if IsPasswordHash(configuredPassword) {
+		ok, err := verifyHashedPassword(configuredPassword, password)
+		if err != nil {
+			logger.Logger.Errorf("Invalid password hash configured for user '%s': %v", username, err)
+		}
+		if ok {
+			return true
+		}
+	} else if subtle.ConstantTimeCompare([]byte(password), []byte(configuredPassword)) == 1 {
+		return true
+	}
+
+	if !legacyHashMatch(configuredPassword, password) {
+		return false
+	}
+	if config.Global.GetBasicAuthLegacyHashCompare() {
+		logger.Logger.Warnf("User '%s' authenticated by sending a password hash instead of the password, update the client and disable basicAuthLegacyHashCompare", username)
+		return true
+	}
+	logger.Logger.Warnf("Rejected a password hash sent as the password for user '%s', send the plaintext password instead (earlier versions accepted it, set basicAuthLegacyHashCompare=true to allow temporarily)", username)
+	return false
+}
+
+// legacyHashMatch 判断密码是否只被旧版本接受：与配置中的 bcrypt 哈希相同，或是配置明文的 bcrypt 哈希
+func legacyHashMatch(configuredPassword, password string) bool {
+	bc := bcryptVerifier{}
+	if !bc.Match(password) {
+		return false
+	}
+	if bc.Match(configuredPassword) {
+		return subtle.ConstantTimeCompare([]byte(password), []byte(configuredPassword)) == 1
+	}
+	ok, _ := bc.Verify(password, configuredPassword)
+	return ok
+}
+
+// writeUnauthorized 返回401及认证方式提示
+func writeUnauthorized(w http.ResponseWriter, r *http.Request, reason string) {
+	w.Header().Add("WWW-Authenticate", fmt.Sprintf(`Basic realm="%s"`, authRealm))
+	if store.hasTokens() {
+		w.Header().Add("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s"`, authRealm))
+	}
+	writeAuthError(w, r, http.StatusUnauthorized, "请提供有效的认证信息", reason)
+}
+
+// writeAuthError 输出认证失败信息：接口路径返回 JSON，其他路径返回 HTML 页面
+func writeAuthError(w http.ResponseWriter, r *http.Request, status int, message, reason string) {
+	for _, prefix := range jsonPathPrefixes {
+		if strings.HasPrefix(r.URL.Path, prefix) {
+			w.Header().Set("Content-Type", "application/json; charset=utf-8")
+			w.WriteHeader(status)
+			json.NewEncoder(w).Encode(map[string]string{"error": reason})
+			return
+		}
+	}
+
+	title := "认证失败"
+	if status == http.StatusForbidden {
+		title = "权限不足"
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	fmt.Fprintf(w, `
 				<html>
 					<head>
-						<title>认证失败</title>
+						<title>%s</title>
 						<style>
 							body { font-family: Arial, sans-serif; margin: 40px; }
 							.error { color: #d32f2f; }
@@ -138,19 +175,13 @@ func BasicAuthMiddleware(next http.Handler) http.Handler {
 						</style>
 					</head>
 					<body>
-						<h1 class="error">认证失败</h1>
-						<p>用户 '%s' 的密码不正确</p>
+						<h1 class="error">%s</h1>
+						<p>%s</p>
 						<div class="retry">
 							<a href="%s">重新登录</a>
 						</div>
 					</body>
-				</html>`, username, r.URL.Path)
-			logger.Logger.Warnf("Basic auth failed: invalid password for user '%s'", username)
-			return
-		}
-
-		next.ServeHTTP(w, r)
-	})
+				</html>`, title, title, html.EscapeString(message), html.EscapeString(r.URL.Path))
 }
 
 // GenerateBcryptPassword 生成bcrypt加密的密码
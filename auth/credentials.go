@@ -0,0 +1,242 @@
+package auth
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"crypto/subtle"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+// Role 访问角色
+type Role string
+
+// 访问角色，admin 拥有全部权限
+const (
+	RoleMetrics Role = "metrics" // 抓取指标与 Zabbix 发现数据
+	RoleRead    Role = "read"    // 查看状态页、数据源清单与事件历史
+	RoleAdmin   Role = "admin"   // 调用数据源运维接口
+)
+
+// 未在文件中指定角色时的默认角色
+var (
+	defaultUserRoles  = []Role{RoleMetrics, RoleRead}
+	defaultTokenRoles = []Role{RoleMetrics}
+)
+
+// principal 已认证的访问者
+type principal struct {
+	name  string
+	roles map[Role]bool
+}
+
+// has 判断是否拥有角色
+func (p *principal) has(role Role) bool {
+	return p.roles[RoleAdmin] || p.roles[role]
+}
+
+// fileUser 用户文件中的用户
+type fileUser struct {
+	hash  []byte
+	roles map[Role]bool
+}
+
+// tokenEntry 令牌文件中的令牌，只保存令牌的摘要
+type tokenEntry struct {
+	name   string
+	digest [sha256.Size]byte
+	roles  map[Role]bool
+}
+
+// credentialStore 从文件加载的用户与令牌
+type credentialStore struct {
+	mu       sync.RWMutex
+	users    map[string]*fileUser
+	tokens   []tokenEntry
+	verified map[string][sha256.Size]byte // 用户名 -> 最近一次验证通过的密码摘要，避免每次请求都计算密码哈希
+}
+
+var store = &credentialStore{}
+
+// LoadCredentials 加载用户文件与令牌文件，路径为空时跳过；任一文件格式错误时返回错误且保留原有凭据
+func LoadCredentials(usersFile, tokensFile string) error {
+	var users map[string]*fileUser
+	var tokens []tokenEntry
+	var err error
+	if usersFile != "" {
+		if users, err = loadUsersFile(usersFile); err != nil {
+			return err
+		}
+	}
+	if tokensFile != "" {
+		if tokens, err = loadTokensFile(tokensFile); err != nil {
+			return err
+		}
+	}
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	store.users = users
+	store.tokens = tokens
+	store.verified = make(map[string][sha256.Size]byte)
+	return nil
+}
+
+// CredentialCounts 返回已加载的用户与令牌数量
+func CredentialCounts() (int, int) {
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+	return len(store.users), len(store.tokens)
+}
+
+// loadUsersFile 加载 htpasswd 格式的用户文件，每行 "用户名:密码哈希[:角色1,角色2]"，# 开头为注释，
+// 密码哈希支持 bcrypt、argon2id 与 sha512-crypt
+func loadUsersFile(path string) (map[string]*fileUser, error) {
+	users := make(map[string]*fileUser)
+	err := readCredentialLines(path, func(fields []string) error {
+		if len(fields) < 2 || fields[0] == "" {
+			return fmt.Errorf("expected username:password_hash[:roles]")
+		}
+		if !IsPasswordHash(fields[1]) {
+			return fmt.Errorf("password of user %q is not a bcrypt, argon2id or sha512-crypt hash", fields[0])
+		}
+		if _, err := verifyHashedPassword(fields[1], ""); err != nil {
+			return fmt.Errorf("invalid password hash of user %q: %w", fields[0], err)
+		}
+		if _, ok := users[fields[0]]; ok {
+			return fmt.Errorf("duplicate user %q", fields[0])
+		}
+		roles, err := parseRoles(fields, defaultUserRoles)
+		if err != nil {
+			return err
+		}
+		users[fields[0]] = &fileUser{hash: []byte(fields[1]), roles: roles}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("users file %s: %w", path, err)
+	}
+	return users, nil
+}
+
+// loadTokensFile 加载令牌文件，每行 "名称:令牌[:角色1,角色2]"，# 开头为注释
+func loadTokensFile(path string) ([]tokenEntry, error) {
+	var tokens []tokenEntry
+	seen := make(map[string]bool)
+	err := readCredentialLines(path, func(fields []string) error {
+		if len(fields) < 2 || fields[0] == "" || fields[1] == "" {
+			return fmt.Errorf("expected name:token[:roles]")
+		}
+		if seen[fields[0]] {
+			return fmt.Errorf("duplicate token name %q", fields[0])
+		}
+		seen[fields[0]] = true
+		roles, err := parseRoles(fields, defaultTokenRoles)
+		if err != nil {
+			return err
+		}
+		tokens = append(tokens, tokenEntry{name: fields[0], digest: sha256.Sum256([]byte(fields[1])), roles: roles})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("tokens file %s: %w", path, err)
+	}
+	return tokens, nil
+}
+
+// readCredentialLines 逐行读取凭据文件，跳过空行与注释，按冒号拆分为最多3列
+func readCredentialLines(path string, handle func(fields []string) error) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.SplitN(line, ":", 3)
+		for i := range fields {
+			fields[i] = strings.TrimSpace(fields[i])
+		}
+		if err := handle(fields); err != nil {
+			return fmt.Errorf("line %d: %w", lineNo, err)
+		}
+	}
+	return scanner.Err()
+}
+
+// parseRoles 解析第3列的角色列表，未指定时使用默认角色
+func parseRoles(fields []string, defaults []Role) (map[Role]bool, error) {
+	roles := make(map[Role]bool)
+	if len(fields) < 3 || fields[2] == "" {
+		for _, r := range defaults {
+			roles[r] = true
+		}
+		return roles, nil
+	}
+	for _, name := range strings.Split(fields[2], ",") {
+		role := Role(strings.TrimSpace(name))
+		switch role {
+		case RoleMetrics, RoleRead, RoleAdmin:
+			roles[role] = true
+		case "":
+		default:
+			return nil, fmt.Errorf("unknown role %q (must be metrics, read or admin)", role)
+		}
+	}
+	return roles, nil
+}
+
+// authenticateUser 使用用户文件验证用户名与密码，用户不在文件中时 known 为 false
+func (s *credentialStore) authenticateUser(username, password string) (p *principal, known bool) {
+	s.mu.RLock()
+	user, ok := s.users[username]
+	cached, hit := s.verified[username]
+	s.mu.RUnlock()
+	if !ok {
+		return nil, false
+	}
+
+	digest := sha256.Sum256(append(append([]byte{}, user.hash...), password...))
+	if !hit || subtle.ConstantTimeCompare(cached[:], digest[:]) != 1 {
+		if ok, _ := verifyHashedPassword(string(user.hash), password); !ok {
+			return nil, true
+		}
+		s.mu.Lock()
+		if s.verified != nil {
+			s.verified[username] = digest
+		}
+		s.mu.Unlock()
+	}
+	return &principal{name: username, roles: user.roles}, true
+}
+
+// authenticateToken 验证 Bearer 令牌
+func (s *credentialStore) authenticateToken(token string) *principal {
+	digest := sha256.Sum256([]byte(token))
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var matched *principal
+	// 逐个比较全部令牌，避免通过响应时间推测令牌位置
+	for i := range s.tokens {
+		if subtle.ConstantTimeCompare(s.tokens[i].digest[:], digest[:]) == 1 && matched == nil {
+			matched = &principal{name: s.tokens[i].name, roles: s.tokens[i].roles}
+		}
+	}
+	return matched
+}
+
+// hasTokens 判断是否加载了令牌
+func (s *credentialStore) hasTokens() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.tokens) > 0
+}
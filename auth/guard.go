@@ -0,0 +1,283 @@
+package auth
+
+import (
+	"dameng_exporter/config"
+	"dameng_exporter/logger"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// 请求被拒绝的原因
+const (
+	deniedIP           = "ip_denied"    // 客户端地址不在访问规则允许范围内
+	deniedLockedOut    = "locked_out"   // 认证失败次数过多，处于锁定期
+	deniedUnauthorized = "unauthorized" // 未提供或提供了无效的认证信息
+	deniedForbidden    = "forbidden"    // 已认证但缺少角色
+)
+
+// maxTrackedClients 记录认证失败的客户端数量上限，超出时清理过期记录
+const maxTrackedClients = 10000
+
+// accessRule 解析后的访问规则
+type accessRule struct {
+	name  string
+	paths []string
+	allow []*net.IPNet
+	deny  []*net.IPNet
+}
+
+// failureRecord 单个客户端地址的认证失败记录
+type failureRecord struct {
+	count       int
+	windowStart time.Time
+	lockedUntil time.Time
+}
+
+// Guard 按客户端地址执行访问规则与认证失败锁定，并统计被拒绝的请求
+type Guard struct {
+	rules   []accessRule
+	trusted []*net.IPNet
+	limit   int
+	window  time.Duration
+	lockout time.Duration
+
+	mu       sync.Mutex
+	failures map[string]*failureRecord
+
+	denied *prometheus.CounterVec
+	locked *prometheus.Desc
+}
+
+// guard 当前生效的访问控制，未配置时不限制地址且不锁定
+var guard = newGuard(nil, nil, 0, 0, 0)
+
+// newGuard 创建访问控制
+func newGuard(rules []accessRule, trusted []*net.IPNet, limit int, window, lockout time.Duration) *Guard {
+	g := &Guard{
+		rules:    rules,
+		trusted:  trusted,
+		limit:    limit,
+		window:   window,
+		lockout:  lockout,
+		failures: make(map[string]*failureRecord),
+		denied: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "dameng_exporter_http_denied_requests_total",
+			Help: "Total number of HTTP requests denied by reason (ip_denied, locked_out, unauthorized, forbidden)",
+		}, []string{"reason"}),
+		locked: prometheus.NewDesc(
+			"dameng_exporter_auth_locked_clients",
+			"Current number of client addresses locked out after too many failed auth attempts",
+			nil, nil,
+		),
+	}
+	// 预先初始化各分类，保证从0开始输出
+	for _, reason := range []string{deniedIP, deniedLockedOut, deniedUnauthorized, deniedForbidden} {
+		g.denied.WithLabelValues(reason)
+	}
+	return g
+}
+
+// SetupGuard 根据访问规则、可信代理与锁定参数创建访问控制并使其生效，返回值用于注册自监控指标
+func SetupGuard(rules []config.AccessRuleConfig, trustedProxies []string, limit int, window, lockout time.Duration) (*Guard, error) {
+	trusted, err := config.ParseCIDRs(trustedProxies)
+	if err != nil {
+		return nil, fmt.Errorf("trustedProxies: %w", err)
+	}
+	parsed := make([]accessRule, 0, len(rules))
+	for _, r := range rules {
+		allow, err := config.ParseCIDRs(r.Allow)
+		if err != nil {
+			return nil, fmt.Errorf("accessRule %s: %w", r.Name, err)
+		}
+		deny, err := config.ParseCIDRs(r.Deny)
+		if err != nil {
+			return nil, fmt.Errorf("accessRule %s: %w", r.Name, err)
+		}
+		parsed = append(parsed, accessRule{name: r.Name, paths: r.Paths, allow: allow, deny: deny})
+	}
+	guard = newGuard(parsed, trusted, limit, window, lockout)
+	return guard, nil
+}
+
+// Describe 实现 prometheus.Collector 接口
+func (g *Guard) Describe(ch chan<- *prometheus.Desc) {
+	g.denied.Describe(ch)
+	ch <- g.locked
+}
+
+// Collect 实现 prometheus.Collector 接口
+func (g *Guard) Collect(ch chan<- prometheus.Metric) {
+	g.denied.Collect(ch)
+	now := time.Now()
+	g.mu.Lock()
+	locked := 0
+	for _, f := range g.failures {
+		if now.Before(f.lockedUntil) {
+			locked++
+		}
+	}
+	g.mu.Unlock()
+	ch <- prometheus.MustNewConstMetric(g.locked, prometheus.GaugeValue, float64(locked))
+}
+
+// AccessControl 按访问规则检查客户端地址的中间件，应包裹整个路由，对探针与全部接口生效
+func AccessControl(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		g := guard
+		if len(g.rules) == 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
+		ip := g.clientIP(r)
+		if rule, ok := g.allowed(ip, r.URL.Path); !ok {
+			g.denied.WithLabelValues(deniedIP).Inc()
+			logger.Logger.Debugf("Request %s %s from %s denied by access rule %s", r.Method, r.URL.Path, ip, rule)
+			writeAuthError(w, r, http.StatusForbidden, "客户端地址不允许访问", "client address not allowed")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// allowed 使用第一条匹配路径的规则检查地址，返回生效的规则名称；没有匹配的规则时允许访问
+func (g *Guard) allowed(ip net.IP, path string) (string, bool) {
+	for _, rule := range g.rules {
+		if !matchPath(rule.paths, path) {
+			continue
+		}
+		if ip == nil || containsIP(rule.deny, ip) {
+			return rule.name, false
+		}
+		return rule.name, len(rule.allow) == 0 || containsIP(rule.allow, ip)
+	}
+	return "", true
+}
+
+// matchPath 判断路径是否匹配任一前缀，前缀列表为空时匹配全部路径
+func matchPath(prefixes []string, path string) bool {
+	if len(prefixes) == 0 {
+		return true
+	}
+	for _, p := range prefixes {
+		if strings.HasPrefix(path, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// containsIP 判断地址是否位于任一地址段中
+func containsIP(nets []*net.IPNet, ip net.IP) bool {
+	for _, n := range nets {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// clientIP 返回客户端地址：直连地址为可信代理时，从 X-Forwarded-For 自右向左取第一个非可信代理的地址，
+// 没有该请求头时使用 X-Real-IP
+func (g *Guard) clientIP(r *http.Request) net.IP {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || !containsIP(g.trusted, ip) {
+		return ip
+	}
+
+	if forwarded := r.Header.Values("X-Forwarded-For"); len(forwarded) > 0 {
+		hops := strings.Split(strings.Join(forwarded, ","), ",")
+		for i := len(hops) - 1; i >= 0; i-- {
+			hop := net.ParseIP(strings.TrimSpace(hops[i]))
+			if hop == nil {
+				// 无法解析的地址之前的内容不可信，使用最后一个可信代理的地址
+				return ip
+			}
+			ip = hop
+			if !containsIP(g.trusted, hop) {
+				return hop
+			}
+		}
+		return ip
+	}
+	if realIP := net.ParseIP(strings.TrimSpace(r.Header.Get("X-Real-IP"))); realIP != nil {
+		return realIP
+	}
+	return ip
+}
+
+// lockedOut 判断客户端地址是否处于锁定期，返回剩余锁定时间
+func (g *Guard) lockedOut(key string) (time.Duration, bool) {
+	if g.limit <= 0 {
+		return 0, false
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	f, ok := g.failures[key]
+	if !ok {
+		return 0, false
+	}
+	remaining := time.Until(f.lockedUntil)
+	return remaining, remaining > 0
+}
+
+// recordFailure 记录一次认证失败，达到失败次数上限时锁定客户端地址并返回 true
+func (g *Guard) recordFailure(key string) bool {
+	if g.limit <= 0 {
+		return false
+	}
+	now := time.Now()
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if len(g.failures) >= maxTrackedClients {
+		g.removeExpiredLocked(now)
+	}
+	f, ok := g.failures[key]
+	if !ok || now.Sub(f.windowStart) > g.window {
+		f = &failureRecord{windowStart: now}
+		g.failures[key] = f
+	}
+	f.count++
+	if f.count < g.limit {
+		return false
+	}
+	f.count = 0
+	f.windowStart = now
+	f.lockedUntil = now.Add(g.lockout)
+	return true
+}
+
+// recordSuccess 认证成功后清除客户端地址的失败记录
+func (g *Guard) recordSuccess(key string) {
+	if g.limit <= 0 {
+		return
+	}
+	g.mu.Lock()
+	delete(g.failures, key)
+	g.mu.Unlock()
+}
+
+// removeExpiredLocked 清理统计窗口与锁定期均已结束的记录，调用方需持有锁
+func (g *Guard) removeExpiredLocked(now time.Time) {
+	for key, f := range g.failures {
+		if now.Sub(f.windowStart) > g.window && !now.Before(f.lockedUntil) {
+			delete(g.failures, key)
+		}
+	}
+}
+
+// retryAfter 格式化 Retry-After 响应头的秒数
+func retryAfter(d time.Duration) string {
+	return strconv.Itoa(int(d.Seconds()) + 1)
+}
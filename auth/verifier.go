@@ -0,0 +1,243 @@
+package auth
+
+import (
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// PasswordVerifier 密码哈希校验器，只接受明文密码，不接受哈希值本身
+type PasswordVerifier interface {
+	// Name 哈希算法名称
+	Name() string
+	// Match 判断哈希是否为该算法的格式
+	Match(hash string) bool
+	// Verify 校验明文密码是否与哈希匹配，哈希格式错误时返回错误
+	Verify(hash, password string) (bool, error)
+}
+
+// verifiers 支持的密码哈希算法
+var verifiers = []PasswordVerifier{
+	bcryptVerifier{},
+	argon2idVerifier{},
+	sha512CryptVerifier{},
+}
+
+// findVerifier 返回与哈希格式匹配的校验器，不是已知哈希格式时返回 nil
+func findVerifier(hash string) PasswordVerifier {
+	for _, v := range verifiers {
+		if v.Match(hash) {
+			return v
+		}
+	}
+	return nil
+}
+
+// IsPasswordHash 判断字符串是否为支持的密码哈希格式
+func IsPasswordHash(s string) bool {
+	return findVerifier(s) != nil
+}
+
+// verifyHashedPassword 使用对应算法校验明文密码与哈希
+func verifyHashedPassword(hash, password string) (bool, error) {
+	v := findVerifier(hash)
+	if v == nil {
+		return false, fmt.Errorf("unsupported password hash format")
+	}
+	return v.Verify(hash, password)
+}
+
+// bcryptVerifier bcrypt 哈希（$2a$/$2b$/$2y$），htpasswd -B 与 --encryptBasicAuthPwd 生成的格式
+type bcryptVerifier struct{}
+
+func (bcryptVerifier) Name() string { return "bcrypt" }
+
+func (bcryptVerifier) Match(hash string) bool {
+	return strings.HasPrefix(hash, "$2a$") || strings.HasPrefix(hash, "$2b$") || strings.HasPrefix(hash, "$2y$")
+}
+
+func (bcryptVerifier) Verify(hash, password string) (bool, error) {
+	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+	switch err {
+	case nil:
+		return true, nil
+	case bcrypt.ErrMismatchedHashAndPassword:
+		return false, nil
+	default:
+		return false, err
+	}
+}
+
+// argon2idVerifier argon2id 哈希，PHC 格式：$argon2id$v=19$m=65536,t=3,p=4$<salt>$<hash>
+type argon2idVerifier struct{}
+
+func (argon2idVerifier) Name() string { return "argon2id" }
+
+func (argon2idVerifier) Match(hash string) bool {
+	return strings.HasPrefix(hash, "$argon2id$")
+}
+
+func (argon2idVerifier) Verify(hash, password string) (bool, error) {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 || parts[2] != "v=19" {
+		return false, fmt.Errorf("invalid argon2id hash, expected $argon2id$v=19$m=...,t=...,p=...$salt$hash")
+	}
+	var memory, iterations uint32
+	var threads uint8
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &memory, &iterations, &threads); err != nil {
+		return false, fmt.Errorf("invalid argon2id parameters %q", parts[3])
+	}
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return false, fmt.Errorf("invalid argon2id salt: %w", err)
+	}
+	expected, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(expected) == 0 {
+		return false, fmt.Errorf("invalid argon2id hash value")
+	}
+	actual := argon2.IDKey([]byte(password), salt, iterations, memory, threads, uint32(len(expected)))
+	return subtle.ConstantTimeCompare(actual, expected) == 1, nil
+}
+
+// sha512CryptVerifier SHA-512 crypt 哈希（$6$），/etc/shadow、openssl passwd -6 与 htpasswd -2 生成的格式
+type sha512CryptVerifier struct{}
+
+const (
+	sha512CryptDefaultRounds = 5000
+	sha512CryptMinRounds     = 1000
+	sha512CryptMaxRounds     = 999999999
+	sha512CryptMaxSalt       = 16
+	// cryptAlphabet crypt 使用的 base64 字母表
+	cryptAlphabet = "./0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+)
+
+func (sha512CryptVerifier) Name() string { return "sha512-crypt" }
+
+func (sha512CryptVerifier) Match(hash string) bool {
+	return strings.HasPrefix(hash, "$6$")
+}
+
+func (sha512CryptVerifier) Verify(hash, password string) (bool, error) {
+	rest := strings.TrimPrefix(hash, "$6$")
+	rounds, customRounds := sha512CryptDefaultRounds, false
+	if strings.HasPrefix(rest, "rounds=") {
+		end := strings.IndexByte(rest, '$')
+		if end < 0 {
+			return false, fmt.Errorf("invalid sha512-crypt hash")
+		}
+		n, err := strconv.Atoi(rest[len("rounds="):end])
+		if err != nil {
+			return false, fmt.Errorf("invalid sha512-crypt rounds: %w", err)
+		}
+		rounds = min(max(n, sha512CryptMinRounds), sha512CryptMaxRounds)
+		customRounds = true
+		rest = rest[end+1:]
+	}
+	end := strings.LastIndexByte(rest, '$')
+	if end < 0 {
+		return false, fmt.Errorf("invalid sha512-crypt hash")
+	}
+	salt := rest[:end]
+	if len(salt) > sha512CryptMaxSalt {
+		salt = salt[:sha512CryptMaxSalt]
+	}
+	computed := sha512Crypt([]byte(password), []byte(salt), rounds, customRounds)
+	return subtle.ConstantTimeCompare([]byte(computed), []byte(hash)) == 1, nil
+}
+
+// sha512Crypt 按 Ulrich Drepper 的 SHA-crypt 规范计算 $6$ 哈希
+func sha512Crypt(password, salt []byte, rounds int, customRounds bool) string {
+	b := sha512.New()
+	b.Write(password)
+	b.Write(salt)
+	b.Write(password)
+	digestB := b.Sum(nil)
+
+	a := sha512.New()
+	a.Write(password)
+	a.Write(salt)
+	a.Write(repeatBytes(digestB, len(password)))
+	for n := len(password); n > 0; n >>= 1 {
+		if n&1 != 0 {
+			a.Write(digestB)
+		} else {
+			a.Write(password)
+		}
+	}
+	digestA := a.Sum(nil)
+
+	dp := sha512.New()
+	for range password {
+		dp.Write(password)
+	}
+	p := repeatBytes(dp.Sum(nil), len(password))
+
+	ds := sha512.New()
+	for i := 0; i < 16+int(digestA[0]); i++ {
+		ds.Write(salt)
+	}
+	s := repeatBytes(ds.Sum(nil), len(salt))
+
+	c := digestA
+	for i := 0; i < rounds; i++ {
+		h := sha512.New()
+		if i&1 != 0 {
+			h.Write(p)
+		} else {
+			h.Write(c)
+		}
+		if i%3 != 0 {
+			h.Write(s)
+		}
+		if i%7 != 0 {
+			h.Write(p)
+		}
+		if i&1 != 0 {
+			h.Write(c)
+		} else {
+			h.Write(p)
+		}
+		c = h.Sum(nil)
+	}
+
+	var sb strings.Builder
+	sb.WriteString("$6$")
+	if customRounds {
+		sb.WriteString("rounds=" + strconv.Itoa(rounds) + "$")
+	}
+	sb.Write(salt)
+	sb.WriteByte('$')
+	order := [][3]int{
+		{0, 21, 42}, {22, 43, 1}, {44, 2, 23}, {3, 24, 45}, {25, 46, 4}, {47, 5, 26}, {6, 27, 48},
+		{28, 49, 7}, {50, 8, 29}, {9, 30, 51}, {31, 52, 10}, {53, 11, 32}, {12, 33, 54}, {34, 55, 13},
+		{56, 14, 35}, {15, 36, 57}, {37, 58, 16}, {59, 17, 38}, {18, 39, 60}, {40, 61, 19}, {62, 20, 41},
+	}
+	for _, o := range order {
+		writeCrypt64(&sb, uint(c[o[0]])<<16|uint(c[o[1]])<<8|uint(c[o[2]]), 4)
+	}
+	writeCrypt64(&sb, uint(c[63]), 2)
+	return sb.String()
+}
+
+// repeatBytes 重复摘要直到指定长度
+func repeatBytes(digest []byte, n int) []byte {
+	out := make([]byte, 0, n)
+	for len(out) < n {
+		out = append(out, digest[:min(len(digest), n-len(out))]...)
+	}
+	return out
+}
+
+// writeCrypt64 以 crypt 字母表输出 w 的低 n*6 位，低位在前
+func writeCrypt64(sb *strings.Builder, w uint, n int) {
+	for ; n > 0; n-- {
+		sb.WriteByte(cryptAlphabet[w&0x3f])
+		w >>= 6
+	}
+}
@@ -0,0 +1,159 @@
+package auth
+
+import "testing"
+
+// TestSha512Crypt 使用 Ulrich Drepper 的 SHA-crypt 规范中的 $6$ 测试向量
+func TestSha512Crypt(t *testing.T) {
+	tests := []struct {
+		name         string
+		password     string
+		salt         string
+		rounds       int
+		customRounds bool
+		want         string
+	}{
+		{
+			name:     "default rounds",
+			password: "Hello world!",
+			salt:     "saltstring",
+			rounds:   sha512CryptDefaultRounds,
+			want:     "$6$saltstring$svn8UoSVapNtMuq1ukKS4tPQd8iKwSMHWjl/O817G3uBnIFNjnQJuesI68u4OTLiBFdcbYEdFCoEOfaS35inz1",
+		},
+		{
+			name:         "rounds=10000",
+			password:     "Hello world!",
+			salt:         "saltstringsaltst",
+			rounds:       10000,
+			customRounds: true,
+			want:         "$6$rounds=10000$saltstringsaltst$OW1/O6BYHV6BcXZu8QVeXbDWra3Oeqh0sbHbbMCVNSnCM/UrjmM0Dp8vOuZeHBy/YTBmSK6H9qs/y3RnOaw5v.",
+		},
+		{
+			name:         "explicit default rounds",
+			password:     "This is just a test",
+			salt:         "toolongsaltstrin",
+			rounds:       5000,
+			customRounds: true,
+			want:         "$6$rounds=5000$toolongsaltstrin$lQ8jolhgVRVhY4b5pZKaysCLi0QBxGoNeKQzQ3glMhwllF7oGDZxUhx1yxdYcz/e1JSbq3y6JMxxl8audkUEm0",
+		},
+		{
+			name:         "short salt",
+			password:     "we have a short salt string but not a short password",
+			salt:         "short",
+			rounds:       77777,
+			customRounds: true,
+			want:         "$6$rounds=77777$short$WuQyW2YR.hBNpjjRhpYD/ifIw05xdfeEyQoMxIXbkvr0gge1a1x3yRULJ5CCaUeOxFmtlcGZelFl5CxtgfiAc0",
+		},
+		{
+			name:         "minimum rounds",
+			password:     "the minimum number is still observed",
+			salt:         "roundstoolow",
+			rounds:       sha512CryptMinRounds,
+			customRounds: true,
+			want:         "$6$rounds=1000$roundstoolow$kUMsbe306n21p9R.FRkW3IGn.S9NPN0x50YhH1xhLsPuWGsUSklZt58jaTfF4ZEQpyUNGc0dqbpBYYBaHHrsX.",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sha512Crypt([]byte(tt.password), []byte(tt.salt), tt.rounds, tt.customRounds); got != tt.want {
+				t.Errorf("sha512Crypt() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestVerifyHashedPassword 覆盖各算法的匹配、不匹配以及规范中的盐截断与轮数下限
+func TestVerifyHashedPassword(t *testing.T) {
+	tests := []struct {
+		name     string
+		hash     string
+		password string
+		verifier string
+		want     bool
+	}{
+		{
+			name:     "bcrypt match",
+			hash:     "$2a$05$CCCCCCCCCCCCCCCCCCCCC.E5YPO9kmyuRGyh0XouQYb4YMJKvyOeW",
+			password: "U*U",
+			verifier: "bcrypt",
+			want:     true,
+		},
+		{
+			name:     "bcrypt mismatch",
+			hash:     "$2a$05$CCCCCCCCCCCCCCCCCCCCC.E5YPO9kmyuRGyh0XouQYb4YMJKvyOeW",
+			password: "U*U*",
+			verifier: "bcrypt",
+		},
+		{
+			name:     "argon2id match",
+			hash:     "$argon2id$v=19$m=65536,t=2,p=1$c29tZXNhbHQ$CTFhFdXPJO1aFaMaO6Mm5c8y7cJHAph8ArZWb2GRPPc",
+			password: "password",
+			verifier: "argon2id",
+			want:     true,
+		},
+		{
+			name:     "argon2id mismatch",
+			hash:     "$argon2id$v=19$m=65536,t=2,p=1$c29tZXNhbHQ$CTFhFdXPJO1aFaMaO6Mm5c8y7cJHAph8ArZWb2GRPPc",
+			password: "Password",
+			verifier: "argon2id",
+		},
+		{
+			name:     "sha512-crypt match",
+			hash:     "$6$saltstring$svn8UoSVapNtMuq1ukKS4tPQd8iKwSMHWjl/O817G3uBnIFNjnQJuesI68u4OTLiBFdcbYEdFCoEOfaS35inz1",
+			password: "Hello world!",
+			verifier: "sha512-crypt",
+			want:     true,
+		},
+		{
+			name:     "sha512-crypt mismatch",
+			hash:     "$6$saltstring$svn8UoSVapNtMuq1ukKS4tPQd8iKwSMHWjl/O817G3uBnIFNjnQJuesI68u4OTLiBFdcbYEdFCoEOfaS35inz1",
+			password: "Hello world",
+			verifier: "sha512-crypt",
+		},
+		{
+			name:     "sha512-crypt salt longer than 16 characters",
+			hash:     "$6$rounds=5000$toolongsaltstring$lQ8jolhgVRVhY4b5pZKaysCLi0QBxGoNeKQzQ3glMhwllF7oGDZxUhx1yxdYcz/e1JSbq3y6JMxxl8audkUEm0",
+			password: "This is just a test",
+			verifier: "sha512-crypt",
+		},
+		{
+			name:     "sha512-crypt truncated salt",
+			hash:     "$6$rounds=5000$toolongsaltstrin$lQ8jolhgVRVhY4b5pZKaysCLi0QBxGoNeKQzQ3glMhwllF7oGDZxUhx1yxdYcz/e1JSbq3y6JMxxl8audkUEm0",
+			password: "This is just a test",
+			verifier: "sha512-crypt",
+			want:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := findVerifier(tt.hash)
+			if v == nil || v.Name() != tt.verifier {
+				t.Fatalf("findVerifier(%q) = %v, want %s", tt.hash, v, tt.verifier)
+			}
+			got, err := verifyHashedPassword(tt.hash, tt.password)
+			if err != nil {
+				t.Fatalf("verifyHashedPassword() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("verifyHashedPassword() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestVerifyHashedPasswordInvalid 格式错误的哈希返回错误，不是已知格式的字符串不视为哈希
+func TestVerifyHashedPasswordInvalid(t *testing.T) {
+	for _, hash := range []string{
+		"$2a$05$short",
+		"$argon2id$v=16$m=65536,t=2,p=1$c29tZXNhbHQ$CTFhFdXPJO1aFaMaO6Mm5c8y7cJHAph8ArZWb2GRPPc",
+		"$argon2id$v=19$m=65536$c29tZXNhbHQ$CTFhFdXPJO1aFaMaO6Mm5c8y7cJHAph8ArZWb2GRPPc",
+		"$6$rounds=abc$salt$hash",
+		"plaintext",
+	} {
+		if ok, err := verifyHashedPassword(hash, "password"); err == nil || ok {
+			t.Errorf("verifyHashedPassword(%q) = %v, %v, want error", hash, ok, err)
+		}
+	}
+	if IsPasswordHash("ENC(abc)") {
+		t.Error("IsPasswordHash(ENC(...)) = true, want false")
+	}
+}
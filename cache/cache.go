@@ -0,0 +1,323 @@
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Kind 缓存条目类型，决定使用数据源的哪一项缓存时间
+type Kind int
+
+const (
+	// KindBigKey 大数据量查询结果，过期时间取数据源的 bigKeyDataCacheTime
+	KindBigKey Kind = iota
+	// KindAlarm 告警状态，过期时间取数据源的 alarmKeyCacheTime；
+	// 告警状态与状态文件保持一致，不计入容量上限，也不会因容量不足被淘汰
+	KindAlarm
+)
+
+// String 返回类型名称，用于指标标签
+func (k Kind) String() string {
+	switch k {
+	case KindAlarm:
+		return "alarm"
+	default:
+		return "big_key"
+	}
+}
+
+// 淘汰原因，用于指标标签
+const (
+	evictReasonExpired     = "expired"
+	evictReasonCapacity    = "capacity"
+	evictReasonInvalidated = "invalidated"
+)
+
+const (
+	// DefaultMaxEntries 默认最大缓存条目数
+	DefaultMaxEntries = 10000
+
+	defaultBigKeyTTL = 60 * time.Minute
+	defaultAlarmTTL  = 5 * time.Minute
+)
+
+// Key 缓存键，由数据源、采集器和业务键组成
+type Key struct {
+	DataSource string
+	Collector  string
+	Name       string
+}
+
+// TTLResolver 根据数据源名称和条目类型返回过期时间，返回值<=0时使用默认值
+type TTLResolver func(dataSource string, kind Kind) time.Duration
+
+// entry 缓存条目
+type entry struct {
+	key       Key
+	kind      Kind
+	value     any
+	expiresAt time.Time
+}
+
+// Cache 按数据源划分命名空间、有容量上限的 LRU 缓存
+type Cache struct {
+	mu          sync.Mutex
+	items       map[Key]*list.Element
+	lru         *list.List // 队首为最近使用
+	maxEntries  int
+	pinned      int // 不参与容量淘汰的告警状态条目数
+	ttlResolver TTLResolver
+
+	hits      *prometheus.CounterVec
+	misses    *prometheus.CounterVec
+	evictions *prometheus.CounterVec
+	entries   *prometheus.Desc
+}
+
+// Default 全局缓存实例
+var Default = New(DefaultMaxEntries)
+
+// New 创建缓存实例，maxEntries<=0 时使用默认容量
+func New(maxEntries int) *Cache {
+	if maxEntries <= 0 {
+		maxEntries = DefaultMaxEntries
+	}
+	return &Cache{
+		items:      make(map[Key]*list.Element),
+		lru:        list.New(),
+		maxEntries: maxEntries,
+		hits: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "dameng_exporter_cache_hits_total",
+			Help: "Total number of cache hits by collector",
+		}, []string{"collector"}),
+		misses: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "dameng_exporter_cache_misses_total",
+			Help: "Total number of cache misses by collector",
+		}, []string{"collector"}),
+		evictions: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "dameng_exporter_cache_evictions_total",
+			Help: "Total number of cache evictions by reason (expired, capacity, invalidated)",
+		}, []string{"reason"}),
+		entries: prometheus.NewDesc(
+			"dameng_exporter_cache_entries",
+			"Current number of cache entries by kind",
+			[]string{"kind"},
+			nil,
+		),
+	}
+}
+
+// SetMaxEntries 调整容量上限，超出部分按 LRU 淘汰
+func (c *Cache) SetMaxEntries(maxEntries int) {
+	if maxEntries <= 0 {
+		maxEntries = DefaultMaxEntries
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.maxEntries = maxEntries
+	c.enforceCapacityLocked()
+}
+
+// SetTTLResolver 设置按数据源解析过期时间的函数
+func (c *Cache) SetTTLResolver(resolver TTLResolver) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.ttlResolver = resolver
+}
+
+// TTL 返回指定数据源某类条目的过期时间
+func (c *Cache) TTL(dataSource string, kind Kind) time.Duration {
+	c.mu.Lock()
+	resolver := c.ttlResolver
+	c.mu.Unlock()
+
+	if resolver != nil {
+		if ttl := resolver(dataSource, kind); ttl > 0 {
+			return ttl
+		}
+	}
+	if kind == KindAlarm {
+		return defaultAlarmTTL
+	}
+	return defaultBigKeyTTL
+}
+
+// Set 写入条目，过期时间取所属数据源对应类型的缓存时间
+func (c *Cache) Set(key Key, kind Kind, value any) {
+	c.SetWithTTL(key, kind, value, c.TTL(key.DataSource, kind))
+}
+
+// SetWithTTL 写入条目并显式指定过期时间
+func (c *Cache) SetWithTTL(key Key, kind Kind, value any, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	expiresAt := time.Now().Add(ttl)
+	if elem, ok := c.items[key]; ok {
+		e := elem.Value.(*entry)
+		c.pinned += pinnedDelta(kind) - pinnedDelta(e.kind)
+		e.kind = kind
+		e.value = value
+		e.expiresAt = expiresAt
+		c.lru.MoveToFront(elem)
+		c.enforceCapacityLocked()
+		return
+	}
+
+	c.items[key] = c.lru.PushFront(&entry{key: key, kind: kind, value: value, expiresAt: expiresAt})
+	c.pinned += pinnedDelta(kind)
+	c.enforceCapacityLocked()
+}
+
+// Get 读取条目，过期条目视为未命中
+func (c *Cache) Get(key Key) (any, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.items[key]
+	if !ok {
+		c.misses.WithLabelValues(key.Collector).Inc()
+		return nil, false
+	}
+
+	e := elem.Value.(*entry)
+	if !time.Now().Before(e.expiresAt) {
+		c.removeElementLocked(elem, evictReasonExpired)
+		c.misses.WithLabelValues(key.Collector).Inc()
+		return nil, false
+	}
+
+	c.lru.MoveToFront(elem)
+	c.hits.WithLabelValues(key.Collector).Inc()
+	return e.value, true
+}
+
+// Exists 判断条目是否存在且未过期
+func (c *Cache) Exists(key Key) bool {
+	_, ok := c.Get(key)
+	return ok
+}
+
+// Delete 删除条目
+func (c *Cache) Delete(key Key) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, ok := c.items[key]; ok {
+		c.pinned -= pinnedDelta(elem.Value.(*entry).kind)
+		c.lru.Remove(elem)
+		delete(c.items, key)
+	}
+}
+
+// InvalidateDataSource 删除指定数据源的条目；未指定 kinds 时删除全部类型，返回删除数量
+func (c *Cache) InvalidateDataSource(dataSource string, kinds ...Kind) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	removed := 0
+	for key, elem := range c.items {
+		if key.DataSource != dataSource {
+			continue
+		}
+		if len(kinds) > 0 && !containsKind(kinds, elem.Value.(*entry).kind) {
+			continue
+		}
+		c.removeElementLocked(elem, evictReasonInvalidated)
+		removed++
+	}
+	return removed
+}
+
+// Get 以指定类型读取条目，类型不匹配时视为未命中
+func Get[T any](c *Cache, key Key) (T, bool) {
+	var zero T
+	value, ok := c.Get(key)
+	if !ok {
+		return zero, false
+	}
+	typed, ok := value.(T)
+	if !ok {
+		return zero, false
+	}
+	return typed, true
+}
+
+// Describe 实现 prometheus.Collector 接口
+func (c *Cache) Describe(ch chan<- *prometheus.Desc) {
+	c.hits.Describe(ch)
+	c.misses.Describe(ch)
+	c.evictions.Describe(ch)
+	ch <- c.entries
+}
+
+// Collect 实现 prometheus.Collector 接口，顺带清理过期条目
+func (c *Cache) Collect(ch chan<- prometheus.Metric) {
+	c.mu.Lock()
+	c.purgeExpiredLocked()
+	counts := map[Kind]int{KindBigKey: 0, KindAlarm: 0}
+	for _, elem := range c.items {
+		counts[elem.Value.(*entry).kind]++
+	}
+	c.mu.Unlock()
+
+	c.hits.Collect(ch)
+	c.misses.Collect(ch)
+	c.evictions.Collect(ch)
+	for kind, count := range counts {
+		ch <- prometheus.MustNewConstMetric(c.entries, prometheus.GaugeValue, float64(count), kind.String())
+	}
+}
+
+// enforceCapacityLocked 超出容量时先清理过期条目，再按 LRU 淘汰；告警状态条目不计入容量，也不会被淘汰
+func (c *Cache) enforceCapacityLocked() {
+	if len(c.items)-c.pinned <= c.maxEntries {
+		return
+	}
+	c.purgeExpiredLocked()
+	for elem := c.lru.Back(); elem != nil && len(c.items)-c.pinned > c.maxEntries; {
+		prev := elem.Prev()
+		if elem.Value.(*entry).kind != KindAlarm {
+			c.removeElementLocked(elem, evictReasonCapacity)
+		}
+		elem = prev
+	}
+}
+
+// purgeExpiredLocked 清理所有过期条目
+func (c *Cache) purgeExpiredLocked() {
+	now := time.Now()
+	for _, elem := range c.items {
+		if !now.Before(elem.Value.(*entry).expiresAt) {
+			c.removeElementLocked(elem, evictReasonExpired)
+		}
+	}
+}
+
+// removeElementLocked 删除条目并记录淘汰原因
+func (c *Cache) removeElementLocked(elem *list.Element, reason string) {
+	e := elem.Value.(*entry)
+	c.pinned -= pinnedDelta(e.kind)
+	c.lru.Remove(elem)
+	delete(c.items, e.key)
+	c.evictions.WithLabelValues(reason).Inc()
+}
+
+// pinnedDelta 条目类型对不参与容量淘汰的条目数的贡献
+func pinnedDelta(kind Kind) int {
+	if kind == KindAlarm {
+		return 1
+	}
+	return 0
+}
+
+func containsKind(kinds []Kind, kind Kind) bool {
+	for _, k := range kinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"dameng_exporter/collector"
+	"dameng_exporter/config"
+	"dameng_exporter/db"
+	"dameng_exporter/logger"
+	"dameng_exporter/report"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// collectArgs collect 子命令参数
+type collectArgs struct {
+	once   *bool
+	format *string
+	output *string
+}
+
+// registerCollectCommand 注册 collect 子命令：对所有启用的数据源执行一次全部采集器，输出按数据源与采集器分组的报告，用于巡检
+func registerCollectCommand() *collectArgs {
+	cmd := kingpin.Command("collect", "Run every enabled collector against every datasource once and write a report grouped by datasource and collector")
+	return &collectArgs{
+		once:   cmd.Flag("once", "Collect once and exit, currently the only supported mode").Default("true").Bool(),
+		format: cmd.Flag("format", "Report format ("+strings.Join(report.Formats, "|")+")").Default(report.FormatJSON).Enum(report.Formats...),
+		output: cmd.Flag("output", "Report file, - writes to stdout").Default("-").String(),
+	}
+}
+
+// runCollect 执行 collect 子命令，返回进程退出码，含义与 Pushgateway 模式一致
+func runCollect(args *collectArgs) int {
+	if !*args.once {
+		fmt.Fprintln(os.Stderr, "Error: collect only supports --once")
+		return db.ExitAllFailed
+	}
+
+	start := time.Now()
+	poolManager := db.NewDBPoolManager(config.GlobalMultiConfig)
+	if err := poolManager.InitPools(); err != nil {
+		logger.Logger.Errorf("Failed to initialize datasource pools: %v", err)
+		return db.ExitAllFailed
+	}
+	defer poolManager.Close()
+
+	// 注册与 HTTP 服务相同的采集器，加载各数据源的自定义指标配置
+	reg := prometheus.NewRegistry()
+	collector.RegisterMultiSourceCollectors(reg, poolManager)
+	groups := collector.GatherByCollector(poolManager)
+	r := report.Build(poolManager, groups, collector.CollectorRuns(), Version, start)
+
+	if err := writeReport(*args.output, r, *args.format); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to write report: %v\n", err)
+		return db.ExitAllFailed
+	}
+	code := r.ExitCode()
+	logger.Logger.Infof("Collected %d datasource(s) in %dms, report written to %s, exit code %d",
+		len(r.Datasources), r.DurationMs, *args.output, code)
+	return code
+}
+
+// writeReport 将报告写入文件，路径为 - 时写入标准输出
+func writeReport(path string, r *report.Report, format string) error {
+	if path == "-" {
+		return report.Write(os.Stdout, r, format)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := report.Write(f, r, format); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
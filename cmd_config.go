@@ -0,0 +1,121 @@
+package main
+
+import (
+	"dameng_exporter/config"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/duke-git/lancet/v2/fileutil"
+)
+
+// configArgs config 子命令参数
+type configArgs struct {
+	from   *string
+	name   *string
+	output *string
+	force  *bool
+}
+
+// registerConfigCommand 注册 config migrate 与 config dump 子命令
+func registerConfigCommand() *configArgs {
+	cmd := kingpin.Command("config", "Configuration tools")
+	migrate := cmd.Command("migrate", "Convert a legacy single-datasource config file and/or the legacy --dbHost/--dbUser/--dbPwd flags into a multi-source TOML config")
+	cmd.Command("dump", "Print the effective configuration after merging the config file and flags, with secrets masked")
+	return &configArgs{
+		from:   migrate.Flag("from", "Legacy single-datasource config file (v1.1.x and earlier), omit to migrate from the legacy flags").Default("").String(),
+		name:   migrate.Flag("name", "Datasource name, defaults to dm_<host> for a legacy file and cmdline_<host> for flags").Default("").String(),
+		output: migrate.Flag("output", "Output file, - writes to stdout").Default("./dameng_exporter.toml").String(),
+		force:  migrate.Flag("force", "Overwrite the output file if it exists").Default("false").Bool(),
+	}
+}
+
+// runConfigMigrate 执行 config migrate 子命令：读取旧版配置文件或命令行参数，写出等价的多数据源配置。
+// 同时指定旧版配置文件与 --dbHost/--dbUser/--dbPwd 时，以配置文件为准并用指定的连接参数覆盖；
+// 密码保持原样，ENC() 格式的密码不会被解密
+func runConfigMigrate(args *configArgs, cmdArgs *config.CmdArgs) int {
+	var (
+		migrated *config.MultiSourceConfig
+		source   string
+		err      error
+	)
+	if *args.from != "" {
+		migrated, err = config.LoadLegacyConfig(*args.from)
+		if err == nil {
+			migrated.OverrideConnection(cmdArgs)
+		}
+		source = *args.from
+	} else {
+		migrated, err = config.MigrateCmdArgs(cmdArgs)
+		source = "command line flags"
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	if *args.name != "" {
+		migrated.DataSources[0].Name = *args.name
+	}
+	migrated.Version = Version
+
+	// 写出前按加载配置文件的规则校验，避免生成无法启动的配置
+	check := *migrated
+	check.ApplyAllDefaults()
+	if err := check.ValidateAll(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: migrated config is invalid: %v\n", err)
+		return 1
+	}
+
+	header := fmt.Sprintf("Migrated from %s by dameng_exporter %s at %s", source, Version, time.Now().Format("2006-01-02 15:04:05"))
+	if *args.output == "-" {
+		if err := config.WriteMultiSourceConfig(os.Stdout, migrated, header); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 1
+		}
+		return 0
+	}
+	if fileutil.IsExist(*args.output) && !*args.force {
+		fmt.Fprintf(os.Stderr, "Error: %s already exists, use --force to overwrite\n", *args.output)
+		return 1
+	}
+	f, err := os.Create(*args.output)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	if err := config.WriteMultiSourceConfig(f, migrated, header); err != nil {
+		f.Close()
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	if err := f.Close(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	fmt.Fprintf(os.Stderr, "Migrated %s to %s with datasource %s\n", source, *args.output, migrated.DataSources[0].Name)
+	return 0
+}
+
+// runConfigDump 执行 config dump 子命令：按启动时的规则加载配置文件并合并命令行参数，隐藏敏感字段后输出。
+// 与启动不同，不会加密并回写配置文件中的明文密码
+func runConfigDump(cmdArgs *config.CmdArgs) int {
+	if !fileutil.IsExist(*cmdArgs.ConfigFile) {
+		fmt.Fprintf(os.Stderr, "Error: Config file not found: %s\n", *cmdArgs.ConfigFile)
+		return 1
+	}
+	effective, err := config.LoadMultiSourceConfig(*cmdArgs.ConfigFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading TOML config file: %v\n", err)
+		return 1
+	}
+	effective.Version = Version
+	config.MergeMultiSourceConfigFromCmdArgs(effective, cmdArgs)
+
+	header := fmt.Sprintf("Effective configuration of dameng_exporter %s loaded from %s, secrets are masked", Version, *cmdArgs.ConfigFile)
+	if err := config.WriteMultiSourceConfig(os.Stdout, effective.Masked(), header); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	return 0
+}
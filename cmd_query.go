@@ -0,0 +1,245 @@
+package main
+
+import (
+	"context"
+	"dameng_exporter/collector"
+	"dameng_exporter/config"
+	"dameng_exporter/db"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/expfmt"
+)
+
+// queryArgs query 子命令参数
+type queryArgs struct {
+	datasource  *string
+	metricsFile *string
+	context     *string
+	format      *string
+}
+
+// registerQueryCommand 注册 query 子命令：对一个数据源执行一次自定义指标定义并输出结果，用于部署前检查 .metrics 文件
+func registerQueryCommand() *queryArgs {
+	cmd := kingpin.Command("query", "Run custom metric definitions once against a datasource and print the resulting series")
+	return &queryArgs{
+		datasource:  cmd.Flag("datasource", "Datasource name in the config file, may be omitted when only one datasource is enabled").String(),
+		metricsFile: cmd.Flag("metrics-file", "Custom metrics file, defaults to the customMetricsFile of the datasource").String(),
+		context:     cmd.Flag("context", "Only run the [[metric]] with this context").String(),
+		format:      cmd.Flag("format", "Output format (exposition|table)").Default("exposition").Enum("exposition", "table"),
+	}
+}
+
+// runQuery 执行 query 子命令，返回进程退出码：全部查询成功返回0，否则返回1
+func runQuery(args *queryArgs) int {
+	ds, err := selectDatasource(*args.datasource)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	metricsFile := *args.metricsFile
+	if metricsFile == "" {
+		metricsFile = ds.CustomMetricsFile
+	}
+	if metricsFile == "" {
+		fmt.Fprintf(os.Stderr, "Error: datasource %s has no customMetricsFile, use --metrics-file\n", ds.Name)
+		return 1
+	}
+	customConfig, err := config.ParseCustomConfig(metricsFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	if *args.context != "" {
+		customConfig.Metrics = filterCustomMetrics(customConfig.Metrics, *args.context)
+		if len(customConfig.Metrics) == 0 {
+			fmt.Fprintf(os.Stderr, "Error: no [[metric]] with context %s in %s\n", *args.context, metricsFile)
+			return 1
+		}
+	}
+
+	pool, closePool, err := openQueryPool(ds)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	defer closePool()
+
+	// 与采集时相同，所有查询共用数据源的查询超时
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(ds.QueryTimeout)*time.Second)
+	defer cancel()
+	results := collector.RunCustomMetrics(ctx, pool.DB, customConfig)
+	injector := collector.NewLabelInjectorFromPool(pool)
+
+	if *args.format == "table" {
+		printQueryTable(os.Stdout, results, injector.GetLabels())
+	} else {
+		printQueryExposition(os.Stdout, results, injector)
+	}
+
+	for _, r := range results {
+		if r.Err != nil {
+			return 1
+		}
+	}
+	return 0
+}
+
+// selectDatasource 按名称选择数据源，未指定名称时要求只有一个启用的数据源
+func selectDatasource(name string) (*config.DataSourceConfig, error) {
+	dataSources := config.GlobalMultiConfig.DataSourceList()
+	if name != "" {
+		for i := range dataSources {
+			if dataSources[i].Name == name {
+				return &dataSources[i], nil
+			}
+		}
+		return nil, fmt.Errorf("datasource %s not found in config", name)
+	}
+
+	var enabled []*config.DataSourceConfig
+	var names []string
+	for i := range dataSources {
+		if dataSources[i].Enabled {
+			enabled = append(enabled, &dataSources[i])
+			names = append(names, dataSources[i].Name)
+		}
+	}
+	if len(enabled) != 1 {
+		return nil, fmt.Errorf("%d datasources enabled (%s), use --datasource to choose one", len(enabled), strings.Join(names, ", "))
+	}
+	return enabled[0], nil
+}
+
+// openQueryPool 只为指定数据源建立连接，不启用拓扑发现，返回连接池与关闭函数
+func openQueryPool(ds *config.DataSourceConfig) (*db.DataSourcePool, func(), error) {
+	single := *ds
+	single.Enabled = true
+	single.Discovery = false
+	multi := *config.GlobalMultiConfig
+	multi.DataSources = []config.DataSourceConfig{single}
+
+	poolManager := db.NewDBPoolManager(&multi)
+	if err := poolManager.InitPools(); err != nil {
+		poolManager.Close()
+		return nil, nil, fmt.Errorf("failed to initialize datasource %s: %w", ds.Name, err)
+	}
+	pool := poolManager.GetPool(ds.Name)
+	if pool == nil {
+		status := poolManager.GetDatasourceHealthStatus(ds.Name)
+		poolManager.Close()
+		return nil, nil, fmt.Errorf("failed to connect to datasource %s: %s", ds.Name, status.LastError)
+	}
+	return pool, poolManager.Close, nil
+}
+
+// filterCustomMetrics 按 context 筛选 [[metric]]
+func filterCustomMetrics(metrics []config.CustomMetric, context string) []config.CustomMetric {
+	var filtered []config.CustomMetric
+	for _, m := range metrics {
+		if m.Context == context {
+			filtered = append(filtered, m)
+		}
+	}
+	return filtered
+}
+
+// printQueryExposition 以 Prometheus 文本格式输出结果，警告与错误以注释行输出在各 context 之前
+func printQueryExposition(w io.Writer, results []collector.CustomQueryResult, injector *collector.LabelInjector) {
+	for _, r := range results {
+		fmt.Fprintf(w, "# context %s: %d row(s), %d sample(s) in %s\n", r.Context, r.Rows, len(r.Samples), r.Duration.Round(time.Millisecond))
+		if r.Err != nil {
+			fmt.Fprintf(w, "# ERROR: %v\n", r.Err)
+		}
+		for _, warning := range r.Warnings {
+			fmt.Fprintf(w, "# WARNING: %s\n", warning)
+		}
+
+		reg := prometheus.NewRegistry()
+		reg.MustRegister(querySamples{samples: r.Samples, injector: injector})
+		families, err := reg.Gather()
+		if err != nil {
+			fmt.Fprintf(w, "# ERROR: %v\n", err)
+		}
+		for _, mf := range families {
+			if _, err := expfmt.MetricFamilyToText(w, mf); err != nil {
+				fmt.Fprintf(w, "# ERROR: %v\n", err)
+			}
+		}
+		fmt.Fprintln(w)
+	}
+}
+
+// printQueryTable 以表格输出结果
+func printQueryTable(w io.Writer, results []collector.CustomQueryResult, extraLabels map[string]string) {
+	for _, r := range results {
+		fmt.Fprintf(w, "== %s: %d row(s), %d sample(s) in %s\n", r.Context, r.Rows, len(r.Samples), r.Duration.Round(time.Millisecond))
+		if r.Err != nil {
+			fmt.Fprintf(w, "ERROR: %v\n", r.Err)
+		}
+		for _, warning := range r.Warnings {
+			fmt.Fprintf(w, "WARNING: %s\n", warning)
+		}
+		if len(r.Samples) > 0 {
+			tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(tw, "METRIC\tTYPE\tLABELS\tVALUE")
+			for _, s := range r.Samples {
+				fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", s.Name, s.Type, formatSampleLabels(s, extraLabels),
+					strconv.FormatFloat(s.Value, 'g', -1, 64))
+			}
+			tw.Flush()
+		}
+		fmt.Fprintln(w)
+	}
+}
+
+// formatSampleLabels 格式化样本标签，包含采集时注入的数据源标签
+func formatSampleLabels(s collector.CustomSample, extraLabels map[string]string) string {
+	labels := make(map[string]string, len(s.LabelNames)+len(extraLabels))
+	for k, v := range extraLabels {
+		labels[k] = v
+	}
+	for i, name := range s.LabelNames {
+		labels[name] = s.LabelValues[i]
+	}
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	pairs := make([]string, 0, len(names))
+	for _, name := range names {
+		pairs = append(pairs, fmt.Sprintf("%s=%q", name, labels[name]))
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+// querySamples 将试运行样本作为 Prometheus 采集器输出，并注入数据源标签
+type querySamples struct {
+	samples  []collector.CustomSample
+	injector *collector.LabelInjector
+}
+
+// Describe 实现 prometheus.Collector 接口，不声明指标描述以允许任意样本
+func (q querySamples) Describe(chan<- *prometheus.Desc) {}
+
+// Collect 实现 prometheus.Collector 接口
+func (q querySamples) Collect(ch chan<- prometheus.Metric) {
+	for _, s := range q.samples {
+		metric, err := collector.NewCustomSampleMetric(s)
+		if err != nil {
+			ch <- prometheus.NewInvalidMetric(prometheus.NewDesc(s.Name, s.Help, nil, nil), err)
+			continue
+		}
+		ch <- collector.NewMetricWrapper(metric, q.injector)
+	}
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"dameng_exporter/collector"
+	"dameng_exporter/config"
+	"dameng_exporter/db"
+	"dameng_exporter/logger"
+	"dameng_exporter/report"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// reportArgs report 子命令参数
+type reportArgs struct {
+	format *string
+	output *string
+}
+
+// registerReportCommand 注册 report 子命令：对所有启用的数据源执行一次巡检，按阈值输出每项检查的结论
+func registerReportCommand() *reportArgs {
+	cmd := kingpin.Command("report", "Inspect every enabled datasource once and write an HTML or Markdown report with pass/warn/fail verdicts per check")
+	return &reportArgs{
+		format: cmd.Flag("format", "Report format ("+strings.Join(report.InspectionFormats, "|")+")").Default(report.FormatHTML).Enum(report.InspectionFormats...),
+		output: cmd.Flag("output", "Report file, - writes to stdout").Default("-").String(),
+	}
+}
+
+// runReport 执行 report 子命令，返回进程退出码：全部通过为0，存在警告、失败或无数据为1，全部失败为2
+func runReport(args *reportArgs) int {
+	poolManager := db.NewDBPoolManager(config.GlobalMultiConfig)
+	if err := poolManager.InitPools(); err != nil {
+		logger.Logger.Errorf("Failed to initialize datasource pools: %v", err)
+		return db.ExitAllFailed
+	}
+	defer poolManager.Close()
+
+	// 只执行巡检需要的采集器
+	filter, err := collector.NewScrapeFilter(nil, report.InspectionCollectors)
+	if err != nil {
+		logger.Logger.Errorf("Failed to create inspection filter: %v", err)
+		return db.ExitAllFailed
+	}
+	reg := prometheus.NewRegistry()
+	collector.RegisterMultiSourceCollectors(reg, poolManager)
+	families, err := collector.NewFilteredRegistry(poolManager, filter).Gather()
+	if err != nil {
+		logger.Logger.Warnf("Inspection collection finished with errors: %v", err)
+	}
+	inspection := report.Inspect(poolManager, families, collector.CollectorRuns(), config.Global.GetReport(), Version, time.Now())
+
+	if err := writeInspection(*args.output, inspection, *args.format); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to write report: %v\n", err)
+		return db.ExitAllFailed
+	}
+	code := inspection.ExitCode()
+	logger.Logger.Infof("Inspected %d datasource(s), verdict %s, report written to %s, exit code %d",
+		len(inspection.Datasources), inspection.Verdict, *args.output, code)
+	return code
+}
+
+// writeInspection 将巡检报告写入文件，路径为 - 时写入标准输出
+func writeInspection(path string, inspection *report.Inspection, format string) error {
+	if path == "-" {
+		return report.WriteInspection(os.Stdout, inspection, format)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := report.WriteInspection(f, inspection, format); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -36,6 +36,7 @@ const (
 	dmdbms_joblog_error_num string = "dmdbms_joblog_error_num"
 
 	dmdbms_slow_sql_info                 string = "dmdbms_slow_sql_info"
+	dmdbms_slow_sql_exec_seconds         string = "dmdbms_slow_sql_exec_seconds"
 	dmdbms_monitor_info                  string = "dmdbms_monitor_info"
 	dmdbms_statement_type_total          string = "dmdbms_statement_type_total"
 	dmdbms_parameter_info                string = "dmdbms_parameter_info"
@@ -0,0 +1,41 @@
+package collector
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// instanceStartTimes 各数据源实例的启动时间（V$INSTANCE.START_TIME），
+// 由实例运行信息采集器更新，作为自实例启动以来累计的计数器的创建时间
+var instanceStartTimes sync.Map // map[string]time.Time
+
+// setInstanceStartTime 记录数据源实例的启动时间
+func setInstanceStartTime(dataSource string, startTime time.Time) {
+	instanceStartTimes.Store(dataSource, startTime)
+}
+
+// instanceStartTime 获取数据源实例的启动时间，尚未采集到时返回 false
+func instanceStartTime(dataSource string) (time.Time, bool) {
+	value, ok := instanceStartTimes.Load(dataSource)
+	if !ok {
+		return time.Time{}, false
+	}
+	return value.(time.Time), true
+}
+
+// forgetDataSourceState 清理已删除数据源跨抓取保留的状态（实例启动时间与慢SQL跟踪），
+// 避免同名数据源重新加入时沿用旧的直方图与启动时间
+func forgetDataSourceState(dataSource string) {
+	instanceStartTimes.Delete(dataSource)
+	slowSQLTrackers.Delete(dataSource)
+}
+
+// newInstanceCounter 创建自实例启动以来累计的计数器，已知实例启动时间时附带创建时间（OpenMetrics 中输出为 _created）
+func newInstanceCounter(desc *prometheus.Desc, value float64, dataSource string, labelValues ...string) prometheus.Metric {
+	if startTime, ok := instanceStartTime(dataSource); ok {
+		return prometheus.MustNewConstMetricWithCreatedTimestamp(desc, prometheus.CounterValue, value, startTime, labelValues...)
+	}
+	return prometheus.MustNewConstMetric(desc, prometheus.CounterValue, value, labelValues...)
+}
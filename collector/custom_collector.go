@@ -7,6 +7,7 @@ import (
 	"dameng_exporter/utils"
 	"database/sql"
 	"fmt"
+	"sort"
 	"strings"
 	"time"
 
@@ -84,7 +85,7 @@ func (cm *CustomMetrics) Collect(ch chan<- prometheus.Metric) {
 	if dsName == "" {
 		dsName = "default"
 	}
-	logger.Logger.Debugf("[%s] Collecting custom metrics...", dsName)
+	logger.ForDatasource(dsName).Debugf("[%s] Collecting custom metrics...", dsName)
 
 	if err := utils.CheckDBConnectionWithSource(cm.db, dsName); err != nil {
 		return
@@ -101,43 +102,21 @@ func (cm *CustomMetrics) Collect(ch chan<- prometheus.Metric) {
 		}
 
 		for _, result := range results {
-			// 创建标签值列表
-			labelValues := make([]string, len(metric.Labels))
-
-			for i, label := range metric.Labels {
-				if val, ok := result[label]; ok {
-					labelValues[i] = fmt.Sprintf("%v", val)
-				}
-			}
-
-			for field, value := range result {
-				//如果metric.Labels中包含field 忽略大小写 则跳过
-				if strings.EqualFold(field, strings.Join(metric.Labels, "")) {
+			labelValues, values := evaluateCustomRow(metric, result, func(format string, args ...interface{}) {
+				logger.ForDatasource(dsName).Debugf("[%s] %s: "+format, append([]interface{}{dsName, metric.Context}, args...)...)
+			})
+			for _, v := range values {
+				collector, ok := cm.metrics[v.name]
+				if !ok {
 					continue
 				}
-				if collector, ok := cm.metrics["dmdbms_"+metric.Context+"_"+field]; ok {
-					conver_float, err := convertor.ToFloat(value)
-					if err != nil {
-						conver_float = 0.0
-					}
-
-					// 如果启用了忽略零值且当前值为0，则跳过该指标
-					if metric.IgnoreZeroResult && conver_float == 0 {
-						logger.Logger.Debugf("[%s] Ignoring zero value for metric: %s_%s",
-							dsName, metric.Context, field)
-						continue
-					}
-
-					switch metric.MetricsType[field] {
-					case "counter":
-						collector.(*prometheus.CounterVec).WithLabelValues(labelValues...).Add(conver_float)
-					default:
-						collector.(*prometheus.GaugeVec).WithLabelValues(labelValues...).Set(conver_float)
-					}
-					//	logger.Logger.Debugf("metric %s value %v labelValues %v", field, conver_float, labelValues)
+				switch metric.MetricsType[v.field] {
+				case "counter":
+					collector.(*prometheus.CounterVec).WithLabelValues(labelValues...).Add(v.value)
+				default:
+					collector.(*prometheus.GaugeVec).WithLabelValues(labelValues...).Set(v.value)
 				}
 			}
-
 		}
 	}
 	// 手动调用 Prometheus 的 Collect 方法来注册所有已更新的 metrics
@@ -146,6 +125,56 @@ func (cm *CustomMetrics) Collect(ch chan<- prometheus.Metric) {
 	}
 }
 
+// customFieldValue 一行查询结果中一个指标字段的值
+type customFieldValue struct {
+	name  string // 完整指标名 dmdbms_<context>_<field>
+	field string
+	value float64
+}
+
+// evaluateCustomRow 按 [[metric]] 定义解析一行查询结果，返回标签值与各指标字段的值（按字段名排序）。
+// 缺失的标签列使用空值，非数值按0处理，开启 ignorezeroresult 时跳过零值，这些情况通过 warn 报告
+func evaluateCustomRow(metric config.CustomMetric, row map[string]interface{}, warn func(format string, args ...interface{})) ([]string, []customFieldValue) {
+	// 创建标签值列表
+	labelValues := make([]string, len(metric.Labels))
+	for i, label := range metric.Labels {
+		if val, ok := row[label]; ok {
+			labelValues[i] = fmt.Sprintf("%v", val)
+		} else {
+			warn("label column %s not found in result, using empty value", label)
+		}
+	}
+
+	fields := make([]string, 0, len(row))
+	for field := range row {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	var values []customFieldValue
+	for _, field := range fields {
+		//如果metric.Labels中包含field 忽略大小写 则跳过
+		if strings.EqualFold(field, strings.Join(metric.Labels, "")) {
+			continue
+		}
+		if _, ok := metric.MetricsDesc[field]; !ok {
+			continue
+		}
+		value, err := convertor.ToFloat(row[field])
+		if err != nil {
+			warn("non-numeric value %v in column %s, using 0", row[field], field)
+			value = 0
+		}
+		// 如果启用了忽略零值且当前值为0，则跳过该指标
+		if metric.IgnoreZeroResult && value == 0 {
+			warn("ignoring zero value of column %s (ignorezeroresult)", field)
+			continue
+		}
+		values = append(values, customFieldValue{name: "dmdbms_" + metric.Context + "_" + field, field: field, value: value})
+	}
+	return labelValues, values
+}
+
 // queryDynamicDatabase 函数返回 SQL 查询结果，包括所有字段及数据
 func queryDynamicDatabase(ctx context.Context, db *sql.DB, query string) ([]map[string]interface{}, error) {
 
@@ -6,6 +6,7 @@ import (
 	"dameng_exporter/logger"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/duke-git/lancet/v2/fileutil"
 	"github.com/prometheus/client_golang/prometheus"
@@ -37,7 +38,7 @@ func (a *CustomMetricsMultiSourceAdapter) loadConfigForDataSource(dsName string)
 
 	if !exists {
 		// 配置应该在注册时已加载，如果没有找到说明该数据源没有配置或加载失败
-		logger.Logger.Debugf("No custom metrics config cached for datasource [%s] ", dsName)
+		logger.ForDatasource(dsName).Debugf("No custom metrics config cached for datasource [%s] ", dsName)
 	}
 
 	return cfg
@@ -50,7 +51,12 @@ func (a *CustomMetricsMultiSourceAdapter) Describe(ch chan<- *prometheus.Desc) {
 
 // Collect 实现Prometheus Collector接口
 func (a *CustomMetricsMultiSourceAdapter) Collect(ch chan<- prometheus.Metric) {
-	pools := a.poolManager.GetHealthyPools()
+	a.collect(ch, nil)
+}
+
+// collect 采集过滤范围内数据源的自定义指标，filter 为 nil 表示不过滤
+func (a *CustomMetricsMultiSourceAdapter) collect(ch chan<- prometheus.Metric, filter *ScrapeFilter) {
+	pools := filter.filterPools(a.poolManager.GetHealthyPools())
 
 	var wg sync.WaitGroup
 	for _, pool := range pools {
@@ -63,6 +69,8 @@ func (a *CustomMetricsMultiSourceAdapter) Collect(ch chan<- prometheus.Metric) {
 		wg.Add(1)
 		go func(p *db.DataSourcePool, cfg *config.CustomConfig) {
 			defer wg.Done()
+			startTime := time.Now()
+			metricCount := 0
 
 			// 为该数据源创建自定义指标采集器
 			collector := NewCustomMetrics(p.DB, *cfg)
@@ -93,6 +101,7 @@ func (a *CustomMetricsMultiSourceAdapter) Collect(ch chan<- prometheus.Metric) {
 					// 阻塞写入，确保所有指标都被Prometheus接收
 					// 这里没有default分支，不会丢失任何指标
 					ch <- wrappedMetric
+					metricCount++
 				}
 			}()
 
@@ -110,14 +119,42 @@ func (a *CustomMetricsMultiSourceAdapter) Collect(ch chan<- prometheus.Metric) {
 			<-collectDone
 			// 等待转发完成
 			<-forwardDone
+
+			recordRun(CollectorRun{
+				Collector:  customCollectorName,
+				DataSource: p.Name,
+				LastRun:    startTime,
+				Duration:   time.Since(startTime),
+				Metrics:    metricCount,
+			})
 		}(pool, customConfig)
 	}
 
 	wg.Wait()
 }
 
-// RegisterCustomMetricsForMultiSource 注册支持多数据源独立配置的自定义指标采集器
-func RegisterCustomMetricsForMultiSource(reg *prometheus.Registry, poolManager *db.DBPoolManager) {
+// customAdapter 已注册的自定义指标适配器，供带过滤条件的抓取复用已加载的配置
+var customAdapter *CustomMetricsMultiSourceAdapter
+
+// filteredCustomMetricsAdapter 单次抓取使用的自定义指标适配器，共享已加载的配置
+type filteredCustomMetricsAdapter struct {
+	adapter *CustomMetricsMultiSourceAdapter
+	filter  *ScrapeFilter
+}
+
+// Describe 实现Prometheus Collector接口
+func (f *filteredCustomMetricsAdapter) Describe(ch chan<- *prometheus.Desc) {
+	f.adapter.Describe(ch)
+}
+
+// Collect 实现Prometheus Collector接口
+func (f *filteredCustomMetricsAdapter) Collect(ch chan<- prometheus.Metric) {
+	f.adapter.collect(ch, f.filter)
+}
+
+// RegisterCustomMetricsForMultiSource 注册支持多数据源独立配置的自定义指标采集器，返回注册的适配器（无需自定义指标时返回 nil）。
+// 调用方需持有 registerMux
+func RegisterCustomMetricsForMultiSource(reg *prometheus.Registry, poolManager *db.DBPoolManager) *CustomMetricsMultiSourceAdapter {
 	// 检查是否有任何数据源需要自定义指标
 	needCustomMetrics := false
 	totalMetricsCount := 0
@@ -125,14 +162,15 @@ func RegisterCustomMetricsForMultiSource(reg *prometheus.Registry, poolManager *
 
 	// 创建适配器实例
 	adapter := NewCustomMetricsMultiSourceAdapter(poolManager)
+	customFiles = nil
 
-	for _, ds := range config.GlobalMultiConfig.DataSources {
+	for _, ds := range config.GlobalMultiConfig.DataSourceList() {
 		if ds.Enabled && ds.RegisterCustomMetrics {
 			needCustomMetrics = true
 
 			// 如果没有配置自定义文件，跳过
 			if ds.CustomMetricsFile == "" {
-				logger.Logger.Debugf("DataSource [%s] has no custom metrics file configured, skipping",
+				logger.ForDatasource(ds.Name).Debugf("DataSource [%s] has no custom metrics file configured, skipping",
 					ds.Name)
 				continue
 			}
@@ -145,6 +183,7 @@ func RegisterCustomMetricsForMultiSource(reg *prometheus.Registry, poolManager *
 						zap.String("datasource", ds.Name),
 						zap.String("file", ds.CustomMetricsFile),
 						zap.Error(err))
+					customFiles = append(customFiles, CustomMetricsFile{DataSource: ds.Name, File: ds.CustomMetricsFile, Error: err.Error()})
 					continue
 				}
 
@@ -156,27 +195,29 @@ func RegisterCustomMetricsForMultiSource(reg *prometheus.Registry, poolManager *
 				metricsCount := len(customConfig.Metrics)
 				totalMetricsCount += metricsCount
 				loadedDataSources = append(loadedDataSources, ds.Name)
+				customFiles = append(customFiles, CustomMetricsFile{DataSource: ds.Name, File: ds.CustomMetricsFile, Metrics: metricsCount})
 
 				// 输出每个数据源的详细加载信息
-				logger.Logger.Infof("DataSource [%s] loaded %d custom metric(s) from %s",
+				logger.ForDatasource(ds.Name).Infof("DataSource [%s] loaded %d custom metric(s) from %s",
 					ds.Name, metricsCount, ds.CustomMetricsFile)
 
 				// 输出每个指标的详细信息
 				for _, metric := range customConfig.Metrics {
 					fieldsCount := len(metric.MetricsDesc)
-					logger.Logger.Debugf("  - Context: %s, Labels: %v, Fields: %d",
+					logger.ForDatasource(ds.Name).Debugf("  - Context: %s, Labels: %v, Fields: %d",
 						metric.Context, metric.Labels, fieldsCount)
 				}
 			} else {
-				logger.Logger.Warnf("Custom metrics file not found for datasource [%s]: %s",
+				logger.ForDatasource(ds.Name).Warnf("Custom metrics file not found for datasource [%s]: %s",
 					ds.Name, ds.CustomMetricsFile)
+				customFiles = append(customFiles, CustomMetricsFile{DataSource: ds.Name, File: ds.CustomMetricsFile, Error: "file not found"})
 			}
 		}
 	}
 
 	if !needCustomMetrics {
 		logger.Logger.Info("No datasource requires custom metrics")
-		return
+		return nil
 	}
 
 	// 注册自定义指标适配器
@@ -189,4 +230,6 @@ func RegisterCustomMetricsForMultiSource(reg *prometheus.Registry, poolManager *
 	} else {
 		logger.Logger.Warn("Custom metrics adapter registered but no metrics were loaded successfully")
 	}
+
+	return adapter
 }
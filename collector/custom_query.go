@@ -0,0 +1,146 @@
+package collector
+
+import (
+	"context"
+	"dameng_exporter/config"
+	"dameng_exporter/utils"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// CustomSample 自定义指标试运行得到的一个样本
+type CustomSample struct {
+	Name        string
+	Help        string
+	Type        string // counter 或 gauge
+	LabelNames  []string
+	LabelValues []string
+	Value       float64
+}
+
+// CustomQueryResult 单个 [[metric]] 的试运行结果
+type CustomQueryResult struct {
+	Context  string
+	Rows     int
+	Duration time.Duration
+	Samples  []CustomSample
+	Warnings []string
+	Err      error
+}
+
+// RunCustomMetrics 按自定义指标定义对数据库执行一次查询，返回每个 [[metric]] 生成的样本与警告，
+// 解析规则与 CustomMetrics 采集时一致，用于在部署前检查 .metrics 文件
+func RunCustomMetrics(ctx context.Context, db *sql.DB, cfg config.CustomConfig) []CustomQueryResult {
+	results := make([]CustomQueryResult, 0, len(cfg.Metrics))
+	for _, metric := range cfg.Metrics {
+		results = append(results, runCustomMetric(ctx, db, metric))
+	}
+	return results
+}
+
+// runCustomMetric 执行单个 [[metric]] 的查询
+func runCustomMetric(ctx context.Context, db *sql.DB, metric config.CustomMetric) CustomQueryResult {
+	result := CustomQueryResult{Context: metric.Context}
+	warn := func(format string, args ...interface{}) {
+		result.Warnings = append(result.Warnings, fmt.Sprintf(format, args...))
+	}
+	for _, field := range utils.SortedKeys(metric.MetricsType) {
+		if _, ok := metric.MetricsDesc[field]; !ok {
+			warn("metricstype field %s has no metricsdesc entry and is ignored", field)
+		}
+		if t := metric.MetricsType[field]; t != "counter" && t != "gauge" {
+			warn("unknown metricstype %q for field %s, treated as gauge", t, field)
+		}
+	}
+
+	start := time.Now()
+	rows, err := queryDynamicDatabase(ctx, db, metric.Request)
+	result.Duration = time.Since(start)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	result.Rows = len(rows)
+	if len(rows) == 0 {
+		warn("query returned no rows")
+		return result
+	}
+
+	// 查询结果的列名已转为小写，metricsdesc 中的字段需与之一致才会生成指标
+	for _, field := range utils.SortedKeys(metric.MetricsDesc) {
+		if _, ok := rows[0][field]; !ok {
+			warn("metricsdesc field %s has no matching column in the result (columns are lower-cased)", field)
+		}
+	}
+
+	// 同名同标签值的样本与采集时一致：counter 累加（CounterVec.Add），gauge 后者覆盖前者（GaugeVec.Set）
+	index := make(map[string]int)
+	for _, row := range rows {
+		labelValues, values := evaluateCustomRow(metric, row, warn)
+		for _, v := range values {
+			metricType := customMetricType(metric.MetricsType[v.field])
+			key := v.name + "\xff" + strings.Join(labelValues, "\xff")
+			if i, ok := index[key]; ok {
+				if metricType == "counter" {
+					warn("duplicate label values %v for counter %s, values of all rows are summed", labelValues, v.name)
+					result.Samples[i].Value += v.value
+				} else {
+					warn("duplicate label values %v for gauge %s, later rows overwrite earlier ones", labelValues, v.name)
+					result.Samples[i].Value = v.value
+				}
+				continue
+			}
+			index[key] = len(result.Samples)
+			result.Samples = append(result.Samples, CustomSample{
+				Name:        v.name,
+				Help:        metric.MetricsDesc[v.field],
+				Type:        metricType,
+				LabelNames:  metric.Labels,
+				LabelValues: labelValues,
+				Value:       v.value,
+			})
+		}
+	}
+	result.Warnings = dedupWarnings(result.Warnings)
+	return result
+}
+
+// NewCustomSampleMetric 将试运行样本转换为 Prometheus 指标
+func NewCustomSampleMetric(s CustomSample) (prometheus.Metric, error) {
+	valueType := prometheus.GaugeValue
+	if s.Type == "counter" {
+		valueType = prometheus.CounterValue
+	}
+	desc := prometheus.NewDesc(s.Name, s.Help, s.LabelNames, nil)
+	return prometheus.NewConstMetric(desc, valueType, s.Value, s.LabelValues...)
+}
+
+// customMetricType 返回采集时实际使用的指标类型
+func customMetricType(t string) string {
+	if t == "counter" {
+		return "counter"
+	}
+	return "gauge"
+}
+
+// dedupWarnings 合并重复的警告，重复出现的警告附加次数
+func dedupWarnings(warnings []string) []string {
+	counts := make(map[string]int)
+	var unique []string
+	for _, w := range warnings {
+		if counts[w] == 0 {
+			unique = append(unique, w)
+		}
+		counts[w]++
+	}
+	for i, w := range unique {
+		if n := counts[w]; n > 1 {
+			unique[i] = fmt.Sprintf("%s (%d rows)", w, n)
+		}
+	}
+	return unique
+}
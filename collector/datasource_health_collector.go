@@ -11,6 +11,7 @@ import (
 type DatasourceHealthCollector struct {
 	poolManager *db.DBPoolManager
 	desc        *prometheus.Desc
+	filter      *ScrapeFilter // 单次抓取的数据源过滤条件，nil 表示不过滤
 }
 
 // NewDatasourceHealthCollector 创建新的数据源状态采集器
@@ -26,6 +27,13 @@ func NewDatasourceHealthCollector(poolManager *db.DBPoolManager) *DatasourceHeal
 	}
 }
 
+// newFilteredHealthCollector 创建只输出过滤范围内数据源的状态采集器
+func newFilteredHealthCollector(poolManager *db.DBPoolManager, filter *ScrapeFilter) *DatasourceHealthCollector {
+	c := NewDatasourceHealthCollector(poolManager)
+	c.filter = filter
+	return c
+}
+
 // Describe 实现 prometheus.Collector 接口
 func (c *DatasourceHealthCollector) Describe(ch chan<- *prometheus.Desc) {
 	ch <- c.desc
@@ -38,9 +46,10 @@ func (c *DatasourceHealthCollector) Collect(ch chan<- prometheus.Metric) {
 	}
 
 	if config.GlobalMultiConfig != nil {
-		for i := range config.GlobalMultiConfig.DataSources {
-			ds := &config.GlobalMultiConfig.DataSources[i]
-			if ds == nil || !ds.Enabled {
+		dataSources := config.GlobalMultiConfig.DataSourceList()
+		for i := range dataSources {
+			ds := &dataSources[i]
+			if ds == nil || !ds.Enabled || !c.filter.includesDataSource(ds.Name) {
 				continue
 			}
 
@@ -57,10 +66,27 @@ func (c *DatasourceHealthCollector) Collect(ch chan<- prometheus.Metric) {
 				ds.Name,
 			)
 		}
+
+		// 自动发现的成员同样输出健康状态
+		for _, ds := range c.poolManager.DiscoveredDataSources() {
+			if !c.filter.includesDataSource(ds.Name) {
+				continue
+			}
+			value := 0.0
+			if c.poolManager.IsDatasourceHealthy(ds.Name) {
+				value = 1.0
+			}
+			ch <- prometheus.MustNewConstMetric(
+				c.desc,
+				prometheus.GaugeValue,
+				value,
+				ds.Name,
+			)
+		}
 		return
 	}
 
-	for _, pool := range c.poolManager.GetHealthyPools() {
+	for _, pool := range c.filter.filterPools(c.poolManager.GetHealthyPools()) {
 		if pool == nil {
 			continue
 		}
@@ -98,16 +98,16 @@ func (c *DbArchQueueCollector) checkWaitingFieldExists(ctx context.Context) bool
 	c.waitingFieldCheckOnce.Do(func() {
 		var count int
 		if err := c.db.QueryRowContext(ctx, config.QueryArchQueueWaitingFieldExists).Scan(&count); err != nil {
-			logger.Logger.Warnf("[%s] Failed to check V$ARCH_QUEUE WAITING field: %v", c.dataSource, err)
+			logger.ForDatasource(c.dataSource).Warnf("[%s] Failed to check V$ARCH_QUEUE WAITING field: %v", c.dataSource, err)
 			c.waitingFieldExists = false
 			return
 		}
 
 		c.waitingFieldExists = count > 0
 		if c.waitingFieldExists {
-			logger.Logger.Debugf("[%s] V$ARCH_QUEUE WAITING field exists", c.dataSource)
+			logger.ForDatasource(c.dataSource).Debugf("[%s] V$ARCH_QUEUE WAITING field exists", c.dataSource)
 		} else {
-			logger.Logger.Infof("[%s] V$ARCH_QUEUE WAITING field not found, skip waiting metric", c.dataSource)
+			logger.ForDatasource(c.dataSource).Infof("[%s] V$ARCH_QUEUE WAITING field not found, skip waiting metric", c.dataSource)
 		}
 	})
 
@@ -115,7 +115,7 @@ func (c *DbArchSendCollector) isArchiveEnabled(ctx context.Context) bool {
 	query := `SELECT /*+DMDB_CHECK_FLAG*/ PARA_VALUE FROM v$dm_ini WHERE para_name='ARCH_INI'`
 	err := c.db.QueryRowContext(ctx, query).Scan(&paraValue)
 	if err != nil {
-		logger.Logger.Debugf("[%s] Failed to check archive status: %v", c.dataSource, err)
+		logger.ForDatasource(c.dataSource).Debugf("[%s] Failed to check archive status: %v", c.dataSource, err)
 		return false
 	}
 
@@ -128,7 +128,7 @@ func (c *DbArchSendCollector) isArchiveEnabled(ctx context.Context) bool {
 	query = `SELECT /*+DMDB_CHECK_FLAG*/ CASE arch_status WHEN 'VALID' THEN '1' WHEN 'INVALID' THEN '0' END FROM v$arch_status WHERE arch_type='LOCAL'`
 	err = c.db.QueryRowContext(ctx, query).Scan(&archStatus)
 	if err != nil {
-		logger.Logger.Debugf("[%s] Failed to check archive validity: %v", c.dataSource, err)
+		logger.ForDatasource(c.dataSource).Debugf("[%s] Failed to check archive validity: %v", c.dataSource, err)
 		return false
 	}
 
@@ -140,13 +140,13 @@ func (c *DbArchSendCollector) checkArchSendInfoFields(ctx context.Context) bool
 	c.archSendFieldsCheckOnce.Do(func() {
 		var count int
 		if err := c.db.QueryRowContext(ctx, config.QueryArchSendInfoFieldsExist).Scan(&count); err != nil {
-			logger.Logger.Warnf("[%s] Failed to check V$ARCH_SEND_INFO fields existence: %v", c.dataSource, err)
+			logger.ForDatasource(c.dataSource).Warnf("[%s] Failed to check V$ARCH_SEND_INFO fields existence: %v", c.dataSource, err)
 			c.archSendFieldsExist = false
 			return
 		}
 		// 如果count为2，说明两个字段都存在
 		c.archSendFieldsExist = count == 2
-		logger.Logger.Debugf("[%s] V$ARCH_SEND_INFO fields exist: %v (LAST_SEND_CODE，LAST_SEND_DESC)", c.dataSource, c.archSendFieldsExist)
+		logger.ForDatasource(c.dataSource).Debugf("[%s] V$ARCH_SEND_INFO fields exist: %v (LAST_SEND_CODE，LAST_SEND_DESC)", c.dataSource, c.archSendFieldsExist)
 	})
 	return c.archSendFieldsExist
 }
@@ -156,12 +156,12 @@ func (c *DbArchSendCollector) checkArchApplyInfoExists(ctx context.Context) bool
 	c.archApplyInfoCheckOnce.Do(func() {
 		var count int
 		if err := c.db.QueryRowContext(ctx, config.QueryArchApplyInfoExists).Scan(&count); err != nil {
-			logger.Logger.Warnf("[%s] V$ARCH_APPLY_INFO not accessible: %v", c.dataSource, err)
+			logger.ForDatasource(c.dataSource).Warnf("[%s] V$ARCH_APPLY_INFO not accessible: %v", c.dataSource, err)
 			c.archApplyInfoExists = false
 			return
 		}
 		c.archApplyInfoExists = count == 1
-		logger.Logger.Debugf("[%s] V$ARCH_APPLY_INFO exists: %v", c.dataSource, c.archApplyInfoExists)
+		logger.ForDatasource(c.dataSource).Debugf("[%s] V$ARCH_APPLY_INFO exists: %v", c.dataSource, c.archApplyInfoExists)
 	})
 	return c.archApplyInfoExists
 }
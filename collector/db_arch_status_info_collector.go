@@ -145,7 +145,7 @@ func (c *DbArchStatusCollector) getDbArchStatus(ctx context.Context, db *sql.DB)
 		return DB_ARCH_NO_ENABLE, nil
 	}
 
-	logger.Logger.Infof("[%s] Check Database Arch Status Info Success", c.dataSource)
+	logger.ForDatasource(c.dataSource).Infof("[%s] Check Database Arch Status Info Success", c.dataSource)
 	return DB_ARCH_INVALID, nil
 }
 
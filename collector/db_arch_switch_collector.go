@@ -69,13 +69,13 @@ func (c *DbArchSwitchCollector) Collect(ch chan<- prometheus.Metric) {
 	// 查询最新归档创建时间
 	dbArchLatestCreateTimeInfo, err := c.getLatestArchCreateTime(ctx, c.db)
 	if err != nil {
-		logger.Logger.Warnf("[%s] Failed to get latest archive create time: %v", c.dataSource, err)
+		logger.ForDatasource(c.dataSource).Warnf("[%s] Failed to get latest archive create time: %v", c.dataSource, err)
 		return
 	}
 
 	lastCreateTime, err := utils.NullStringTimeToUnixSeconds(dbArchLatestCreateTimeInfo.createTime)
 	if err != nil {
-		logger.Logger.Warnf("[%s] Failed to parse archive create time %q: %v", c.dataSource, utils.NullStringToString(dbArchLatestCreateTimeInfo.createTime), err)
+		logger.ForDatasource(c.dataSource).Warnf("[%s] Failed to parse archive create time %q: %v", c.dataSource, utils.NullStringToString(dbArchLatestCreateTimeInfo.createTime), err)
 		lastCreateTime = 0
 	}
 	// 最新归档创建时间指标
@@ -92,7 +92,7 @@ func (c *DbArchSwitchCollector) isArchiveEnabled(ctx context.Context) bool {
 	query := `SELECT /*+DMDB_CHECK_FLAG*/ PARA_VALUE FROM v$dm_ini WHERE para_name='ARCH_INI'`
 	err := c.db.QueryRowContext(ctx, query).Scan(&paraValue)
 	if err != nil {
-		logger.Logger.Debugf("[%s] Failed to check archive status: %v", c.dataSource, err)
+		logger.ForDatasource(c.dataSource).Debugf("[%s] Failed to check archive status: %v", c.dataSource, err)
 		return false
 	}
 
@@ -105,7 +105,7 @@ func (c *DbArchSwitchCollector) isArchiveEnabled(ctx context.Context) bool {
 	query = `SELECT /*+DMDB_CHECK_FLAG*/ CASE arch_status WHEN 'VALID' THEN '1' WHEN 'INVALID' THEN '0' END FROM v$arch_status WHERE arch_type='LOCAL'`
 	err = c.db.QueryRowContext(ctx, query).Scan(&archStatus)
 	if err != nil {
-		logger.Logger.Debugf("[%s] Failed to check archive validity: %v", c.dataSource, err)
+		logger.ForDatasource(c.dataSource).Debugf("[%s] Failed to check archive validity: %v", c.dataSource, err)
 		return false
 	}
 
@@ -68,7 +68,7 @@ func (c *CkptCollector) Collect(ch chan<- prometheus.Metric) {
 	if err != nil {
 		//if strings.EqualFold(err.Error(), "CKPT") { // 检查视图不存在的特定错误
 		if strings.Contains(err.Error(), "v$CKPT") {
-			logger.Logger.Warnf("[%s] v$CKPT view does not exist, skipping future queries: %v", c.dataSource, err)
+			logger.ForDatasource(c.dataSource).Warnf("[%s] v$CKPT view does not exist, skipping future queries: %v", c.dataSource, err)
 			c.viewExists = false
 			return
 		}
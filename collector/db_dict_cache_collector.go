@@ -71,16 +71,16 @@ func (c *DbDictCacheCollector) checkDictCacheFields(ctx context.Context) []strin
 			query := fmt.Sprintf("SELECT COUNT(*) FROM V$DYNAMIC_TABLE_COLUMNS WHERE TABNAME = 'V$DB_CACHE' AND COLNAME = '%s'", field)
 			var count int
 			if err := c.db.QueryRowContext(ctx, query).Scan(&count); err != nil {
-				logger.Logger.Warnf("[%s] Failed to check V$DB_CACHE field %s: %v", c.dataSource, field, err)
+				logger.ForDatasource(c.dataSource).Warnf("[%s] Failed to check V$DB_CACHE field %s: %v", c.dataSource, field, err)
 				continue
 			}
 			if count > 0 {
 				c.availableFields = append(c.availableFields, field)
-				logger.Logger.Debugf("[%s] V$DB_CACHE field %s exists", c.dataSource, field)
+				logger.ForDatasource(c.dataSource).Debugf("[%s] V$DB_CACHE field %s exists", c.dataSource, field)
 			}
 		}
 
-		logger.Logger.Infof("[%s] V$DB_CACHE available fields: %v", c.dataSource, c.availableFields)
+		logger.ForDatasource(c.dataSource).Infof("[%s] V$DB_CACHE available fields: %v", c.dataSource, c.availableFields)
 	})
 	return c.availableFields
 }
@@ -136,7 +136,7 @@ func (c *DbDictCacheCollector) Collect(ch chan<- prometheus.Metric) {
 	// 检查可用字段
 	availableFields := c.checkDictCacheFields(ctx)
 	if len(availableFields) == 0 {
-		logger.Logger.Warnf("[%s] No dictionary cache fields available in V$DB_CACHE", c.dataSource)
+		logger.ForDatasource(c.dataSource).Warnf("[%s] No dictionary cache fields available in V$DB_CACHE", c.dataSource)
 		return
 	}
 
@@ -146,7 +146,7 @@ func (c *DbDictCacheCollector) Collect(ch chan<- prometheus.Metric) {
 		return
 	}
 
-	//	logger.Logger.Debugf("[%s] Executing dictionary cache query: %s", c.dataSource, query)
+	//	logger.ForDatasource(c.dataSource).Debugf("[%s] Executing dictionary cache query: %s", c.dataSource, query)
 
 	// 执行查询
 	var dictCacheInfo DictCacheInfo
@@ -158,7 +158,7 @@ func (c *DbDictCacheCollector) Collect(ch chan<- prometheus.Metric) {
 
 	if err != nil {
 		if err == sql.ErrNoRows {
-			logger.Logger.Debugf("[%s] No dictionary cache data available", c.dataSource)
+			logger.ForDatasource(c.dataSource).Debugf("[%s] No dictionary cache data available", c.dataSource)
 		} else {
 			logger.Logger.Error(fmt.Sprintf("[%s] Error querying dictionary cache info", c.dataSource), zap.Error(err))
 		}
@@ -168,30 +168,30 @@ func (c *DbDictCacheCollector) Collect(ch chan<- prometheus.Metric) {
 	// 发送指标到Prometheus
 	// LRU_DISCARD - 由于缓存池已满导致字典对象被淘汰的次数
 	if dictCacheInfo.lruDiscard.Valid {
-		ch <- prometheus.MustNewConstMetric(
+		ch <- newInstanceCounter(
 			c.dictCacheTotalDesc,
-			prometheus.CounterValue,
 			dictCacheInfo.lruDiscard.Float64,
+			c.dataSource,
 			"lru_discard",
 		)
 	}
 
 	// DDL_DISCARD - DDL操作导致字典对象被淘汰的次数
 	if dictCacheInfo.ddlDiscard.Valid {
-		ch <- prometheus.MustNewConstMetric(
+		ch <- newInstanceCounter(
 			c.dictCacheTotalDesc,
-			prometheus.CounterValue,
 			dictCacheInfo.ddlDiscard.Float64,
+			c.dataSource,
 			"ddl_discard",
 		)
 	}
 
 	// DISABLED_DICT_NUM - 缓存池中被淘汰字典对象的总数
 	if dictCacheInfo.disabledDictNum.Valid {
-		ch <- prometheus.MustNewConstMetric(
+		ch <- newInstanceCounter(
 			c.dictCacheTotalDesc,
-			prometheus.CounterValue,
 			dictCacheInfo.disabledDictNum.Float64,
+			c.dataSource,
 			"disabled_dict_num",
 		)
 	}
@@ -2,6 +2,7 @@ package collector
 
 import (
 	"context"
+	"dameng_exporter/cache"
 	"dameng_exporter/config"
 	"dameng_exporter/logger"
 	"dameng_exporter/utils"
@@ -148,6 +149,9 @@ func (c *DBInstanceRunningInfoCollector) Collect(ch chan<- prometheus.Metric) {
 			logger.Logger.Error(fmt.Sprintf("[%s] Error parsing start time", c.dataSource), zap.Error(err))
 			// 如果转换失败则赋予默认时间值（此处使用东八区）
 			startTime = time.Date(2006, time.January, 1, 0, 0, 0, 0, loc)
+		} else {
+			// 记录实例启动时间，作为计数器类指标的创建时间
+			setInstanceStartTime(c.dataSource, startTime)
 		}
 		/*		// 解析时间戳字符串为 time.Time 类型
 				startTime, err := time.Parse("2006-01-02 15:04:05", startTimeStr)
@@ -185,7 +189,7 @@ func (c *DBInstanceRunningInfoCollector) collectMetrics(ch chan<- prometheus.Met
 	ch <- prometheus.MustNewConstMetric(c.statusDesc, prometheus.GaugeValue, data["status"])
 	ch <- prometheus.MustNewConstMetric(c.modeDesc, prometheus.GaugeValue, data["mode"])
 	ch <- prometheus.MustNewConstMetric(c.trxNumDesc, prometheus.GaugeValue, data["trxNum"])
-	ch <- prometheus.MustNewConstMetric(c.deadlockDesc, prometheus.CounterValue, data["deadlockNum"])
+	ch <- newInstanceCounter(c.deadlockDesc, data["deadlockNum"], c.dataSource)
 	ch <- prometheus.MustNewConstMetric(c.threadNumDesc, prometheus.GaugeValue, data["threadNum"])
 	ch <- prometheus.MustNewConstMetric(c.dbStartDayDesc, prometheus.GaugeValue, data["dbStartDay"])
 }
@@ -200,12 +204,9 @@ Default Case：如果 AlarmSwitchStr 缓存键不存在，设置 switchingOccurs
 func (c *DBInstanceRunningInfoCollector) handleDatabaseModeSwitch(ch chan<- prometheus.Metric, mode float64) {
 	modeStr := strconv.FormatFloat(mode, 'f', -1, 64)
 
-	// 使用带数据源的缓存键
-	switchStrKey := fmt.Sprintf("%s_%s", AlarmSwitchStr, c.dataSource)
-	switchOccurKey := fmt.Sprintf("%s_%s", AlarmSwitchOccur, c.dataSource)
-
-	cachedModeValue, modeExists := config.GetFromCache(switchStrKey) //这个key存储的是 mode值
-	switchOccurExists := config.GetKeyExists(switchOccurKey)         //这个key表示已经发生切换了，保留的时间
+	// 告警键按数据源划分命名空间，启用 alarmStateFile 时会持久化，重启后仍可感知切换
+	cachedModeValue, modeExists := config.GetAlarmState(c.dataSource, AlarmSwitchStr) //这个key存储的是 mode值
+	switchOccurExists := config.AlarmStateExists(c.dataSource, AlarmSwitchOccur)      //这个key表示已经发生切换了，保留的时间
 
 	switch {
 	case switchOccurExists:
@@ -214,10 +215,16 @@ func (c *DBInstanceRunningInfoCollector) handleDatabaseModeSwitch(ch chan<- prom
 		ch <- prometheus.MustNewConstMetric(c.switchingOccursDesc, prometheus.GaugeValue, AlarmStatus_Normal)
 	case modeExists:
 		ch <- prometheus.MustNewConstMetric(c.switchingOccursDesc, prometheus.GaugeValue, AlarmStatus_Unusual)
-		config.DeleteFromCache(switchStrKey)
-		config.SetCache(switchOccurKey, strconv.Itoa(AlarmStatus_Unusual), time.Minute*time.Duration(config.Global.GetAlarmKeyCacheTime()))
+		if err := config.DeleteAlarmState(c.dataSource, AlarmSwitchStr); err != nil {
+			logger.ForDatasource(c.dataSource).Warnf("[%s] Failed to persist alarm state: %v", c.dataSource, err)
+		}
+		if err := config.SetAlarmState(c.dataSource, AlarmSwitchOccur, strconv.Itoa(AlarmStatus_Unusual), cache.Default.TTL(c.dataSource, cache.KindAlarm)); err != nil {
+			logger.ForDatasource(c.dataSource).Warnf("[%s] Failed to persist alarm state: %v", c.dataSource, err)
+		}
 	default:
-		config.SetCache(switchStrKey, modeStr, time.Minute*time.Duration(config.Global.GetAlarmKeyCacheTime()*2))
+		if err := config.SetAlarmState(c.dataSource, AlarmSwitchStr, modeStr, 2*cache.Default.TTL(c.dataSource, cache.KindAlarm)); err != nil {
+			logger.ForDatasource(c.dataSource).Warnf("[%s] Failed to persist alarm state: %v", c.dataSource, err)
+		}
 		ch <- prometheus.MustNewConstMetric(c.switchingOccursDesc, prometheus.GaugeValue, AlarmStatus_Normal)
 	}
 }
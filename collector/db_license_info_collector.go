@@ -89,11 +89,11 @@ func (c *DbLicenseCollector) Collect(ch chan<- prometheus.Metric) {
 			betweenDay := expiredDate.Sub(time.Now()).Hours() / 24
 			returnDateStr = fmt.Sprintf("%.0f", betweenDay)
 			licenseStatus = returnDateStr
-			logger.Logger.Infof("[%s] Check Database License Date Info Success, betweenDay is %s day", c.dataSource, returnDateStr)
+			logger.ForDatasource(c.dataSource).Infof("[%s] Check Database License Date Info Success, betweenDay is %s day", c.dataSource, returnDateStr)
 		} else {
 			licenseStatus = "无限制"
 			returnDateStr = "-1"
-			logger.Logger.Debugf("[%s] Check Database License Date Info Success, Expired Unlimited", c.dataSource)
+			logger.ForDatasource(c.dataSource).Debugf("[%s] Check Database License Date Info Success, Expired Unlimited", c.dataSource)
 		}
 
 		ch <- prometheus.MustNewConstMetric(
@@ -65,7 +65,7 @@ func (c *DbLogHistoryCollector) Collect(ch chan<- prometheus.Metric) {
 
 	lastSwitchTime, err := utils.NullStringTimeToUnixSeconds(rectime)
 	if err != nil {
-		logger.Logger.Warnf("[%s] Failed to parse redo log rectime %q: %v", c.dataSource, utils.NullStringToString(rectime), err)
+		logger.ForDatasource(c.dataSource).Warnf("[%s] Failed to parse redo log rectime %q: %v", c.dataSource, utils.NullStringToString(rectime), err)
 		lastSwitchTime = 0
 	}
 
@@ -48,12 +48,12 @@ func (c *MonitorInfoCollector) checkDmMonitorExists(ctx context.Context) bool {
 		const query = "SELECT COUNT(1) FROM V$DYNAMIC_TABLES WHERE NAME = 'V$DMMONITOR'"
 		var count int
 		if err := c.db.QueryRowContext(ctx, query).Scan(&count); err != nil {
-			logger.Logger.Warnf("[%s] Failed to check V$DMMONITOR existence: %v", c.dataSource, err)
+			logger.ForDatasource(c.dataSource).Warnf("[%s] Failed to check V$DMMONITOR existence: %v", c.dataSource, err)
 			c.viewChecked = false
 			return
 		}
 		c.viewChecked = count == 1
-		logger.Logger.Debugf("[%s] V$DMMONITOR exists: %v", c.dataSource, c.viewChecked)
+		logger.ForDatasource(c.dataSource).Debugf("[%s] V$DMMONITOR exists: %v", c.dataSource, c.viewChecked)
 	})
 	return c.viewChecked
 }
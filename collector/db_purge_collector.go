@@ -83,14 +83,14 @@ func (c *PurgeCollector) getPurgeInfos() ([]PurgeInfo, error) {
 		var isRunning, purgeForTs sql.NullString
 		err := rows.Scan(&info.ObjNum, &isRunning, &purgeForTs)
 		if err != nil {
-			logger.Logger.Errorf("[%s] Error scanning purge row: %v", c.dataSource, err)
+			logger.ForDatasource(c.dataSource).Errorf("[%s] Error scanning purge row: %v", c.dataSource, err)
 			continue
 		}
 		purgeInfos = append(purgeInfos, info)
 	}
 
 	if err = rows.Err(); err != nil {
-		logger.Logger.Errorf("[%s] Error iterating purge rows: %v", c.dataSource, err)
+		logger.ForDatasource(c.dataSource).Errorf("[%s] Error iterating purge rows: %v", c.dataSource, err)
 		return nil, err
 	}
 
@@ -67,14 +67,14 @@ func (c *DbRlogFileCollector) Collect(ch chan<- prometheus.Metric) {
 	for rows.Next() {
 		var row rlogFileRow
 		if err := rows.Scan(&row.fileID, &row.path, &row.createTime, &row.size); err != nil {
-			logger.Logger.Warnf("[%s] Failed to scan V$RLOGFILE row: %v", c.dataSource, err)
+			logger.ForDatasource(c.dataSource).Warnf("[%s] Failed to scan V$RLOGFILE row: %v", c.dataSource, err)
 			continue
 		}
 		c.emitMetric(ch, row)
 	}
 
 	if err := rows.Err(); err != nil {
-		logger.Logger.Warnf("[%s] Iterating V$RLOGFILE rows failed: %v", c.dataSource, err)
+		logger.ForDatasource(c.dataSource).Warnf("[%s] Iterating V$RLOGFILE rows failed: %v", c.dataSource, err)
 	}
 }
 
@@ -102,13 +102,13 @@ func (c *DbRedoLogLsnCollector) checkRlogView(ctx context.Context) bool {
 	c.viewCheckOnce.Do(func() {
 		var count int
 		if err := c.db.QueryRowContext(ctx, config.QueryRlogViewExists).Scan(&count); err != nil {
-			logger.Logger.Warnf("[%s] Failed to check V$RLOG view existence: %v", c.dataSource, err)
+			logger.ForDatasource(c.dataSource).Warnf("[%s] Failed to check V$RLOG view existence: %v", c.dataSource, err)
 			c.viewExists = false
 			return
 		}
 		c.viewExists = count > 0
 		if !c.viewExists {
-			logger.Logger.Infof("[%s] V$RLOG view not found, skip redo LSN metrics", c.dataSource)
+			logger.ForDatasource(c.dataSource).Infof("[%s] V$RLOG view not found, skip redo LSN metrics", c.dataSource)
 		}
 	})
 	return c.viewExists
@@ -118,13 +118,13 @@ func (c *DbRedoLogLsnCollector) checkRlogColumns(ctx context.Context) bool {
 	c.columnCheckOnce.Do(func() {
 		var count int
 		if err := c.db.QueryRowContext(ctx, config.QueryRlogColumnsExist).Scan(&count); err != nil {
-			logger.Logger.Warnf("[%s] Failed to check V$RLOG columns: %v", c.dataSource, err)
+			logger.ForDatasource(c.dataSource).Warnf("[%s] Failed to check V$RLOG columns: %v", c.dataSource, err)
 			c.columnsExist = false
 			return
 		}
 		c.columnsExist = count == requiredRlogColumnCount
 		if !c.columnsExist {
-			logger.Logger.Infof("[%s] Required V$RLOG columns missing, skip redo LSN metrics", c.dataSource)
+			logger.ForDatasource(c.dataSource).Infof("[%s] Required V$RLOG columns missing, skip redo LSN metrics", c.dataSource)
 		}
 	})
 	return c.columnsExist
@@ -143,14 +143,14 @@ func (c *DbRedoLogLsnCollector) queryRlogInfo(ctx context.Context) (rlogLsnInfo,
 	for rows.Next() {
 		var row rlogLsnInfo
 		if err := rows.Scan(&row.ckptLsn, &row.fileLsn, &row.flushLsn, &row.curLsn); err != nil {
-			logger.Logger.Warnf("[%s] Failed to scan V$RLOG row: %v", c.dataSource, err)
+			logger.ForDatasource(c.dataSource).Warnf("[%s] Failed to scan V$RLOG row: %v", c.dataSource, err)
 			continue
 		}
 		result = mergeRlogInfo(result, row)
 	}
 
 	if err := rows.Err(); err != nil {
-		logger.Logger.Warnf("[%s] Iterating V$RLOG rows failed: %v", c.dataSource, err)
+		logger.ForDatasource(c.dataSource).Warnf("[%s] Iterating V$RLOG rows failed: %v", c.dataSource, err)
 		return result, err
 	}
 
@@ -9,9 +9,95 @@ import (
 	"fmt"
 	"github.com/prometheus/client_golang/prometheus"
 	"go.uber.org/zap"
+	"hash/fnv"
+	"regexp"
+	"strings"
+	"sync"
 	"time"
 )
 
+// slowSQLExecBuckets 慢SQL执行耗时直方图的桶（秒）
+var slowSQLExecBuckets = []float64{1, 5, 10, 30, 60, 300, 600, 1800, 3600}
+
+const slowSQLExecHelp = "Execution time of finished slow SQL statements observed by the exporter (seconds)"
+
+// slowSQLExecDesc 慢SQL耗时直方图的描述，与各数据源直方图的名称和说明一致
+var slowSQLExecDesc = prometheus.NewDesc(dmdbms_slow_sql_exec_seconds, slowSQLExecHelp, nil, nil)
+
+// slowSQLTrackers 各数据源的慢SQL跟踪状态，采集器每次抓取都会重新创建，状态需要跨抓取保留
+var slowSQLTrackers sync.Map // map[string]*slowSQLTracker
+
+// slowStatement 正在执行的慢SQL
+type slowStatement struct {
+	sessionID   string
+	fingerprint string
+	execSeconds float64 // 最近一次采集时的已执行时长
+}
+
+// slowSQLTracker 跟踪数据源上正在执行的慢SQL，语句结束（不再出现在完整的结果中）时按最后一次看到的耗时计入直方图，
+// 并以会话ID与SQL指纹作为 exemplar，便于在 Grafana 中从耗时尖刺定位到具体会话
+type slowSQLTracker struct {
+	mu        sync.Mutex
+	running   map[string]slowStatement // key 为 会话ID|最近接收时间，同一会话的新语句接收时间不同
+	histogram prometheus.Histogram
+}
+
+// getSlowSQLTracker 获取数据源的慢SQL跟踪状态，不存在时创建
+func getSlowSQLTracker(dataSource string) *slowSQLTracker {
+	if tracker, ok := slowSQLTrackers.Load(dataSource); ok {
+		return tracker.(*slowSQLTracker)
+	}
+	tracker, _ := slowSQLTrackers.LoadOrStore(dataSource, &slowSQLTracker{
+		running: make(map[string]slowStatement),
+		histogram: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name:    dmdbms_slow_sql_exec_seconds,
+			Help:    slowSQLExecHelp,
+			Buckets: slowSQLExecBuckets,
+		}),
+	})
+	return tracker.(*slowSQLTracker)
+}
+
+// update 用本次采集到的慢SQL更新跟踪状态，已结束的语句计入直方图。
+// 结果按耗时排序并受 slowSqlMaxRows 限制，truncated 为 true 时未出现的语句可能只是被更慢的语句挤出，
+// 继续保留等待后续采集，不视为已结束
+func (t *slowSQLTracker) update(current map[string]slowStatement, truncated bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for key, stmt := range t.running {
+		if _, ok := current[key]; ok {
+			continue
+		}
+		if truncated {
+			current[key] = stmt
+			continue
+		}
+		t.histogram.(prometheus.ExemplarObserver).ObserveWithExemplar(stmt.execSeconds, prometheus.Labels{
+			"sess_id":         stmt.sessionID,
+			"sql_fingerprint": stmt.fingerprint,
+		})
+	}
+	t.running = current
+}
+
+var (
+	sqlStringLiteral  = regexp.MustCompile(`'(?:[^']|'')*'`)
+	sqlNumericLiteral = regexp.MustCompile(`\b\d+(?:\.\d+)?\b`)
+	sqlWhitespace     = regexp.MustCompile(`\s+`)
+)
+
+// sqlFingerprint 计算SQL指纹：去除字面量与多余空白、统一大小写后取 FNV-64a 哈希，相同结构的语句指纹相同
+func sqlFingerprint(sqlText string) string {
+	normalized := sqlStringLiteral.ReplaceAllString(sqlText, "?")
+	normalized = sqlNumericLiteral.ReplaceAllString(normalized, "?")
+	normalized = strings.ToLower(strings.TrimSpace(sqlWhitespace.ReplaceAllString(normalized, " ")))
+
+	h := fnv.New64a()
+	h.Write([]byte(normalized))
+	return fmt.Sprintf("%016x", h.Sum64())
+}
+
 type SessionInfoCollector struct {
 	db              *sql.DB
 	slowSQLInfoDesc *prometheus.Desc
@@ -48,11 +134,12 @@ func NewSlowSessionInfoCollector(db *sql.DB) MetricCollector {
 
 func (c *SessionInfoCollector) Describe(ch chan<- *prometheus.Desc) {
 	ch <- c.slowSQLInfoDesc
+	ch <- slowSQLExecDesc
 }
 
 func (c *SessionInfoCollector) Collect(ch chan<- prometheus.Metric) {
 	if !config.Global.GetCheckSlowSQL() {
-		logger.Logger.Debugf("[%s] CheckSlowSQL is false, skip collecting slow SQL info", c.dataSource)
+		logger.ForDatasource(c.dataSource).Debugf("[%s] CheckSlowSQL is false, skip collecting slow SQL info", c.dataSource)
 		return
 	}
 
@@ -71,10 +158,12 @@ func (c *SessionInfoCollector) Collect(ch chan<- prometheus.Metric) {
 	defer rows.Close()
 
 	var sessionInfos []SessionInfo
+	rowsFailed := false
 	for rows.Next() {
 		var info SessionInfo
 		if err := rows.Scan(&info.ExecTime, &info.SlowSQL, &info.SessID, &info.CurrSch, &info.ThrdID, &info.LastRecvTime, &info.ConnIP); err != nil {
 			logger.Logger.Error(fmt.Sprintf("[%s] Error scanning row", c.dataSource), zap.Error(err))
+			rowsFailed = true
 			continue
 		}
 		sessionInfos = append(sessionInfos, info)
@@ -82,7 +171,24 @@ func (c *SessionInfoCollector) Collect(ch chan<- prometheus.Metric) {
 
 	if err := rows.Err(); err != nil {
 		logger.Logger.Error(fmt.Sprintf("[%s] Error with rows", c.dataSource), zap.Error(err))
+		rowsFailed = true
 	}
+	// 更新慢SQL跟踪状态，已结束的语句计入耗时直方图；结果不完整时无法判断哪些语句已结束，跳过本次更新
+	tracker := getSlowSQLTracker(c.dataSource)
+	if !rowsFailed {
+		current := make(map[string]slowStatement, len(sessionInfos))
+		for _, info := range sessionInfos {
+			sessionID := utils.NullStringToString(info.SessID)
+			current[sessionID+"|"+utils.NullTimeToString(info.LastRecvTime)] = slowStatement{
+				sessionID:   sessionID,
+				fingerprint: sqlFingerprint(utils.NullStringToString(info.SlowSQL)),
+				execSeconds: utils.NullFloat64ToFloat64(info.ExecTime) / 1000,
+			}
+		}
+		tracker.update(current, len(sessionInfos) >= config.Global.GetSlowSqlMaxRows())
+	}
+	ch <- tracker.histogram
+
 	// 发送数据到 Prometheus
 	for _, info := range sessionInfos {
 		sessionID := utils.NullStringToString(info.SessID)
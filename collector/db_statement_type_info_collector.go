@@ -79,10 +79,10 @@ func (c *DbSqlExecTypeCollector) Collect(ch chan<- prometheus.Metric) {
 	for _, info := range sysstatInfos {
 		statementName := utils.NullStringToString(info.Name)
 
-		ch <- prometheus.MustNewConstMetric(
+		ch <- newInstanceCounter(
 			c.statementTypeDesc,
-			prometheus.CounterValue,
 			utils.NullFloat64ToFloat64(info.StatVal),
+			c.dataSource,
 			statementName,
 		)
 	}
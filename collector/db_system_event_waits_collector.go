@@ -104,10 +104,10 @@ func (c *DbSystemEventWaitCollector) Collect(ch chan<- prometheus.Metric) {
 		}
 
 		// 5.3 输出 Counter 指标，TOTAL_WAITS 作为等待次数。
-		ch <- prometheus.MustNewConstMetric(
+		ch <- newInstanceCounter(
 			c.eventWaitsDesc,
-			prometheus.CounterValue,
 			utils.NullFloat64ToFloat64(info.TotalWaits),
+			c.dataSource,
 			event,
 		)
 	}
@@ -125,12 +125,12 @@ func (c *DbSystemEventWaitCollector) checkSystemEventViewExists(ctx context.Cont
 		// 通过 V$DYNAMIC_TABLES 判断目标视图是否被注册，避免访问不存在视图导致的 SQL 错误。
 		var count int
 		if err := c.db.QueryRowContext(ctx, config.QuerySystemEventViewExistsSqlStr).Scan(&count); err != nil {
-			logger.Logger.Warnf("[%s] Failed to check V$SYSTEM_EVENT existence: %v", c.dataSource, err)
+			logger.ForDatasource(c.dataSource).Warnf("[%s] Failed to check V$SYSTEM_EVENT existence: %v", c.dataSource, err)
 			c.viewExists = false
 			return
 		}
 		c.viewExists = count > 0
-		logger.Logger.Debugf("[%s] V$SYSTEM_EVENT exists: %v", c.dataSource, c.viewExists)
+		logger.ForDatasource(c.dataSource).Debugf("[%s] V$SYSTEM_EVENT exists: %v", c.dataSource, c.viewExists)
 	})
 	return c.viewExists
 }
@@ -141,12 +141,12 @@ func (c *DbSystemEventWaitCollector) checkSystemEventColumnsExist(ctx context.Co
 		// 查询系统列元数据，确认 EVENT 与 TOTAL_WAITS 是否都可用。
 		var count int
 		if err := c.db.QueryRowContext(ctx, config.QuerySystemEventColumnsExistSqlStr).Scan(&count); err != nil {
-			logger.Logger.Warnf("[%s] Failed to check V$SYSTEM_EVENT columns: %v", c.dataSource, err)
+			logger.ForDatasource(c.dataSource).Warnf("[%s] Failed to check V$SYSTEM_EVENT columns: %v", c.dataSource, err)
 			c.columnsExist = false
 			return
 		}
 		c.columnsExist = count >= 2
-		logger.Logger.Debugf("[%s] V$SYSTEM_EVENT columns valid: %v", c.dataSource, c.columnsExist)
+		logger.ForDatasource(c.dataSource).Debugf("[%s] V$SYSTEM_EVENT columns valid: %v", c.dataSource, c.columnsExist)
 	})
 	return c.columnsExist
 }
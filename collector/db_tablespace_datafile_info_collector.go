@@ -2,11 +2,11 @@ package collector
 
 import (
 	"context"
+	"dameng_exporter/cache"
 	"dameng_exporter/config"
 	"dameng_exporter/logger"
 	"dameng_exporter/utils"
 	"database/sql"
-	"encoding/json"
 	"fmt"
 	"github.com/prometheus/client_golang/prometheus"
 	"go.uber.org/zap"
@@ -62,24 +62,16 @@ func (c *TableSpaceDateFileInfoCollector) Collect(ch chan<- prometheus.Metric) {
 	//保存全局结果对象，可以用来做缓存以及序列化
 	var tablespaceInfos []TableSpaceDateFileInfo
 
-	// 从缓存中获取数据，使用带数据源的缓存键
-	cacheKey := fmt.Sprintf("%s_%s", dmdbms_tablespace_file_total_info, c.dataSource)
-	if cachedJSON, found := config.GetFromCache(cacheKey); found {
-		// 将缓存中的 JSON 字符串转换为 TablespaceInfo 切片
-		if err := json.Unmarshal([]byte(cachedJSON), &tablespaceInfos); err != nil {
-			// 处理反序列化错误
-			logger.Logger.Error(fmt.Sprintf("[%s] Error unmarshaling cached data", c.dataSource), zap.Error(err))
-			// 反序列化失败，忽略缓存中的数据，继续查询数据库
-			cachedJSON = "" // 清空缓存数据，确保后续不使用过期或损坏的数据
-		} else {
-			logger.Logger.Infof("[%s] Use cache TablespaceDateFile data", c.dataSource)
-			// 使用缓存的数据
-			for _, info := range tablespaceInfos {
-				ch <- prometheus.MustNewConstMetric(c.totalDesc, prometheus.GaugeValue, info.TotalSize, info.Path, info.AutoExtend, info.NextSize, info.MaxSize)
-				ch <- prometheus.MustNewConstMetric(c.freeDesc, prometheus.GaugeValue, info.FreeSize, info.Path, info.AutoExtend, info.NextSize, info.MaxSize)
-			}
-			return
+	// 从缓存中获取数据，缓存键按数据源和采集器划分
+	cacheKey := cache.Key{DataSource: c.dataSource, Collector: "TableSpaceDateFileInfo", Name: dmdbms_tablespace_file_total_info}
+	if cachedInfos, found := cache.Get[[]TableSpaceDateFileInfo](cache.Default, cacheKey); found {
+		logger.ForDatasource(c.dataSource).Infof("[%s] Use cache TablespaceDateFile data", c.dataSource)
+		// 使用缓存的数据
+		for _, info := range cachedInfos {
+			ch <- prometheus.MustNewConstMetric(c.totalDesc, prometheus.GaugeValue, info.TotalSize, info.Path, info.AutoExtend, info.NextSize, info.MaxSize)
+			ch <- prometheus.MustNewConstMetric(c.freeDesc, prometheus.GaugeValue, info.FreeSize, info.Path, info.AutoExtend, info.NextSize, info.MaxSize)
 		}
+		return
 	}
 
 	if err := utils.CheckDBConnectionWithSource(c.db, c.dataSource); err != nil {
@@ -113,15 +105,8 @@ func (c *TableSpaceDateFileInfoCollector) Collect(ch chan<- prometheus.Metric) {
 		ch <- prometheus.MustNewConstMetric(c.freeDesc, prometheus.GaugeValue, info.FreeSize, info.Path, info.AutoExtend, info.NextSize, info.MaxSize)
 	}
 
-	// 将 TablespaceInfo 切片序列化为 JSON 字符串
-	valueJSON, err := json.Marshal(tablespaceInfos)
-	if err != nil {
-		// 处理序列化错误
-		logger.Logger.Error(fmt.Sprintf("[%s] TablespaceInfo marshal error", c.dataSource), zap.Error(err))
-		return
-	}
-	// 将查询结果存入缓存，重用之前定义的cacheKey
-	config.SetCache(cacheKey, string(valueJSON), time.Minute*time.Duration(config.Global.GetBigKeyDataCacheTime()))
-	logger.Logger.Infof("[%s] TablespaceFileInfoCollector exec finish", c.dataSource)
+	// 将查询结果存入缓存，过期时间取该数据源的 bigKeyDataCacheTime
+	cache.Default.Set(cacheKey, cache.KindBigKey, tablespaceInfos)
+	logger.ForDatasource(c.dataSource).Infof("[%s] TablespaceFileInfoCollector exec finish", c.dataSource)
 
 }
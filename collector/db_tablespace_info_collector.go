@@ -2,11 +2,11 @@ package collector
 
 import (
 	"context"
+	"dameng_exporter/cache"
 	"dameng_exporter/config"
 	"dameng_exporter/logger"
 	"dameng_exporter/utils"
 	"database/sql"
-	"encoding/json"
 	"fmt"
 	"github.com/prometheus/client_golang/prometheus"
 	"go.uber.org/zap"
@@ -59,24 +59,16 @@ func (c *TableSpaceInfoCollector) Collect(ch chan<- prometheus.Metric) {
 	//保存全局结果对象，可以用来做缓存以及序列化
 	var tablespaceInfos []TableSpaceInfo
 
-	// 从缓存中获取数据，使用带数据源的缓存键
-	cacheKey := fmt.Sprintf("%s_%s", dmdbms_tablespace_size_total_info, c.dataSource)
-	if cachedJSON, found := config.GetFromCache(cacheKey); found {
-		// 将缓存中的 JSON 字符串转换为 TablespaceInfo 切片
-		if err := json.Unmarshal([]byte(cachedJSON), &tablespaceInfos); err != nil {
-			// 处理反序列化错误
-			logger.Logger.Error(fmt.Sprintf("[%s] Error unmarshaling cached data", c.dataSource), zap.Error(err))
-			// 反序列化失败，忽略缓存中的数据，继续查询数据库
-			cachedJSON = "" // 清空缓存数据，确保后续不使用过期或损坏的数据
-		} else {
-			logger.Logger.Infof("[%s] Use cache TablespaceInfo data", c.dataSource)
-			// 使用缓存的数据
-			for _, info := range tablespaceInfos {
-				ch <- prometheus.MustNewConstMetric(c.totalDesc, prometheus.GaugeValue, info.TotalSize, info.TablespaceName)
-				ch <- prometheus.MustNewConstMetric(c.freeDesc, prometheus.GaugeValue, info.FreeSize, info.TablespaceName)
-			}
-			return
+	// 从缓存中获取数据，缓存键按数据源和采集器划分
+	cacheKey := cache.Key{DataSource: c.dataSource, Collector: "TableSpaceInfo", Name: dmdbms_tablespace_size_total_info}
+	if cachedInfos, found := cache.Get[[]TableSpaceInfo](cache.Default, cacheKey); found {
+		logger.ForDatasource(c.dataSource).Infof("[%s] Use cache TablespaceInfo data", c.dataSource)
+		// 使用缓存的数据
+		for _, info := range cachedInfos {
+			ch <- prometheus.MustNewConstMetric(c.totalDesc, prometheus.GaugeValue, info.TotalSize, info.TablespaceName)
+			ch <- prometheus.MustNewConstMetric(c.freeDesc, prometheus.GaugeValue, info.FreeSize, info.TablespaceName)
 		}
+		return
 	}
 
 	if err := utils.CheckDBConnectionWithSource(c.db, c.dataSource); err != nil {
@@ -110,15 +102,8 @@ func (c *TableSpaceInfoCollector) Collect(ch chan<- prometheus.Metric) {
 		ch <- prometheus.MustNewConstMetric(c.freeDesc, prometheus.GaugeValue, info.FreeSize, info.TablespaceName)
 	}
 
-	// 将 TablespaceInfo 切片序列化为 JSON 字符串
-	valueJSON, err := json.Marshal(tablespaceInfos)
-	if err != nil {
-		// 处理序列化错误
-		logger.Logger.Error(fmt.Sprintf("[%s] TablespaceInfo marshal error", c.dataSource), zap.Error(err))
-		return
-	}
-	// 将查询结果存入缓存，重用之前定义的cacheKey
-	config.SetCache(cacheKey, string(valueJSON), time.Minute*time.Duration(config.Global.GetBigKeyDataCacheTime()))
+	// 将查询结果存入缓存，过期时间取该数据源的 bigKeyDataCacheTime
+	cache.Default.Set(cacheKey, cache.KindBigKey, tablespaceInfos)
 	//	logger.Logger.Infof("TablespaceFileInfo exec finish")
 
 }
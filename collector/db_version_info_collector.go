@@ -2,6 +2,7 @@ package collector
 
 import (
 	"context"
+	"dameng_exporter/cache"
 	"dameng_exporter/config"
 	"dameng_exporter/logger"
 	"dameng_exporter/utils"
@@ -33,6 +34,13 @@ type DbVersionInfo struct {
 	innerVer  sql.NullString
 }
 
+// dbVersionLabels 缓存的版本标签值
+type dbVersionLabels struct {
+	idCode    string
+	buildType string
+	innerVer  string
+}
+
 // 初始化收集器
 func NewDbVersionCollector(db *sql.DB) MetricCollector {
 	return &DbVersionCollector{
@@ -56,25 +64,21 @@ func (c *DbVersionCollector) Collect(ch chan<- prometheus.Metric) {
 		return
 	}
 
-	// 构建缓存键，包含数据源标识
-	cacheKey := fmt.Sprintf("db_version_info_%s", c.dataSource)
+	// 构建缓存键，按数据源和采集器划分
+	cacheKey := cache.Key{DataSource: c.dataSource, Collector: "DbVersion", Name: dmdbms_version}
 
 	// 尝试从缓存获取版本信息
-	if cachedValue, found := config.GetFromCache(cacheKey); found {
-		// 缓存值格式: "idCode|buildType|innerVer"
-		parts := strings.Split(cachedValue, "|")
-		if len(parts) == 3 {
-			logger.Logger.Debugf("[%s] Using cached database version info", c.dataSource)
-			ch <- prometheus.MustNewConstMetric(
-				c.versionInfoDesc,
-				prometheus.GaugeValue,
-				1,
-				parts[0], // idCode
-				parts[1], // buildType
-				parts[2], // innerVer
-			)
-			return
-		}
+	if cached, found := cache.Get[dbVersionLabels](cache.Default, cacheKey); found {
+		logger.ForDatasource(c.dataSource).Debugf("[%s] Using cached database version info", c.dataSource)
+		ch <- prometheus.MustNewConstMetric(
+			c.versionInfoDesc,
+			prometheus.GaugeValue,
+			1,
+			cached.idCode,
+			cached.buildType,
+			cached.innerVer,
+		)
+		return
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(config.Global.GetQueryTimeout())*time.Second)
@@ -92,9 +96,8 @@ func (c *DbVersionCollector) Collect(ch chan<- prometheus.Metric) {
 		}
 
 		// 缓存V1版本信息
-		cacheValue := fmt.Sprintf("%s||", dbVersion)
-		config.SetCache(cacheKey, cacheValue, time.Minute*time.Duration(config.Global.GetBigKeyDataCacheTime()))
-		logger.Logger.Debugf("[%s] Database version info (V1) cached", c.dataSource)
+		cache.Default.Set(cacheKey, cache.KindBigKey, dbVersionLabels{idCode: dbVersion})
+		logger.ForDatasource(c.dataSource).Debugf("[%s] Database version info (V1) cached", c.dataSource)
 
 		// 使用V1版本时，新增标签填充空值
 		ch <- prometheus.MustNewConstMetric(
@@ -109,12 +112,12 @@ func (c *DbVersionCollector) Collect(ch chan<- prometheus.Metric) {
 	}
 
 	// 缓存V2版本信息
-	cacheValue := fmt.Sprintf("%s|%s|%s",
-		utils.NullStringToString(versionInfo.idCode),
-		utils.NullStringToString(versionInfo.buildType),
-		utils.NullStringToString(versionInfo.innerVer))
-	config.SetCache(cacheKey, cacheValue, time.Minute*time.Duration(config.Global.GetBigKeyDataCacheTime()))
-	logger.Logger.Debugf("[%s] Database version info (V2) cached", c.dataSource)
+	cache.Default.Set(cacheKey, cache.KindBigKey, dbVersionLabels{
+		idCode:    utils.NullStringToString(versionInfo.idCode),
+		buildType: utils.NullStringToString(versionInfo.buildType),
+		innerVer:  utils.NullStringToString(versionInfo.innerVer),
+	})
+	logger.ForDatasource(c.dataSource).Debugf("[%s] Database version info (V2) cached", c.dataSource)
 
 	// 发送V2版本信息到Prometheus
 	ch <- prometheus.MustNewConstMetric(
@@ -137,7 +140,7 @@ func (c *DbVersionCollector) getDbVersionV2(ctx context.Context, db *sql.DB) (*D
 		return nil, err
 	}
 
-	logger.Logger.Debugf("[%s] Check Database version Info V2 Success, version info: %+v", c.dataSource, versionInfo)
+	logger.ForDatasource(c.dataSource).Debugf("[%s] Check Database version Info V2 Success, version info: %+v", c.dataSource, versionInfo)
 	return &versionInfo, nil
 }
 
@@ -176,6 +179,6 @@ func (c *DbVersionCollector) getDbVersionV1(ctx context.Context, db *sql.DB) (st
 		dbVersion = strings.TrimSpace(dbVersion)
 	}
 
-	logger.Logger.Debugf("[%s] Check Database version Info V1 Success, version value %s", c.dataSource, dbVersion)
+	logger.ForDatasource(c.dataSource).Debugf("[%s] Check Database version Info V1 Success, version value %s", c.dataSource, dbVersion)
 	return dbVersion, nil
 }
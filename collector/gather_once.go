@@ -0,0 +1,61 @@
+package collector
+
+import (
+	"dameng_exporter/db"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// ExporterCollectorName 系统级指标（构建信息、数据源状态）在按采集器分组的结果中使用的名称
+const ExporterCollectorName = "exporter"
+
+// CollectorFamilies 单个采集器一次采集得到的指标
+type CollectorFamilies struct {
+	Collector string              // 采集器名称，与 collect[] 参数一致
+	Families  []*dto.MetricFamily // 采集到的指标，包含所有数据源
+	Err       error               // 采集过程中的错误（如指标重复或不合法），成功时为 nil
+}
+
+// GatherByCollector 按采集器分别执行一次完整采集，用于一次性采集报告。
+// 采集器与 RegisterMultiSourceCollectors 注册的一致，需在其之后调用以复用已加载的自定义指标配置；
+// 结果按 CollectorNames 排序，系统级指标排在最前
+func GatherByCollector(poolManager *db.DBPoolManager) []CollectorFamilies {
+	type group struct {
+		name       string
+		collectors []prometheus.Collector
+	}
+	groups := []group{{ExporterCollectorName, []prometheus.Collector{NewBuildInfoCollector(), NewDatasourceHealthCollector(poolManager)}}}
+
+	registerMux.Lock()
+	adapter := customAdapter
+	registerMux.Unlock()
+	for _, name := range CollectorNames() {
+		collectors := dataCollectors(poolManager, &ScrapeFilter{Collectors: map[string]bool{name: true}})
+		if name == customCollectorName && adapter != nil {
+			collectors = append(collectors, adapter)
+		}
+		if len(collectors) > 0 {
+			groups = append(groups, group{name, collectors})
+		}
+	}
+
+	// 与抓取时相同，各采集器并发执行
+	results := make([]CollectorFamilies, len(groups))
+	var wg sync.WaitGroup
+	for i, g := range groups {
+		wg.Add(1)
+		go func(i int, g group) {
+			defer wg.Done()
+			reg := prometheus.NewRegistry()
+			for _, c := range g.collectors {
+				reg.MustRegister(c)
+			}
+			families, err := reg.Gather()
+			results[i] = CollectorFamilies{Collector: g.name, Families: families, Err: err}
+		}(i, g)
+	}
+	wg.Wait()
+	return results
+}
@@ -21,8 +21,8 @@ func NewLabelInjectorFromPool(pool *db.DataSourcePool) *LabelInjector {
 	}
 
 	// 确保包含数据源名称，优先使用注入的 datasource 标签
-	if dsLabel, ok := labels["datasource"]; !ok || dsLabel == "" {
-		labels["datasource"] = pool.Name
+	if dsLabel, ok := labels[db.DatasourceLabel]; !ok || dsLabel == "" {
+		labels[db.DatasourceLabel] = pool.Name
 	}
 
 	return &LabelInjector{
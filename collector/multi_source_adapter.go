@@ -33,7 +33,9 @@ func SetDataSourceIfSupported(collector MetricCollector, dataSource string) {
 type MultiSourceAdapter struct {
 	poolManager     *db.DBPoolManager
 	createCollector func(*sql.DB) MetricCollector
-	collectorName   string // 采集器名称（延迟初始化）
+	collectorName   string        // 采集器名称（延迟初始化）
+	name            string        // 采集器在 collect[] 参数与运行状态中的名称
+	filter          *ScrapeFilter // 单次抓取的数据源过滤条件，nil 表示不过滤
 	mu              sync.Mutex
 	nameOnce        sync.Once // 确保名称只获取一次
 }
@@ -83,8 +85,8 @@ func (a *MultiSourceAdapter) Describe(ch chan<- *prometheus.Desc) {
 
 // Collect 实现Prometheus Collector接口
 func (a *MultiSourceAdapter) Collect(ch chan<- prometheus.Metric) {
-	// 获取所有健康的连接池
-	pools := a.poolManager.GetHealthyPools()
+	// 获取所有健康的连接池，按单次抓取的过滤条件筛选
+	pools := a.filter.filterPools(a.poolManager.GetHealthyPools())
 
 	// 为每个数据源采集指标
 	var wg sync.WaitGroup
@@ -110,8 +112,9 @@ func (a *MultiSourceAdapter) Collect(ch chan<- prometheus.Metric) {
 
 			// 快速检查数据源是否已降级，避免无谓查询
 			if err := utils.CheckDBConnectionWithSource(p.DB, p.Name); err != nil {
-				logger.Logger.Warnf("[%s] %s skipped (datasource unavailable): %v",
+				logger.ForDatasource(p.Name).Warnf("[%s] %s skipped (datasource unavailable): %v",
 					p.Name, a.collectorName, err)
+				a.recordRun(p.Name, startTime, 0, fmt.Sprintf("skipped (datasource unavailable): %v", err))
 				return
 			}
 
@@ -154,11 +157,13 @@ func (a *MultiSourceAdapter) collectInBlockingMode(ch chan<- prometheus.Metric,
 
 	// 采集goroutine
 	collectDone := make(chan struct{})
+	var panicErr string
 	go func() {
 		defer func() {
 			if r := recover(); r != nil {
-				logger.Logger.Errorf("[%s] Collector panic recovered: %v\nStack trace:\n%s",
+				logger.ForDatasource(p.Name).Errorf("[%s] Collector panic recovered: %v\nStack trace:\n%s",
 					p.Name, r, debug.Stack())
+				panicErr = fmt.Sprintf("panic: %v", r)
 			}
 			close(safeChan)
 			close(collectDone)
@@ -190,12 +195,13 @@ func (a *MultiSourceAdapter) collectInBlockingMode(ch chan<- prometheus.Metric,
 	finalCount := atomic.LoadInt32(&metricCount)
 
 	if slowCollector {
-		logger.Logger.Warnf("[%s] %s completed (slow, blocking mode) | Cost: %vms | Metrics: %d",
+		logger.ForDatasource(p.Name).Warnf("[%s] %s completed (slow, blocking mode) | Cost: %vms | Metrics: %d",
 			p.Name, a.collectorName, collectorDuration.Milliseconds(), finalCount)
 	} else {
-		logger.Logger.Infof("[%s] %s completed (blocking mode) | Cost: %vms | Metrics: %d",
+		logger.ForDatasource(p.Name).Infof("[%s] %s completed (blocking mode) | Cost: %vms | Metrics: %d",
 			p.Name, a.collectorName, collectorDuration.Milliseconds(), finalCount)
 	}
+	a.recordRun(p.Name, startTime, int(finalCount), panicErr)
 }
 
 // collectInFastMode 快速模式采集 - 超时返回部分数据
@@ -237,11 +243,13 @@ func (a *MultiSourceAdapter) collectInFastMode(ch chan<- prometheus.Metric, p *d
 
 	// 采集goroutine
 	collectDone := make(chan struct{})
+	var panicErr string
 	go func() {
 		defer func() {
 			if r := recover(); r != nil {
-				logger.Logger.Errorf("[%s] Collector panic recovered: %v\nStack trace:\n%s",
+				logger.ForDatasource(p.Name).Errorf("[%s] Collector panic recovered: %v\nStack trace:\n%s",
 					p.Name, r, debug.Stack())
+				panicErr = fmt.Sprintf("panic: %v", r)
 			}
 			close(safeChan)
 			close(collectDone)
@@ -271,12 +279,41 @@ func (a *MultiSourceAdapter) collectInFastMode(ch chan<- prometheus.Metric, p *d
 	finalCount := atomic.LoadInt32(&metricCount)
 
 	if timedOut {
-		logger.Logger.Warnf("[%s] %s TIMEOUT (fast mode) | Cost: %vms | Timeout: %v | Metrics: %d (partial)",
+		logger.ForDatasource(p.Name).Warnf("[%s] %s TIMEOUT (fast mode) | Cost: %vms | Timeout: %v | Metrics: %d (partial)",
 			p.Name, a.collectorName, collectorDuration.Milliseconds(), timeout, finalCount)
+		a.recordRun(p.Name, startTime, int(finalCount), fmt.Sprintf("timeout after %v (partial)", timeout))
 	} else {
-		logger.Logger.Infof("[%s] %s completed (fast mode) | Cost: %vms | Metrics: %d",
+		logger.ForDatasource(p.Name).Infof("[%s] %s completed (fast mode) | Cost: %vms | Metrics: %d",
 			p.Name, a.collectorName, collectorDuration.Milliseconds(), finalCount)
+		// 未超时时采集goroutine已结束，可以安全读取 panicErr
+		a.recordRun(p.Name, startTime, int(finalCount), panicErr)
+	}
+}
+
+// recordRun 记录采集器在数据源上的运行结果，未命名的采集器使用类型名称
+func (a *MultiSourceAdapter) recordRun(dataSource string, startTime time.Time, metrics int, errMsg string) {
+	name := a.name
+	if name == "" {
+		name = a.collectorName
+	}
+	recordRun(CollectorRun{
+		Collector:  name,
+		DataSource: dataSource,
+		LastRun:    startTime,
+		Duration:   time.Since(startTime),
+		Metrics:    metrics,
+		Error:      errMsg,
+	})
+}
+
+// newFilteredAdapter 创建只采集过滤范围内数据源的命名适配器
+func newFilteredAdapter(poolManager *db.DBPoolManager, name string, createFunc func(*sql.DB) MetricCollector, filter *ScrapeFilter) MetricCollector {
+	adapter := AdaptCollector(poolManager, createFunc)
+	if multi, ok := adapter.(*MultiSourceAdapter); ok {
+		multi.name = name
+		multi.filter = filter
 	}
+	return adapter
 }
 
 // AdaptCollector 适配单个采集器到多数据源
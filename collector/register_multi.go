@@ -1,6 +1,7 @@
 package collector
 
 import (
+	"dameng_exporter/cache"
 	"dameng_exporter/config"
 	"dameng_exporter/db"
 	"dameng_exporter/logger"
@@ -11,95 +12,146 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 )
 
-// RegisterMultiSourceCollectors 注册多数据源收集器
-func RegisterMultiSourceCollectors(reg *prometheus.Registry, poolManager *db.DBPoolManager) {
-	registerMux.Lock()
-	defer registerMux.Unlock()
-
-	logger.Logger.Debugf("Registering multi-source collectors, OS: %v", utils.GetOS())
-
-	// 清空现有收集器
-	collectors = []prometheus.Collector{}
+// 不在 databaseCollectors 中的采集器名称，用于 collect[] 参数
+const (
+	hostProcessCollectorName = "host_process"
+	customCollectorName      = "custom"
+)
 
-	// 系统级收集器（不依赖数据库）
-	collectors = append(collectors, NewBuildInfoCollector())
-	collectors = append(collectors, NewDatasourceHealthCollector(poolManager))
+// namedCollector 带名称的数据库采集器，名称用于按 collect[] 参数过滤
+type namedCollector struct {
+	name   string
+	create func(*sql.DB) MetricCollector
+}
 
-	// 如果poolManager为nil，报错
-	if poolManager == nil {
-		logger.Logger.Error("PoolManager is nil, cannot register collectors")
-		return
-	}
+// databaseCollectors 数据库指标采集器（按注册顺序）
+var databaseCollectors = []namedCollector{
+	{"tablespace_datafile", NewTableSpaceDateFileInfoCollector},
+	{"tablespace", NewTableSpaceInfoCollector},
+	{"instance", NewDBInstanceRunningInfoCollector},
+	{"memory_pool", NewDbMemoryPoolInfoCollector},
+	{"sessions", NewDBSessionsStatusCollector},
+	{"jobs", NewDbJobRunningInfoCollector},
+	{"slow_sql", NewSlowSessionInfoCollector},
+	{"monitor", NewMonitorInfoCollector},
+	{"sql_exec_type", NewDbSqlExecTypeCollector},
+	{"parameter", NewIniParameterCollector},
+	{"users", NewDbUserCollector},
+	{"license", NewDbLicenseCollector},
+	{"version", NewDbVersionCollector},
+	{"arch_status", NewDbArchStatusCollector},
+	{"arch_switch", NewDbArchSwitchCollector},
+	{"arch_send", NewDbArchSendCollector},
+	{"arch_queue", NewDbArchQueueCollector},
+	{"log_history", NewDbLogHistoryCollector},
+	{"rlog_file", NewDbRlogFileCollector},
+	{"rapply_sys", NewDbRapplySysCollector},
+	{"rapply_time_diff", NewDbRapplyTimeDiffCollector},
+	{"purge", NewPurgeCollector},
+	{"ckpt", NewCkptCollector},
+	{"redo_lsn", NewDbRedoLogLsnCollector},
+	{"buffer_pool", NewDbBufferPoolCollector},
+	{"dual", NewDbDualCollector},
+	{"dw_watcher", NewDbDwWatcherInfoCollector},
+	{"system", NewDBSystemInfoCollector},
+	{"system_event_wait", NewDbSystemEventWaitCollector},
+	{"instance_log_error", NewDbInstanceLogErrorCollector},
+	{"dict_cache", NewDbDictCacheCollector},
+}
 
-	// 检查是否有任何数据源需要各类指标
-	needHostMetrics := false
-	needDatabaseMetrics := false
-	needDmhsMetrics := false
-	needCustomMetrics := false
+// metricGroups 各类指标是否有任何启用的数据源需要
+type metricGroups struct {
+	host     bool
+	database bool
+	dmhs     bool
+	custom   bool
+}
 
-	for _, ds := range config.GlobalMultiConfig.DataSources {
+// neededMetricGroups 检查是否有任何数据源需要各类指标
+func neededMetricGroups() metricGroups {
+	var groups metricGroups
+	for _, ds := range config.GlobalMultiConfig.DataSourceList() {
 		if ds.Enabled {
 			if ds.RegisterHostMetrics {
-				needHostMetrics = true
+				groups.host = true
 			}
 			if ds.RegisterDatabaseMetrics {
-				needDatabaseMetrics = true
+				groups.database = true
 			}
 			if ds.RegisterDmhsMetrics {
-				needDmhsMetrics = true
+				groups.dmhs = true
 			}
 			if ds.RegisterCustomMetrics {
-				needCustomMetrics = true
 				// 不再需要收集 customMetricsFile，每个数据源独立处理
+				groups.custom = true
 			}
 		}
 	}
+	return groups
+}
+
+// buildCollectors 构建系统级收集器与数据源收集器，filter 为 nil 表示不过滤
+func buildCollectors(poolManager *db.DBPoolManager, filter *ScrapeFilter) []prometheus.Collector {
+	// 系统级收集器（不依赖数据库）
+	result := []prometheus.Collector{
+		NewBuildInfoCollector(),
+		newFilteredHealthCollector(poolManager, filter),
+	}
+	// 缓存为进程级指标，只在不带过滤条件的抓取中输出
+	if filter.IsEmpty() {
+		result = append(result, cache.Default)
+	}
+
+	if poolManager == nil {
+		return result
+	}
+	return append(result, dataCollectors(poolManager, filter)...)
+}
+
+// dataCollectors 构建过滤范围内需要查询数据源的收集器（不含自定义指标），filter 为 nil 表示不过滤
+func dataCollectors(poolManager *db.DBPoolManager, filter *ScrapeFilter) []prometheus.Collector {
+	var result []prometheus.Collector
+	groups := neededMetricGroups()
 
 	// 主机指标（如果任何数据源需要，且在Linux系统上）
-	if needHostMetrics && strings.Compare(utils.GetOS(), utils.OS_LINUX) == 0 {
-		collectors = append(collectors, AdaptCollector(poolManager, func(db *sql.DB) MetricCollector {
+	if groups.host && strings.Compare(utils.GetOS(), utils.OS_LINUX) == 0 && filter.includesCollector(hostProcessCollectorName) {
+		result = append(result, newFilteredAdapter(poolManager, hostProcessCollectorName, func(db *sql.DB) MetricCollector {
 			return NewDmapProcessCollector(db)
-		}))
+		}, filter))
 	}
 
-	// 数据库指标（如果任何数据源需要）
-	if needDatabaseMetrics {
-		// 使用适配器包装所有采集器
-		collectors = append(collectors, AdaptCollector(poolManager, NewTableSpaceDateFileInfoCollector))
-		collectors = append(collectors, AdaptCollector(poolManager, NewTableSpaceInfoCollector))
-		collectors = append(collectors, AdaptCollector(poolManager, NewDBInstanceRunningInfoCollector))
-		collectors = append(collectors, AdaptCollector(poolManager, NewDbMemoryPoolInfoCollector))
-		collectors = append(collectors, AdaptCollector(poolManager, NewDBSessionsStatusCollector))
-		collectors = append(collectors, AdaptCollector(poolManager, NewDbJobRunningInfoCollector))
-		collectors = append(collectors, AdaptCollector(poolManager, NewSlowSessionInfoCollector))
-		collectors = append(collectors, AdaptCollector(poolManager, NewMonitorInfoCollector))
-		collectors = append(collectors, AdaptCollector(poolManager, NewDbSqlExecTypeCollector))
-		collectors = append(collectors, AdaptCollector(poolManager, NewIniParameterCollector))
-		collectors = append(collectors, AdaptCollector(poolManager, NewDbUserCollector))
-		collectors = append(collectors, AdaptCollector(poolManager, NewDbLicenseCollector))
-		collectors = append(collectors, AdaptCollector(poolManager, NewDbVersionCollector))
-		collectors = append(collectors, AdaptCollector(poolManager, NewDbArchStatusCollector))
-		collectors = append(collectors, AdaptCollector(poolManager, NewDbArchSwitchCollector))
-		collectors = append(collectors, AdaptCollector(poolManager, NewDbArchSendCollector))
-		collectors = append(collectors, AdaptCollector(poolManager, NewDbArchQueueCollector))
-		collectors = append(collectors, AdaptCollector(poolManager, NewDbLogHistoryCollector))
-		collectors = append(collectors, AdaptCollector(poolManager, NewDbRlogFileCollector))
-		collectors = append(collectors, AdaptCollector(poolManager, NewDbRapplySysCollector))
-		collectors = append(collectors, AdaptCollector(poolManager, NewDbRapplyTimeDiffCollector))
-		collectors = append(collectors, AdaptCollector(poolManager, NewPurgeCollector))
-		collectors = append(collectors, AdaptCollector(poolManager, NewCkptCollector))
-		collectors = append(collectors, AdaptCollector(poolManager, NewDbRedoLogLsnCollector))
-		collectors = append(collectors, AdaptCollector(poolManager, NewDbBufferPoolCollector))
-		collectors = append(collectors, AdaptCollector(poolManager, NewDbDualCollector))
-		collectors = append(collectors, AdaptCollector(poolManager, NewDbDwWatcherInfoCollector))
-		collectors = append(collectors, AdaptCollector(poolManager, NewDBSystemInfoCollector))
-		collectors = append(collectors, AdaptCollector(poolManager, NewDbSystemEventWaitCollector))
-		collectors = append(collectors, AdaptCollector(poolManager, NewDbInstanceLogErrorCollector))
-		collectors = append(collectors, AdaptCollector(poolManager, NewDbDictCacheCollector))
+	// 数据库指标（如果任何数据源需要），使用适配器包装所有采集器
+	if groups.database {
+		for _, c := range databaseCollectors {
+			if filter.includesCollector(c.name) {
+				result = append(result, newFilteredAdapter(poolManager, c.name, c.create, filter))
+			}
+		}
 	}
 
+	return result
+}
+
+// RegisterMultiSourceCollectors 注册多数据源收集器
+func RegisterMultiSourceCollectors(reg *prometheus.Registry, poolManager *db.DBPoolManager) {
+	registerMux.Lock()
+	defer registerMux.Unlock()
+
+	logger.Logger.Debugf("Registering multi-source collectors, OS: %v", utils.GetOS())
+
+	collectors = buildCollectors(poolManager, nil)
+
+	// 如果poolManager为nil，报错
+	if poolManager == nil {
+		logger.Logger.Error("PoolManager is nil, cannot register collectors")
+		return
+	}
+	poolManager.OnDatasourceRemoved(forgetDataSourceState)
+
+	groups := neededMetricGroups()
+
 	// DMHS指标（如果任何数据源需要）
-	if needDmhsMetrics {
+	if groups.dmhs {
 		// TODO: 添加DMHS相关采集器
 		logger.Logger.Debug("DMHS metrics requested but not yet implemented")
 	}
@@ -113,9 +165,9 @@ func RegisterMultiSourceCollectors(reg *prometheus.Registry, poolManager *db.DBP
 
 	// 自定义指标处理 - 使用新的多数据源独立配置方式
 	// 每个数据源可以有自己独立的自定义指标配置文件
-	if needCustomMetrics {
+	if groups.custom {
 		// 使用专门的自定义指标适配器，支持每个数据源独立配置
-		RegisterCustomMetricsForMultiSource(reg, poolManager)
+		customAdapter = RegisterCustomMetricsForMultiSource(reg, poolManager)
 	}
 
 	logger.Logger.Infof("Registered %d collectors in multi-source mode", len(collectors))
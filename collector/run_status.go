@@ -0,0 +1,66 @@
+package collector
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// CollectorRun 采集器在单个数据源上最近一次运行的结果
+type CollectorRun struct {
+	Collector  string        // 采集器名称，与 collect[] 参数一致
+	DataSource string        // 数据源名称
+	LastRun    time.Time     // 最近一次开始运行的时间
+	Duration   time.Duration // 耗时
+	Metrics    int           // 输出的指标数量
+	Error      string        // 跳过、超时或 panic 的原因，成功时为空
+}
+
+// CustomMetricsFile 自定义指标配置文件的加载结果
+type CustomMetricsFile struct {
+	DataSource string // 数据源名称
+	File       string // 配置文件路径
+	Metrics    int    // 加载的指标数量
+	Error      string // 加载失败的原因，成功时为空
+}
+
+// runStatus 记录各采集器在各数据源上最近一次运行的结果
+var runStatus = struct {
+	mu   sync.RWMutex
+	runs map[string]CollectorRun
+}{runs: make(map[string]CollectorRun)}
+
+// customFiles 最近一次注册时自定义指标配置文件的加载结果，由 registerMux 保护
+var customFiles []CustomMetricsFile
+
+// recordRun 记录一次采集结果，覆盖同一采集器在同一数据源上的上次结果
+func recordRun(run CollectorRun) {
+	runStatus.mu.Lock()
+	runStatus.runs[run.Collector+"|"+run.DataSource] = run
+	runStatus.mu.Unlock()
+}
+
+// CollectorRuns 返回各采集器最近一次运行的结果，按数据源与采集器名称排序
+func CollectorRuns() []CollectorRun {
+	runStatus.mu.RLock()
+	runs := make([]CollectorRun, 0, len(runStatus.runs))
+	for _, run := range runStatus.runs {
+		runs = append(runs, run)
+	}
+	runStatus.mu.RUnlock()
+
+	sort.Slice(runs, func(i, j int) bool {
+		if runs[i].DataSource != runs[j].DataSource {
+			return runs[i].DataSource < runs[j].DataSource
+		}
+		return runs[i].Collector < runs[j].Collector
+	})
+	return runs
+}
+
+// CustomMetricsFiles 返回自定义指标配置文件的加载结果
+func CustomMetricsFiles() []CustomMetricsFile {
+	registerMux.Lock()
+	defer registerMux.Unlock()
+	return append([]CustomMetricsFile(nil), customFiles...)
+}
@@ -0,0 +1,109 @@
+package collector
+
+import (
+	"dameng_exporter/db"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// ScrapeFilter 单次抓取的数据源与采集器过滤条件，nil 或空集合表示不过滤
+type ScrapeFilter struct {
+	DataSources map[string]bool // 仅采集这些数据源
+	Collectors  map[string]bool // 仅运行这些采集器
+}
+
+// NewScrapeFilter 根据请求参数创建过滤条件，采集器名称不合法时返回错误
+func NewScrapeFilter(dataSources, collectorNames []string) (*ScrapeFilter, error) {
+	filter := &ScrapeFilter{}
+
+	for _, name := range dataSources {
+		if name = strings.TrimSpace(name); name == "" {
+			continue
+		}
+		if filter.DataSources == nil {
+			filter.DataSources = make(map[string]bool)
+		}
+		filter.DataSources[name] = true
+	}
+
+	known := make(map[string]bool)
+	for _, name := range CollectorNames() {
+		known[name] = true
+	}
+	for _, name := range collectorNames {
+		if name = strings.TrimSpace(name); name == "" {
+			continue
+		}
+		if !known[name] {
+			return nil, fmt.Errorf("unknown collector %q, available: %s", name, strings.Join(CollectorNames(), ", "))
+		}
+		if filter.Collectors == nil {
+			filter.Collectors = make(map[string]bool)
+		}
+		filter.Collectors[name] = true
+	}
+
+	return filter, nil
+}
+
+// IsEmpty 判断是否未设置任何过滤条件
+func (f *ScrapeFilter) IsEmpty() bool {
+	return f == nil || (len(f.DataSources) == 0 && len(f.Collectors) == 0)
+}
+
+// includesDataSource 判断数据源是否在过滤范围内
+func (f *ScrapeFilter) includesDataSource(name string) bool {
+	return f == nil || len(f.DataSources) == 0 || f.DataSources[name]
+}
+
+// includesCollector 判断采集器是否在过滤范围内
+func (f *ScrapeFilter) includesCollector(name string) bool {
+	return f == nil || len(f.Collectors) == 0 || f.Collectors[name]
+}
+
+// filterPools 按过滤条件筛选连接池
+func (f *ScrapeFilter) filterPools(pools []*db.DataSourcePool) []*db.DataSourcePool {
+	if f == nil || len(f.DataSources) == 0 {
+		return pools
+	}
+	filtered := make([]*db.DataSourcePool, 0, len(f.DataSources))
+	for _, pool := range pools {
+		if f.DataSources[pool.Name] {
+			filtered = append(filtered, pool)
+		}
+	}
+	return filtered
+}
+
+// CollectorNames 返回可用于 collect[] 参数的采集器名称（按名称排序）
+func CollectorNames() []string {
+	names := make([]string, 0, len(databaseCollectors)+2)
+	for _, c := range databaseCollectors {
+		names = append(names, c.name)
+	}
+	names = append(names, hostProcessCollectorName, customCollectorName)
+	sort.Strings(names)
+	return names
+}
+
+// NewFilteredRegistry 为带过滤条件的单次抓取创建独立的注册器，
+// 仅包含系统级指标与被选中的采集器，且只对选中的数据源执行查询
+func NewFilteredRegistry(poolManager *db.DBPoolManager, filter *ScrapeFilter) *prometheus.Registry {
+	reg := prometheus.NewRegistry()
+	for _, c := range buildCollectors(poolManager, filter) {
+		reg.MustRegister(c)
+	}
+
+	if filter.includesCollector(customCollectorName) {
+		registerMux.Lock()
+		adapter := customAdapter
+		registerMux.Unlock()
+		if adapter != nil {
+			reg.MustRegister(&filteredCustomMetricsAdapter{adapter: adapter, filter: filter})
+		}
+	}
+	return reg
+}
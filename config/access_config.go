@@ -0,0 +1,60 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// AccessRuleConfig 按路径限制客户端地址的访问规则
+type AccessRuleConfig struct {
+	Name  string   `toml:"name"`  // 名称，用于日志
+	Paths []string `toml:"paths"` // 适用的路径前缀，为空表示全部路径
+	Allow []string `toml:"allow"` // 允许访问的地址段（CIDR 或单个 IP），为空表示不限制
+	Deny  []string `toml:"deny"`  // 拒绝访问的地址段，优先于 allow
+}
+
+// Validate 验证访问规则
+func (a *AccessRuleConfig) Validate() error {
+	for _, p := range a.Paths {
+		if !strings.HasPrefix(p, "/") {
+			return fmt.Errorf("accessRule %s: 路径必须以 / 开头: %s (paths)", a.Name, p)
+		}
+	}
+	if len(a.Allow) == 0 && len(a.Deny) == 0 {
+		return fmt.Errorf("accessRule %s: allow 与 deny 至少配置一项", a.Name)
+	}
+	if _, err := ParseCIDRs(a.Allow); err != nil {
+		return fmt.Errorf("accessRule %s: %w (allow)", a.Name, err)
+	}
+	if _, err := ParseCIDRs(a.Deny); err != nil {
+		return fmt.Errorf("accessRule %s: %w (deny)", a.Name, err)
+	}
+	return nil
+}
+
+// ParseCIDRs 解析地址段列表，单个 IP 视为只包含该地址的地址段
+func ParseCIDRs(list []string) ([]*net.IPNet, error) {
+	nets := make([]*net.IPNet, 0, len(list))
+	for _, item := range list {
+		item = strings.TrimSpace(item)
+		if !strings.Contains(item, "/") {
+			ip := net.ParseIP(item)
+			if ip == nil {
+				return nil, fmt.Errorf("无效的地址 %s", item)
+			}
+			bits := 32
+			if ip.To4() == nil {
+				bits = 128
+			}
+			nets = append(nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, ipNet, err := net.ParseCIDR(item)
+		if err != nil {
+			return nil, fmt.Errorf("无效的地址段 %s", item)
+		}
+		nets = append(nets, ipNet)
+	}
+	return nets, nil
+}
@@ -0,0 +1,167 @@
+package config
+
+import (
+	"dameng_exporter/cache"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// alarmStateFileVersion 状态文件格式版本
+const alarmStateFileVersion = 1
+
+// alarmStateEntry 状态文件中的单个告警键
+type alarmStateEntry struct {
+	Value     string    `json:"value"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// alarmStateFile 状态文件结构，按数据源划分命名空间
+type alarmStateFile struct {
+	Version     int                                   `json:"version"`
+	DataSources map[string]map[string]alarmStateEntry `json:"datasources"`
+}
+
+// alarmStateStore 告警类缓存键的持久化存储
+type alarmStateStore struct {
+	mu      sync.Mutex
+	path    string                                // 状态文件路径，为空表示未启用持久化
+	entries map[string]map[string]alarmStateEntry // 数据源 -> 键 -> 值
+}
+
+var alarmStore = &alarmStateStore{
+	entries: make(map[string]map[string]alarmStateEntry),
+}
+
+// alarmStateCollector 告警状态条目在缓存中的归属名称
+const alarmStateCollector = "alarm_state"
+
+// alarmCacheKey 生成告警键在缓存中的命名空间键
+func alarmCacheKey(dataSource, key string) cache.Key {
+	return cache.Key{DataSource: dataSource, Collector: alarmStateCollector, Name: key}
+}
+
+// InitAlarmStateStore 启用告警状态持久化，加载状态文件中未过期的键到缓存，返回加载的键数量
+func InitAlarmStateStore(path string) (int, error) {
+	alarmStore.mu.Lock()
+	defer alarmStore.mu.Unlock()
+
+	alarmStore.path = path
+	if path == "" {
+		return 0, nil
+	}
+
+	content, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to read alarm state file: %w", err)
+	}
+
+	var state alarmStateFile
+	if err := json.Unmarshal(content, &state); err != nil {
+		return 0, fmt.Errorf("failed to parse alarm state file: %w", err)
+	}
+
+	now := time.Now()
+	loaded := 0
+	for dataSource, keys := range state.DataSources {
+		for key, entry := range keys {
+			ttl := entry.ExpiresAt.Sub(now)
+			if ttl <= 0 {
+				continue
+			}
+			cache.Default.SetWithTTL(alarmCacheKey(dataSource, key), cache.KindAlarm, entry.Value, ttl)
+			if alarmStore.entries[dataSource] == nil {
+				alarmStore.entries[dataSource] = make(map[string]alarmStateEntry)
+			}
+			alarmStore.entries[dataSource][key] = entry
+			loaded++
+		}
+	}
+	return loaded, nil
+}
+
+// GetAlarmState 获取指定数据源的告警键
+func GetAlarmState(dataSource, key string) (string, bool) {
+	return cache.Get[string](cache.Default, alarmCacheKey(dataSource, key))
+}
+
+// AlarmStateExists 判断指定数据源的告警键是否存在
+func AlarmStateExists(dataSource, key string) bool {
+	return cache.Default.Exists(alarmCacheKey(dataSource, key))
+}
+
+// SetAlarmState 写入指定数据源的告警键，启用持久化时同步写入状态文件
+func SetAlarmState(dataSource, key, value string, duration time.Duration) error {
+	cache.Default.SetWithTTL(alarmCacheKey(dataSource, key), cache.KindAlarm, value, duration)
+
+	alarmStore.mu.Lock()
+	defer alarmStore.mu.Unlock()
+	if alarmStore.entries[dataSource] == nil {
+		alarmStore.entries[dataSource] = make(map[string]alarmStateEntry)
+	}
+	alarmStore.entries[dataSource][key] = alarmStateEntry{Value: value, ExpiresAt: time.Now().Add(duration)}
+	return alarmStore.saveLocked()
+}
+
+// DeleteAlarmState 删除指定数据源的告警键，启用持久化时同步写入状态文件
+func DeleteAlarmState(dataSource, key string) error {
+	cache.Default.Delete(alarmCacheKey(dataSource, key))
+
+	alarmStore.mu.Lock()
+	defer alarmStore.mu.Unlock()
+	if keys, ok := alarmStore.entries[dataSource]; ok {
+		delete(keys, key)
+		if len(keys) == 0 {
+			delete(alarmStore.entries, dataSource)
+		}
+	}
+	return alarmStore.saveLocked()
+}
+
+// saveLocked 在持有锁的情况下清理过期键并原子写入状态文件
+func (s *alarmStateStore) saveLocked() error {
+	if s.path == "" {
+		return nil
+	}
+
+	now := time.Now()
+	for dataSource, keys := range s.entries {
+		for key, entry := range keys {
+			if !entry.ExpiresAt.After(now) {
+				delete(keys, key)
+			}
+		}
+		if len(keys) == 0 {
+			delete(s.entries, dataSource)
+		}
+	}
+
+	content, err := json.MarshalIndent(alarmStateFile{
+		Version:     alarmStateFileVersion,
+		DataSources: s.entries,
+	}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode alarm state: %w", err)
+	}
+
+	// 先写临时文件再重命名，避免进程异常退出导致状态文件损坏
+	if dir := filepath.Dir(s.path); dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create alarm state directory: %w", err)
+		}
+	}
+	tmpPath := s.path + ".tmp"
+	if err := os.WriteFile(tmpPath, content, 0600); err != nil {
+		return fmt.Errorf("failed to write alarm state file: %w", err)
+	}
+	if err := os.Rename(tmpPath, s.path); err != nil {
+		return fmt.Errorf("failed to replace alarm state file: %w", err)
+	}
+	return nil
+}
@@ -2,35 +2,43 @@ package config
 
 // CmdArgs 命令行参数结构体
 type CmdArgs struct {
-	ConfigFile              *string
-	ListenAddr              *string
-	MetricPath              *string
-	DbHost                  *string
-	DbUser                  *string
-	DbPwd                   *string
-	DbName                  *string
-	QueryTimeout            *int
-	MaxOpenConns            *int
-	ConnMaxLife             *int
-	CheckSlowSQL            *bool
-	SlowSqlTime             *int
-	SlowSqlMaxRows          *int
-	RegisterHostMetrics     *bool
-	RegisterDatabaseMetrics *bool
-	RegisterDmhsMetrics     *bool
-	RegisterCustomMetrics   *bool
-	BigKeyDataCacheTime     *int
-	AlarmKeyCacheTime       *int
-	LogMaxSize              *int
-	LogMaxBackups           *int
-	LogMaxAge               *int
-	LogLevel                *string
-	EncryptPwd              *string
-	EncodeConfigPwd         *bool
-	EnableBasicAuth         *bool
-	BasicAuthUsername       *string
-	BasicAuthPassword       *string
-	EncryptBasicAuthPwd     *string
+	ConfigFile                 *string
+	ListenAddr                 *string
+	MetricPath                 *string
+	EnableOpenMetrics          *bool
+	DbHost                     *string
+	DbUser                     *string
+	DbPwd                      *string
+	DbName                     *string
+	QueryTimeout               *int
+	MaxOpenConns               *int
+	ConnMaxLife                *int
+	CheckSlowSQL               *bool
+	SlowSqlTime                *int
+	SlowSqlMaxRows             *int
+	RegisterHostMetrics        *bool
+	RegisterDatabaseMetrics    *bool
+	RegisterDmhsMetrics        *bool
+	RegisterCustomMetrics      *bool
+	BigKeyDataCacheTime        *int
+	AlarmKeyCacheTime          *int
+	LogMaxSize                 *int
+	LogMaxBackups              *int
+	LogMaxAge                  *int
+	LogLevel                   *string
+	LogDir                     *string
+	LogFormat                  *string
+	LogOutput                  *string
+	LogDedupIntervalSeconds    *int
+	EncryptPwd                 *string
+	EncodeConfigPwd            *bool
+	EnableBasicAuth            *bool
+	BasicAuthUsername          *string
+	BasicAuthPassword          *string
+	BasicAuthUsersFile         *string
+	BasicAuthLegacyHashCompare *bool
+	BearerTokensFile           *string
+	EncryptBasicAuthPwd        *string
 
 	// 全局超时控制参数
 	GlobalTimeoutSeconds *int
@@ -40,6 +48,49 @@ type CmdArgs struct {
 
 	// 健康检查参数
 	EnableHealthPing *bool
+
+	// 抓取合并与并发控制参数
+	ScrapeCoalesceSeconds *int
+	MaxConcurrentScrapes  *int
+
+	// 优雅停机与探针参数
+	ShutdownDrainSeconds       *int
+	ShutdownTimeoutSeconds     *int
+	ReadyMinHealthyDatasources *int
+
+	// 告警状态持久化参数
+	AlarmStateFile *string
+
+	// 缓存参数
+	CacheMaxEntries *int
+
+	// 通知参数（Webhook 列表仅支持配置文件）
+	WebhookDedupSeconds *int
+	EventHistorySize    *int
+
+	// 推送参数（推送目标列表仅支持配置文件）
+	RemoteWriteIntervalSeconds *int
+	OTLPIntervalSeconds        *int
+
+	// Zabbix 推送参数
+	ZabbixServer                   *string
+	ZabbixIntervalSeconds          *int
+	ZabbixHostLabel                *string
+	ZabbixDiscoveryIntervalSeconds *int
+	ZabbixTimeoutSeconds           *int
+
+	// Pushgateway 一次性推送参数（指定地址时采集一次、推送后退出）
+	PushGatewayURL *string
+	PushGatewayJob *string
+
+	// 运维接口参数
+	EnableAdminAPI *bool
+
+	// 访问控制参数
+	TrustedProxies           *[]string
+	AuthFailureLimit         *int
+	AuthFailureWindowSeconds *int
+	AuthLockoutSeconds       *int
 }
 
 // MergeConfig 函数已移除，使用 MergeMultiSourceConfig 代替
@@ -3,7 +3,9 @@ package config
 import (
 	"encoding/base64"
 	"fmt"
+	"maps"
 	"os"
+	"slices"
 	"strings"
 
 	"github.com/BurntSushi/toml"
@@ -79,6 +81,47 @@ func CheckAndEncryptConfigPasswords(multiConfig *MultiSourceConfig, configFile s
 			needUpdate = true
 			fmt.Printf("Encrypted password for datasource: %s\n", rawConfig.DataSources[i].Name)
 		}
+		// 自动发现成员的连接密码同样需要加密
+		if rawConfig.DataSources[i].DiscoveryPwd != "" &&
+			!strings.HasPrefix(rawConfig.DataSources[i].DiscoveryPwd, "ENC(") {
+			rawConfig.DataSources[i].DiscoveryPwd = EncryptPassword(rawConfig.DataSources[i].DiscoveryPwd)
+			needUpdate = true
+			fmt.Printf("Encrypted discovery password for datasource: %s\n", rawConfig.DataSources[i].Name)
+		}
+	}
+	// remote_write 推送目标的凭据同样需要加密
+	for i := range rawConfig.RemoteWrite {
+		r := &rawConfig.RemoteWrite[i]
+		if r.Password != "" && !strings.HasPrefix(r.Password, "ENC(") {
+			r.Password = EncryptPassword(r.Password)
+			needUpdate = true
+			fmt.Printf("Encrypted password for remoteWrite: %s\n", r.Name)
+		}
+		if r.BearerToken != "" && !strings.HasPrefix(r.BearerToken, "ENC(") {
+			r.BearerToken = EncryptPassword(r.BearerToken)
+			needUpdate = true
+			fmt.Printf("Encrypted bearer token for remoteWrite: %s\n", r.Name)
+		}
+	}
+	// Webhook 加签密钥同样需要加密
+	for i := range rawConfig.Webhooks {
+		w := &rawConfig.Webhooks[i]
+		if w.Secret != "" && !strings.HasPrefix(w.Secret, "ENC(") {
+			w.Secret = EncryptPassword(w.Secret)
+			needUpdate = true
+			fmt.Printf("Encrypted secret for webhook: %s\n", w.Name)
+		}
+	}
+	// OTLP 请求头中通常包含认证信息，值同样需要加密
+	for i := range rawConfig.OTLP {
+		o := &rawConfig.OTLP[i]
+		for _, name := range slices.Sorted(maps.Keys(o.Headers)) {
+			if value := o.Headers[name]; value != "" && !strings.HasPrefix(value, "ENC(") {
+				o.Headers[name] = EncryptPassword(value)
+				needUpdate = true
+				fmt.Printf("Encrypted header %s for otlp: %s\n", name, o.Name)
+			}
+		}
 	}
 
 	// 如果有密码被加密，更新配置文件
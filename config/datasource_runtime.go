@@ -0,0 +1,212 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/BurntSushi/toml"
+)
+
+// dataSourcesMu 保护运行时对 DataSources 的修改。
+// 修改采用写时复制：每次替换为新切片，已取得的数据源指针及其内容保持不变，读取方无需长时间持锁。
+var dataSourcesMu sync.RWMutex
+
+// persistMu 串行化运行时对配置文件的写回
+var persistMu sync.Mutex
+
+// DataSourceList 返回当前数据源列表，调用方只能读取，不能修改切片元素
+func (msc *MultiSourceConfig) DataSourceList() []DataSourceConfig {
+	dataSourcesMu.RLock()
+	defer dataSourcesMu.RUnlock()
+	return msc.DataSources
+}
+
+// AddDataSource 运行时新增数据源，名称与地址不能与现有数据源重复，返回新数据源在列表中的指针
+func (msc *MultiSourceConfig) AddDataSource(ds DataSourceConfig) (*DataSourceConfig, error) {
+	ds.applyDefaults()
+	if err := ds.Validate(); err != nil {
+		return nil, err
+	}
+
+	dataSourcesMu.Lock()
+	defer dataSourcesMu.Unlock()
+
+	hostAddr := stripHostQuery(ds.DbHost)
+	for _, existing := range msc.DataSources {
+		if existing.Name == ds.Name {
+			return nil, fmt.Errorf("数据源名称重复: %s", ds.Name)
+		}
+		if existing.Enabled && ds.Enabled && stripHostQuery(existing.DbHost) == hostAddr {
+			return nil, fmt.Errorf("数据源地址重复: %s (被 '%s' 和 '%s' 同时使用)", hostAddr, existing.Name, ds.Name)
+		}
+	}
+
+	list := make([]DataSourceConfig, len(msc.DataSources), len(msc.DataSources)+1)
+	copy(list, msc.DataSources)
+	list = append(list, ds)
+	msc.DataSources = list
+	return &list[len(list)-1], nil
+}
+
+// RemoveDataSource 运行时删除数据源，返回被删除的数据源配置
+func (msc *MultiSourceConfig) RemoveDataSource(name string) (*DataSourceConfig, error) {
+	dataSourcesMu.Lock()
+	defer dataSourcesMu.Unlock()
+
+	for i := range msc.DataSources {
+		if msc.DataSources[i].Name != name {
+			continue
+		}
+		removed := msc.DataSources[i]
+		list := make([]DataSourceConfig, 0, len(msc.DataSources)-1)
+		list = append(list, msc.DataSources[:i]...)
+		list = append(list, msc.DataSources[i+1:]...)
+		msc.DataSources = list
+		return &removed, nil
+	}
+	return nil, fmt.Errorf("数据源不存在: %s", name)
+}
+
+// SetDataSourceEnabled 运行时启用或禁用数据源，返回修改后的数据源在列表中的指针
+func (msc *MultiSourceConfig) SetDataSourceEnabled(name string, enabled bool) (*DataSourceConfig, error) {
+	dataSourcesMu.Lock()
+	defer dataSourcesMu.Unlock()
+
+	index := -1
+	for i := range msc.DataSources {
+		if msc.DataSources[i].Name == name {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		return nil, fmt.Errorf("数据源不存在: %s", name)
+	}
+
+	// 启用时重新检查地址冲突，避免与运行时新增的数据源重复采集同一实例
+	if enabled {
+		hostAddr := stripHostQuery(msc.DataSources[index].DbHost)
+		for i, existing := range msc.DataSources {
+			if i != index && existing.Enabled && stripHostQuery(existing.DbHost) == hostAddr {
+				return nil, fmt.Errorf("数据源地址重复: %s (被 '%s' 和 '%s' 同时使用)", hostAddr, existing.Name, name)
+			}
+		}
+	}
+
+	list := make([]DataSourceConfig, len(msc.DataSources))
+	copy(list, msc.DataSources)
+	list[index].Enabled = enabled
+	msc.DataSources = list
+	return &list[index], nil
+}
+
+// stripHostQuery 去除地址中的查询参数，用于地址去重
+func stripHostQuery(host string) string {
+	if idx := strings.Index(host, "?"); idx != -1 {
+		return host[:idx]
+	}
+	return host
+}
+
+// ParseDataSourceJSON 解析 JSON 格式的数据源配置，字段名与配置文件一致，未设置的字段使用默认值，ENC() 格式的密码会被解密
+func ParseDataSourceJSON(data []byte) (DataSourceConfig, error) {
+	var raw rawDataSourceConfig
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&raw); err != nil {
+		return DataSourceConfig{}, fmt.Errorf("invalid datasource definition: %w", err)
+	}
+
+	ds := raw.toConfig()
+	for _, pwd := range []*string{&ds.DbPwd, &ds.DiscoveryPwd} {
+		if strings.HasPrefix(*pwd, encryptedPrefix) && strings.HasSuffix(*pwd, ")") {
+			decPwd, err := DecryptPassword(*pwd)
+			if err != nil {
+				return DataSourceConfig{}, fmt.Errorf("failed to decrypt password for datasource %s: %w", ds.Name, err)
+			}
+			*pwd = decPwd
+		}
+	}
+	return ds, nil
+}
+
+// PersistDataSourceEnabled 将数据源启用状态写回配置文件
+func PersistDataSourceEnabled(configFile, name string, enabled bool) error {
+	return persistDataSources(configFile, func(fileConfig *MultiSourceConfig) error {
+		for i := range fileConfig.DataSources {
+			if fileConfig.DataSources[i].Name == name {
+				fileConfig.DataSources[i].Enabled = enabled
+				return nil
+			}
+		}
+		return fmt.Errorf("datasource %s not found in config file", name)
+	})
+}
+
+// PersistDataSourceAdded 将新增的数据源写入配置文件，启用密码加密时以 ENC() 格式保存密码
+func PersistDataSourceAdded(configFile string, ds DataSourceConfig) error {
+	return persistDataSources(configFile, func(fileConfig *MultiSourceConfig) error {
+		for i := range fileConfig.DataSources {
+			if fileConfig.DataSources[i].Name == ds.Name {
+				return fmt.Errorf("datasource %s already exists in config file", ds.Name)
+			}
+		}
+		if fileConfig.EncodeConfigPwd {
+			ds.DbPwd = EncryptPassword(ds.DbPwd)
+			if ds.DiscoveryPwd != "" {
+				ds.DiscoveryPwd = EncryptPassword(ds.DiscoveryPwd)
+			}
+		}
+		ds.DiscoveredFrom = ""
+		fileConfig.DataSources = append(fileConfig.DataSources, ds)
+		return nil
+	})
+}
+
+// PersistDataSourceRemoved 从配置文件中删除数据源
+func PersistDataSourceRemoved(configFile, name string) error {
+	return persistDataSources(configFile, func(fileConfig *MultiSourceConfig) error {
+		for i := range fileConfig.DataSources {
+			if fileConfig.DataSources[i].Name == name {
+				fileConfig.DataSources = append(fileConfig.DataSources[:i], fileConfig.DataSources[i+1:]...)
+				return nil
+			}
+		}
+		return fmt.Errorf("datasource %s not found in config file", name)
+	})
+}
+
+// persistDataSources 以配置文件的原始内容为基础修改数据源列表并写回，
+// 与 CheckAndEncryptConfigPasswords 相同，保留文件中密码的原有格式（不会写入解密后的明文）
+func persistDataSources(configFile string, mutate func(fileConfig *MultiSourceConfig) error) error {
+	if configFile == "" {
+		return fmt.Errorf("config file path is empty")
+	}
+
+	persistMu.Lock()
+	defer persistMu.Unlock()
+
+	rawContent, err := os.ReadFile(configFile)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	rawConfig := &rawMultiSourceConfig{}
+	if _, err := toml.Decode(string(rawContent), rawConfig); err != nil {
+		return fmt.Errorf("failed to decode config file: %w", err)
+	}
+
+	fileConfig := rawConfig.toConfig()
+	if err := mutate(fileConfig); err != nil {
+		return err
+	}
+
+	if err := SaveMultiSourceConfig(fileConfig, configFile); err != nil {
+		return fmt.Errorf("failed to update config file: %w", err)
+	}
+	return nil
+}
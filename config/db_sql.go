@@ -205,4 +205,19 @@ FROM (
 
 	// 检查V$RLOG视图中LSN字段是否存在
 	QueryRlogColumnsExist = "SELECT /*+DM_EXPORTER*/ COUNT(*) FROM V$DYNAMIC_TABLE_COLUMNS WHERE TABNAME = 'V$RLOG' AND COLNAME IN ('CKPT_LSN','FILE_LSN','FLUSH_LSN','CUR_LSN')"
+
+	// 拓扑自动发现：当前实例名称
+	QueryDiscoveryInstanceNameSql = `SELECT /*+DM_EXPORTER*/ INSTANCE_NAME FROM V$INSTANCE`
+
+	// 拓扑自动发现：归档目标（实时/即时/同步/异步归档的目标均为集群成员实例名）
+	QueryDiscoveryArchDestSql = `SELECT /*+DM_EXPORTER*/ DISTINCT ARCH_DEST FROM V$ARCH_STATUS WHERE ARCH_TYPE IN ('REALTIME','TIMELY','SYNC','ASYNC')`
+
+	// 拓扑自动发现：归档发送目标
+	QueryDiscoveryArchSendDestSql = `SELECT /*+DM_EXPORTER*/ DISTINCT ARCH_DEST FROM V$ARCH_SEND_INFO`
+
+	// 拓扑自动发现：守护进程所在组的实例
+	QueryDiscoveryWatcherInstSql = `SELECT /*+DM_EXPORTER*/ DISTINCT INST_NAME FROM V$DMWATCHER`
+
+	// 拓扑自动发现：通过MAL配置解析实例地址
+	QueryDiscoveryMalInstSql = `SELECT /*+DM_EXPORTER*/ MAL_INST_NAME, MAL_INST_HOST, MAL_INST_PORT FROM V$DM_MAL_INI`
 )
@@ -0,0 +1,107 @@
+package config
+
+import (
+	"maps"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// maskedSecret 输出配置时替换敏感字段的值
+const maskedSecret = "******"
+
+// Masked 返回隐藏敏感字段后的配置副本，用于输出生效配置：
+// 数据源与发现成员密码、Basic 认证密码、Webhook 加签密钥、推送凭据与 OTLP 请求头的值替换为 ******，
+// 地址中的用户密码与查询参数（如钉钉机器人的 access_token）同样隐藏，飞书机器人地址末段的令牌也会隐藏
+func (msc *MultiSourceConfig) Masked() *MultiSourceConfig {
+	masked := *msc
+	masked.BasicAuthPassword = maskSecret(msc.BasicAuthPassword)
+
+	masked.DataSources = slices.Clone(msc.DataSources)
+	for i := range masked.DataSources {
+		ds := &masked.DataSources[i]
+		ds.DbPwd = maskSecret(ds.DbPwd)
+		ds.DiscoveryPwd = maskSecret(ds.DiscoveryPwd)
+	}
+
+	masked.Webhooks = slices.Clone(msc.Webhooks)
+	for i := range masked.Webhooks {
+		w := &masked.Webhooks[i]
+		if w.Type == WebhookTypeFeishu {
+			w.URL = maskURL(maskLastPathSegment(w.URL))
+		} else {
+			w.URL = maskURL(w.URL)
+		}
+		w.Secret = maskSecret(w.Secret)
+	}
+
+	masked.RemoteWrite = slices.Clone(msc.RemoteWrite)
+	for i := range masked.RemoteWrite {
+		r := &masked.RemoteWrite[i]
+		r.URL = maskURL(r.URL)
+		r.Password = maskSecret(r.Password)
+		r.BearerToken = maskSecret(r.BearerToken)
+	}
+
+	masked.OTLP = slices.Clone(msc.OTLP)
+	for i := range masked.OTLP {
+		o := &masked.OTLP[i]
+		o.Endpoint = maskURL(o.Endpoint)
+		o.Headers = maps.Clone(o.Headers)
+		for name, value := range o.Headers {
+			o.Headers[name] = maskSecret(value)
+		}
+	}
+	return &masked
+}
+
+// maskSecret 隐藏非空的敏感值
+func maskSecret(value string) string {
+	if value == "" {
+		return ""
+	}
+	return maskedSecret
+}
+
+// maskLastPathSegment 隐藏地址路径的最后一段，用于令牌位于路径中的地址（如飞书机器人的 /hook/<token>），无法解析时整体隐藏
+func maskLastPathSegment(raw string) string {
+	if raw == "" {
+		return ""
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return maskedSecret
+	}
+	path := strings.TrimSuffix(u.Path, "/")
+	if i := strings.LastIndex(path, "/"); i >= 0 && i < len(path)-1 {
+		// 与其他字段一致地显示 ******，不转义为 %2A
+		u.RawPath = (&url.URL{Path: path[:i+1]}).EscapedPath() + maskedSecret
+		u.Path = path[:i+1] + maskedSecret
+	}
+	return u.String()
+}
+
+// maskURL 隐藏地址中的密码与查询参数的值，无法解析时整体隐藏
+func maskURL(raw string) string {
+	if raw == "" {
+		return ""
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return maskedSecret
+	}
+	if u.RawQuery != "" {
+		names := slices.Sorted(maps.Keys(u.Query()))
+		for i, name := range names {
+			names[i] = url.QueryEscape(name) + "=" + maskedSecret
+		}
+		u.RawQuery = strings.Join(names, "&")
+	}
+	// url.UserPassword 会转义 *，手工拼接用户信息以保持与其他字段一致的显示
+	if _, ok := u.User.Password(); ok {
+		username := u.User.Username()
+		u.User = nil
+		return strings.Replace(u.String(), "://", "://"+url.PathEscape(username)+":"+maskedSecret+"@", 1)
+	}
+	return u.String()
+}
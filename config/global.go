@@ -64,6 +64,16 @@ func (g *GlobalSettings) GetMetricPath() string {
 	return g.config.MetricPath
 }
 
+// GetEnableOpenMetrics 获取是否启用 OpenMetrics 格式输出
+func (g *GlobalSettings) GetEnableOpenMetrics() bool {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	if g.config == nil {
+		return DefaultMultiSourceConfig.EnableOpenMetrics
+	}
+	return g.config.EnableOpenMetrics
+}
+
 // GetLogLevel 获取日志级别
 func (g *GlobalSettings) GetLogLevel() string {
 	g.mu.RLock()
@@ -94,6 +104,36 @@ func (g *GlobalSettings) GetLogMaxBackups() int {
 	return g.config.LogMaxBackups
 }
 
+// GetLogDir 获取日志文件目录
+func (g *GlobalSettings) GetLogDir() string {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	if g.config == nil {
+		return DefaultMultiSourceConfig.LogDir
+	}
+	return g.config.LogDir
+}
+
+// GetLogFormat 获取日志格式
+func (g *GlobalSettings) GetLogFormat() string {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	if g.config == nil {
+		return DefaultMultiSourceConfig.LogFormat
+	}
+	return g.config.LogFormat
+}
+
+// GetLogOutput 获取日志输出位置
+func (g *GlobalSettings) GetLogOutput() string {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	if g.config == nil {
+		return DefaultMultiSourceConfig.LogOutput
+	}
+	return g.config.LogOutput
+}
+
 // GetLogMaxAge 获取日志最大保留天数
 func (g *GlobalSettings) GetLogMaxAge() int {
 	g.mu.RLock()
@@ -104,6 +144,16 @@ func (g *GlobalSettings) GetLogMaxAge() int {
 	return g.config.LogMaxAge
 }
 
+// GetLogDedupIntervalSeconds 获取数据源日志去重周期（秒）
+func (g *GlobalSettings) GetLogDedupIntervalSeconds() int {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	if g.config == nil {
+		return DefaultMultiSourceConfig.LogDedupIntervalSeconds
+	}
+	return g.config.LogDedupIntervalSeconds
+}
+
 // GetEnableBasicAuth 获取是否启用基础认证
 func (g *GlobalSettings) GetEnableBasicAuth() bool {
 	g.mu.RLock()
@@ -154,15 +204,320 @@ func (g *GlobalSettings) GetEnableHealthPing() bool {
 	return g.config.IsHealthPingEnabled()
 }
 
+// GetShutdownDrainSeconds 获取停机前的流量摘除等待时间
+func (g *GlobalSettings) GetShutdownDrainSeconds() int {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	if g.config == nil {
+		return DefaultMultiSourceConfig.ShutdownDrainSeconds
+	}
+	return g.config.ShutdownDrainSeconds
+}
+
+// GetShutdownTimeoutSeconds 获取等待在途请求完成的超时时间
+func (g *GlobalSettings) GetShutdownTimeoutSeconds() int {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	if g.config == nil {
+		return DefaultMultiSourceConfig.ShutdownTimeoutSeconds
+	}
+	return g.config.ShutdownTimeoutSeconds
+}
+
+// GetReadyMinHealthyDatasources 获取就绪判定所需的最少健康数据源数量
+func (g *GlobalSettings) GetReadyMinHealthyDatasources() int {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	if g.config == nil {
+		return DefaultMultiSourceConfig.ReadyMinHealthyDatasources
+	}
+	return g.config.ReadyMinHealthyDatasources
+}
+
+// GetAlarmStateFile 获取告警状态持久化文件路径
+func (g *GlobalSettings) GetAlarmStateFile() string {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	if g.config == nil {
+		return DefaultMultiSourceConfig.AlarmStateFile
+	}
+	return g.config.AlarmStateFile
+}
+
+// GetCacheMaxEntries 获取缓存容量上限
+func (g *GlobalSettings) GetCacheMaxEntries() int {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	if g.config == nil {
+		return DefaultMultiSourceConfig.CacheMaxEntries
+	}
+	return g.config.CacheMaxEntries
+}
+
+// GetScrapeCoalesceSeconds 获取抓取合并的时间窗口（秒）
+func (g *GlobalSettings) GetScrapeCoalesceSeconds() int {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	if g.config == nil {
+		return DefaultMultiSourceConfig.ScrapeCoalesceSeconds
+	}
+	return g.config.ScrapeCoalesceSeconds
+}
+
+// GetMaxConcurrentScrapes 获取同时执行的采集数量上限
+func (g *GlobalSettings) GetMaxConcurrentScrapes() int {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	if g.config == nil {
+		return DefaultMultiSourceConfig.MaxConcurrentScrapes
+	}
+	return g.config.MaxConcurrentScrapes
+}
+
+// GetBasicAuthUsersFile 获取 Basic 认证用户文件路径
+func (g *GlobalSettings) GetBasicAuthUsersFile() string {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	if g.config == nil {
+		return DefaultMultiSourceConfig.BasicAuthUsersFile
+	}
+	return g.config.BasicAuthUsersFile
+}
+
+// GetBasicAuthLegacyHashCompare 获取是否兼容旧版本的密码哈希比较
+func (g *GlobalSettings) GetBasicAuthLegacyHashCompare() bool {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	if g.config == nil {
+		return DefaultMultiSourceConfig.BasicAuthLegacyHashCompare
+	}
+	return g.config.BasicAuthLegacyHashCompare
+}
+
+// GetBearerTokensFile 获取 Bearer 令牌文件路径
+func (g *GlobalSettings) GetBearerTokensFile() string {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	if g.config == nil {
+		return DefaultMultiSourceConfig.BearerTokensFile
+	}
+	return g.config.BearerTokensFile
+}
+
+// GetAuthEnabled 获取是否启用访问认证
+func (g *GlobalSettings) GetAuthEnabled() bool {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	if g.config == nil {
+		return DefaultMultiSourceConfig.IsAuthEnabled()
+	}
+	return g.config.IsAuthEnabled()
+}
+
+// GetAccessRules 获取访问规则列表的副本
+func (g *GlobalSettings) GetAccessRules() []AccessRuleConfig {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	if g.config == nil {
+		return nil
+	}
+	return append([]AccessRuleConfig(nil), g.config.AccessRules...)
+}
+
+// GetTrustedProxies 获取可信代理地址段列表的副本
+func (g *GlobalSettings) GetTrustedProxies() []string {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	if g.config == nil {
+		return nil
+	}
+	return append([]string(nil), g.config.TrustedProxies...)
+}
+
+// GetAuthFailureLimit 获取统计窗口内允许的认证失败次数
+func (g *GlobalSettings) GetAuthFailureLimit() int {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	if g.config == nil {
+		return DefaultMultiSourceConfig.AuthFailureLimit
+	}
+	return g.config.AuthFailureLimit
+}
+
+// GetAuthFailureWindowSeconds 获取认证失败次数的统计窗口（秒）
+func (g *GlobalSettings) GetAuthFailureWindowSeconds() int {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	if g.config == nil {
+		return DefaultMultiSourceConfig.AuthFailureWindowSeconds
+	}
+	return g.config.AuthFailureWindowSeconds
+}
+
+// GetAuthLockoutSeconds 获取认证失败过多后的锁定时间（秒）
+func (g *GlobalSettings) GetAuthLockoutSeconds() int {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	if g.config == nil {
+		return DefaultMultiSourceConfig.AuthLockoutSeconds
+	}
+	return g.config.AuthLockoutSeconds
+}
+
+// GetEnableAdminAPI 获取是否开放数据源运维接口
+func (g *GlobalSettings) GetEnableAdminAPI() bool {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	if g.config == nil {
+		return DefaultMultiSourceConfig.EnableAdminAPI
+	}
+	return g.config.EnableAdminAPI
+}
+
+// GetWebhookDedupSeconds 获取相同事件的通知去重窗口（秒）
+func (g *GlobalSettings) GetWebhookDedupSeconds() int {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	if g.config == nil {
+		return DefaultMultiSourceConfig.WebhookDedupSeconds
+	}
+	return g.config.WebhookDedupSeconds
+}
+
+// GetEventHistorySize 获取内存中保留的事件数量
+func (g *GlobalSettings) GetEventHistorySize() int {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	if g.config == nil {
+		return DefaultMultiSourceConfig.EventHistorySize
+	}
+	return g.config.EventHistorySize
+}
+
+// GetWebhooks 获取 Webhook 配置列表的副本
+func (g *GlobalSettings) GetWebhooks() []WebhookConfig {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	if g.config == nil {
+		return nil
+	}
+	return append([]WebhookConfig(nil), g.config.Webhooks...)
+}
+
+// GetRemoteWriteIntervalSeconds 获取 remote_write 推送周期（秒）
+func (g *GlobalSettings) GetRemoteWriteIntervalSeconds() int {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	if g.config == nil {
+		return DefaultMultiSourceConfig.RemoteWriteIntervalSeconds
+	}
+	return g.config.RemoteWriteIntervalSeconds
+}
+
+// GetRemoteWrite 获取 remote_write 推送目标列表的副本
+func (g *GlobalSettings) GetRemoteWrite() []RemoteWriteConfig {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	if g.config == nil {
+		return nil
+	}
+	return append([]RemoteWriteConfig(nil), g.config.RemoteWrite...)
+}
+
+// GetReport 获取巡检报告的检查阈值
+func (g *GlobalSettings) GetReport() ReportConfig {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	if g.config == nil {
+		return DefaultReportConfig
+	}
+	return g.config.Report
+}
+
+// GetOTLPIntervalSeconds 获取 OTLP 导出周期（秒）
+func (g *GlobalSettings) GetOTLPIntervalSeconds() int {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	if g.config == nil {
+		return DefaultMultiSourceConfig.OTLPIntervalSeconds
+	}
+	return g.config.OTLPIntervalSeconds
+}
+
+// GetOTLP 获取 OTLP 导出目标列表的副本
+func (g *GlobalSettings) GetOTLP() []OTLPConfig {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	if g.config == nil {
+		return nil
+	}
+	return append([]OTLPConfig(nil), g.config.OTLP...)
+}
+
+// GetZabbixServer 获取 Zabbix server/proxy 地址，为空表示不推送
+func (g *GlobalSettings) GetZabbixServer() string {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	if g.config == nil {
+		return DefaultMultiSourceConfig.ZabbixServer
+	}
+	return g.config.ZabbixServer
+}
+
+// GetZabbixIntervalSeconds 获取 Zabbix 监控项推送周期（秒）
+func (g *GlobalSettings) GetZabbixIntervalSeconds() int {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	if g.config == nil {
+		return DefaultMultiSourceConfig.ZabbixIntervalSeconds
+	}
+	return g.config.ZabbixIntervalSeconds
+}
+
+// GetZabbixHostLabel 获取取值作为 Zabbix 主机名的标签
+func (g *GlobalSettings) GetZabbixHostLabel() string {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	if g.config == nil {
+		return DefaultMultiSourceConfig.ZabbixHostLabel
+	}
+	return g.config.ZabbixHostLabel
+}
+
+// GetZabbixDiscoveryIntervalSeconds 获取 Zabbix 低级别发现数据推送周期（秒）
+func (g *GlobalSettings) GetZabbixDiscoveryIntervalSeconds() int {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	if g.config == nil {
+		return DefaultMultiSourceConfig.ZabbixDiscoveryIntervalSeconds
+	}
+	return g.config.ZabbixDiscoveryIntervalSeconds
+}
+
+// GetZabbixTimeoutSeconds 获取 Zabbix 单次发送超时（秒）
+func (g *GlobalSettings) GetZabbixTimeoutSeconds() int {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	if g.config == nil {
+		return DefaultMultiSourceConfig.ZabbixTimeoutSeconds
+	}
+	return g.config.ZabbixTimeoutSeconds
+}
+
 // GetDefaultDataSource 获取默认数据源配置（用于兼容旧代码）
 func (g *GlobalSettings) GetDefaultDataSource() *DataSourceConfig {
 	g.mu.RLock()
 	defer g.mu.RUnlock()
-	if g.config == nil || len(g.config.DataSources) == 0 {
+	if g.config == nil {
+		defaultDS := DefaultDataSourceConfig
+		return &defaultDS
+	}
+	dataSources := g.config.DataSourceList()
+	if len(dataSources) == 0 {
 		defaultDS := DefaultDataSourceConfig
 		return &defaultDS
 	}
-	return &g.config.DataSources[0]
+	return &dataSources[0]
 }
 
 // GetQueryTimeout 获取查询超时（从第一个数据源）
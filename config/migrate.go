@@ -0,0 +1,107 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/duke-git/lancet/v2/fileutil"
+)
+
+// LoadLegacyConfig 加载 v1.1.x 及更早版本的单数据源配置文件。
+// 旧版配置为扁平的 TOML 文件，全局参数与数据库连接参数均位于顶层；
+// 全局参数按多数据源配置的字段读取，数据库连接、慢SQL、指标注册、labels 与 customMetricsFile 等字段归入一个数据源。
+// 密码保持原样，ENC() 格式不会被解密
+func LoadLegacyConfig(configFile string) (*MultiSourceConfig, error) {
+	if !fileutil.IsExist(configFile) {
+		return nil, fmt.Errorf("legacy config file not found: %s", configFile)
+	}
+	content, err := os.ReadFile(configFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read legacy config file: %w", err)
+	}
+
+	var raw rawMultiSourceConfig
+	if _, err := toml.Decode(string(content), &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse legacy config file as TOML: %w", err)
+	}
+	if len(raw.DataSources) > 0 {
+		return nil, fmt.Errorf("%s already uses the multi-source format ([[datasource]])", configFile)
+	}
+	var rawDs rawDataSourceConfig
+	if _, err := toml.Decode(string(content), &rawDs); err != nil {
+		return nil, fmt.Errorf("failed to parse legacy config file as TOML: %w", err)
+	}
+	if rawDs.DbHost == "" {
+		return nil, fmt.Errorf("%s does not contain a legacy datasource (dbHost)", configFile)
+	}
+	// 旧版的 logLevel 是全局参数，不再作为数据源的日志级别重复写入；
+	// 旧版普遍配置的 maxIdleConns 已废弃，迁移时直接丢弃，不输出废弃警告以免混入标准输出的配置
+	rawDs.LogLevel = ""
+	rawDs.MaxIdleConns = 0
+	if rawDs.Name == "" {
+		rawDs.Name = legacyDataSourceName(rawDs.DbHost)
+	}
+	if rawDs.Description == "" {
+		rawDs.Description = fmt.Sprintf("DataSource migrated from %s", configFile)
+	}
+
+	cfg := raw.toConfig()
+	cfg.DataSources = []DataSourceConfig{rawDs.toConfig()}
+	return cfg, nil
+}
+
+// MigrateCmdArgs 根据旧版命令行参数生成多数据源配置，与启动时的命令行模式一致：
+// 全局参数取命令行的值，数据源名称为 cmdline_<主机地址>
+func MigrateCmdArgs(args *CmdArgs) (*MultiSourceConfig, error) {
+	if *args.DbHost == "" || *args.DbUser == "" || *args.DbPwd == "" {
+		return nil, fmt.Errorf("--dbHost, --dbUser and --dbPwd must all be specified")
+	}
+	cfg := DefaultMultiSourceConfig
+	applyCmdArgsGlobals(&cfg, args)
+	cfg.DataSources = []DataSourceConfig{cmdArgsDataSource(args)}
+	return &cfg, nil
+}
+
+// OverrideConnection 使用命令行指定的数据库连接参数覆盖旧版配置文件中的对应字段，未指定的字段保持不变
+func (msc *MultiSourceConfig) OverrideConnection(args *CmdArgs) {
+	for i := range msc.DataSources {
+		ds := &msc.DataSources[i]
+		if *args.DbHost != "" {
+			ds.DbHost = *args.DbHost
+		}
+		if *args.DbUser != "" {
+			ds.DbUser = *args.DbUser
+		}
+		if *args.DbPwd != "" {
+			ds.DbPwd = *args.DbPwd
+		}
+	}
+}
+
+// legacyDataSourceName 旧版配置文件迁移后的数据源名称：dm_<主机地址>
+func legacyDataSourceName(dbHost string) string {
+	return "dm_" + strings.Split(dbHost, ":")[0]
+}
+
+// WriteMultiSourceConfig 将配置以 TOML 格式写出，header 不为空时作为注释写在文件开头
+func WriteMultiSourceConfig(w io.Writer, config *MultiSourceConfig, header string) error {
+	if header != "" {
+		for _, line := range strings.Split(strings.TrimRight(header, "\n"), "\n") {
+			if _, err := fmt.Fprintf(w, "# %s\n", line); err != nil {
+				return err
+			}
+		}
+		if _, err := fmt.Fprintln(w); err != nil {
+			return err
+		}
+	}
+	saveConfig := *config
+	saveConfig.ApplyAllDefaults()
+	if err := toml.NewEncoder(w).Encode(saveConfig); err != nil {
+		return fmt.Errorf("failed to encode config to TOML: %w", err)
+	}
+	return nil
+}
@@ -2,6 +2,7 @@ package config
 
 import (
 	"fmt"
+	"net"
 	"strings"
 )
 
@@ -11,20 +12,31 @@ var GlobalMultiConfig *MultiSourceConfig
 // MultiSourceConfig 多数据源配置结构
 type MultiSourceConfig struct {
 	// 全局系统级配置（不可下沉）
-	ConfigFile           string `toml:"-"` // 配置文件路径，不从配置文件读取
-	ListenAddress        string `toml:"listenAddress"`
-	MetricPath           string `toml:"metricPath"`
-	Version              string `toml:"version"`
-	LogMaxSize           int    `toml:"logMaxSize"`
-	LogMaxBackups        int    `toml:"logMaxBackups"`
-	LogMaxAge            int    `toml:"logMaxAge"`
-	LogLevel             string `toml:"logLevel"`
-	EncodeConfigPwd      bool   `toml:"encodeConfigPwd"`
-	EnableBasicAuth      bool   `toml:"enableBasicAuth"`
-	BasicAuthUsername    string `toml:"basicAuthUsername"`
-	BasicAuthPassword    string `toml:"basicAuthPassword"`
-	RetryIntervalSeconds int    `toml:"retryIntervalSeconds"`
-	EnableHealthPing     bool   `toml:"enableHealthPing"`
+	ConfigFile         string `toml:"-"` // 配置文件路径，不从配置文件读取
+	ListenAddress      string `toml:"listenAddress"`
+	MetricPath         string `toml:"metricPath"`
+	Version            string `toml:"version"`
+	EnableOpenMetrics  bool   `toml:"enableOpenMetrics"` // 客户端协商时使用 OpenMetrics 格式输出（含 _created 与 exemplar）
+	LogMaxSize         int    `toml:"logMaxSize"`
+	LogMaxBackups      int    `toml:"logMaxBackups"`
+	LogMaxAge          int    `toml:"logMaxAge"`
+	LogLevel           string `toml:"logLevel"`
+	LogDir             string `toml:"logDir"`    // 日志文件目录
+	LogFormat          string `toml:"logFormat"` // 日志格式：auto/json/console/logfmt
+	LogOutput          string `toml:"logOutput"` // 日志输出位置：file/stdout/both
+	EncodeConfigPwd    bool   `toml:"encodeConfigPwd"`
+	EnableBasicAuth    bool   `toml:"enableBasicAuth"`
+	BasicAuthUsername  string `toml:"basicAuthUsername"`
+	BasicAuthPassword  string `toml:"basicAuthPassword"`
+	BasicAuthUsersFile string `toml:"basicAuthUsersFile"` // htpasswd 格式的用户文件（bcrypt/argon2id/sha512-crypt 哈希），可附加角色列
+	// 兼容旧版本的密码校验：接受客户端发送配置中的 bcrypt 哈希或配置明文的 bcrypt 哈希作为密码，存在安全风险，仅用于过渡
+	BasicAuthLegacyHashCompare bool   `toml:"basicAuthLegacyHashCompare"`
+	BearerTokensFile           string `toml:"bearerTokensFile"` // 静态 Bearer 令牌文件
+	RetryIntervalSeconds       int    `toml:"retryIntervalSeconds"`
+	EnableHealthPing           bool   `toml:"enableHealthPing"`
+
+	// 日志去重配置：同一数据源的同类警告与错误日志在周期内只输出首条，之后汇总输出被抑制的条数，0 表示不去重
+	LogDedupIntervalSeconds int `toml:"logDedupIntervalSeconds"`
 
 	// 全局超时控制配置
 	GlobalTimeoutSeconds int `toml:"globalTimeoutSeconds"` // 全局超时时间（秒）
@@ -34,6 +46,54 @@ type MultiSourceConfig struct {
 	// "fast": 快速模式，超时返回部分数据（适合要求快速响应的场景）
 	CollectionMode string `toml:"collectionMode"`
 
+	// 抓取合并与并发控制配置
+	ScrapeCoalesceSeconds int `toml:"scrapeCoalesceSeconds"` // 并发或在该时间窗口内到达的相同抓取共用一次采集结果（秒），0 表示不合并
+	MaxConcurrentScrapes  int `toml:"maxConcurrentScrapes"`  // 同时执行的采集数量上限，超出时返回503，0 表示不限制
+
+	// 优雅停机与探针配置
+	ShutdownDrainSeconds       int `toml:"shutdownDrainSeconds"`       // 收到停止信号后保持服务、等待流量摘除的时间（秒）
+	ShutdownTimeoutSeconds     int `toml:"shutdownTimeoutSeconds"`     // 等待在途请求处理完成的最长时间（秒）
+	ReadyMinHealthyDatasources int `toml:"readyMinHealthyDatasources"` // /-/ready 判定就绪所需的最少健康数据源数量
+
+	// 告警状态持久化配置，为空表示仅保存在内存中
+	AlarmStateFile string `toml:"alarmStateFile"`
+
+	// 缓存容量上限（条目数），超出后按最近最少使用淘汰
+	CacheMaxEntries int `toml:"cacheMaxEntries"`
+
+	// 数据源状态变化通知配置
+	WebhookDedupSeconds int             `toml:"webhookDedupSeconds"` // 相同事件的去重窗口（秒），0 表示不去重
+	EventHistorySize    int             `toml:"eventHistorySize"`    // 内存中保留的最近事件数量
+	Webhooks            []WebhookConfig `toml:"webhook"`             // Webhook 列表
+
+	// remote_write 推送配置，未配置推送目标时不启用
+	RemoteWriteIntervalSeconds int                 `toml:"remoteWriteIntervalSeconds"` // 推送周期（秒）
+	RemoteWrite                []RemoteWriteConfig `toml:"remoteWrite"`                // 推送目标列表
+
+	// OpenTelemetry OTLP 导出配置，未配置导出目标时不启用
+	OTLPIntervalSeconds int          `toml:"otlpIntervalSeconds"` // 导出周期（秒）
+	OTLP                []OTLPConfig `toml:"otlp"`                // 导出目标列表
+
+	// Zabbix 主动推送配置，zabbixServer 为空时不启用推送（低级别发现接口始终可用）
+	ZabbixServer                   string `toml:"zabbixServer"`                   // Zabbix server/proxy 的 trapper 地址，如 zabbix:10051
+	ZabbixIntervalSeconds          int    `toml:"zabbixIntervalSeconds"`          // 监控项推送周期（秒）
+	ZabbixHostLabel                string `toml:"zabbixHostLabel"`                // 取值作为 Zabbix 主机名的标签
+	ZabbixDiscoveryIntervalSeconds int    `toml:"zabbixDiscoveryIntervalSeconds"` // 通过 trapper 推送低级别发现数据的周期（秒），0 表示不推送
+	ZabbixTimeoutSeconds           int    `toml:"zabbixTimeoutSeconds"`           // 单次发送超时（秒）
+
+	// 巡检报告的检查阈值
+	Report ReportConfig `toml:"report"`
+
+	// 是否开放数据源运维接口（运行时启停、重连、增删数据源），建议同时启用 Basic 认证
+	EnableAdminAPI bool `toml:"enableAdminApi"`
+
+	// 访问控制配置
+	AccessRules              []AccessRuleConfig `toml:"accessRule"`               // 按路径限制客户端地址的规则，按顺序匹配第一条
+	TrustedProxies           []string           `toml:"trustedProxies"`           // 可信代理地址段，来自这些地址的请求从 X-Forwarded-For 获取客户端地址
+	AuthFailureLimit         int                `toml:"authFailureLimit"`         // 同一客户端地址在统计窗口内允许的认证失败次数，0 表示不限制
+	AuthFailureWindowSeconds int                `toml:"authFailureWindowSeconds"` // 认证失败次数的统计窗口（秒）
+	AuthLockoutSeconds       int                `toml:"authLockoutSeconds"`       // 超过失败次数后的锁定时间（秒）
+
 	// 数据源列表
 	DataSources []DataSourceConfig `toml:"datasource"`
 
@@ -74,6 +134,93 @@ type DataSourceConfig struct {
 	// 采集配置
 	Labels            string `toml:"labels"`            // 标签字符串，格式: "key1=val1,key2=val2"
 	CustomMetricsFile string `toml:"customMetricsFile"` // 数据源专用的自定义指标配置文件
+	LogLevel          string `toml:"logLevel"`          // 数据源相关日志的级别，为空时使用全局 logLevel
+
+	// 拓扑自动发现配置（仅对配置文件中的种子数据源生效）
+	Discovery                bool   `toml:"discovery"`                // 是否从该数据源自动发现备库/集群成员
+	DiscoveryIntervalSeconds int    `toml:"discoveryIntervalSeconds"` // 发现周期（秒）
+	DiscoveryPort            int    `toml:"discoveryPort"`            // 成员端口模板，0 表示使用视图中的端口
+	DiscoveryUser            string `toml:"discoveryUser"`            // 成员连接用户，为空时继承种子数据源
+	DiscoveryPwd             string `toml:"discoveryPwd"`             // 成员连接密码，为空时继承种子数据源，支持ENC()加密格式
+
+	// 运行时辅助字段（不参与序列化）
+	DiscoveredFrom string `toml:"-"` // 自动发现的成员记录其种子数据源名称
+}
+
+// 日志格式
+const (
+	LogFormatAuto    = "auto"    // 文件使用 json，标准输出使用 console
+	LogFormatJSON    = "json"    // JSON
+	LogFormatConsole = "console" // 便于阅读的文本格式
+	LogFormatLogfmt  = "logfmt"  // key=value 格式
+)
+
+// 日志输出位置
+const (
+	LogOutputFile   = "file"   // 仅写入日志文件
+	LogOutputStdout = "stdout" // 仅输出到标准输出（适合容器）
+	LogOutputBoth   = "both"   // 同时写入日志文件与标准输出
+)
+
+// Webhook 类型
+const (
+	WebhookTypeGeneric  = "generic"  // 通用 JSON
+	WebhookTypeDingTalk = "dingtalk" // 钉钉机器人
+	WebhookTypeWeCom    = "wecom"    // 企业微信机器人
+	WebhookTypeFeishu   = "feishu"   // 飞书机器人
+)
+
+// WebhookConfig 数据源状态变化通知的 Webhook 配置
+type WebhookConfig struct {
+	Name           string   `toml:"name"`           // 名称，用于日志
+	Type           string   `toml:"type"`           // generic/dingtalk/wecom/feishu
+	URL            string   `toml:"url"`            // 推送地址
+	Secret         string   `toml:"secret"`         // 钉钉/飞书机器人加签密钥，可选，支持ENC()加密格式
+	Events         []string `toml:"events"`         // 订阅的事件类型（down/up），为空表示全部
+	TimeoutSeconds int      `toml:"timeoutSeconds"` // 单次请求超时（秒）
+	MaxRetries     int      `toml:"maxRetries"`     // 失败重试次数
+}
+
+// DefaultWebhookConfig 默认 Webhook 配置
+var DefaultWebhookConfig = WebhookConfig{
+	Type:           WebhookTypeGeneric,
+	TimeoutSeconds: 5,
+	MaxRetries:     3,
+}
+
+// applyDefaults 为 Webhook 应用默认值
+func (w *WebhookConfig) applyDefaults() {
+	if w.Type == "" {
+		w.Type = DefaultWebhookConfig.Type
+	}
+	w.Type = strings.ToLower(w.Type)
+	if w.TimeoutSeconds <= 0 {
+		w.TimeoutSeconds = DefaultWebhookConfig.TimeoutSeconds
+	}
+	if w.MaxRetries <= 0 {
+		w.MaxRetries = DefaultWebhookConfig.MaxRetries
+	}
+}
+
+// Validate 验证 Webhook 配置
+func (w *WebhookConfig) Validate() error {
+	if w.URL == "" {
+		return fmt.Errorf("webhook %s: 推送地址不能为空 (url)", w.Name)
+	}
+	if !strings.HasPrefix(w.URL, "http://") && !strings.HasPrefix(w.URL, "https://") {
+		return fmt.Errorf("webhook %s: 推送地址必须以 http:// 或 https:// 开头 (url)", w.Name)
+	}
+	switch w.Type {
+	case WebhookTypeGeneric, WebhookTypeDingTalk, WebhookTypeWeCom, WebhookTypeFeishu:
+	default:
+		return fmt.Errorf("webhook %s: 无效的类型 %s (必须是 generic、dingtalk、wecom 或 feishu)", w.Name, w.Type)
+	}
+	for _, event := range w.Events {
+		if event != "down" && event != "up" {
+			return fmt.Errorf("webhook %s: 无效的事件类型 %s (必须是 down 或 up)", w.Name, event)
+		}
+	}
+	return nil
 }
 
 // DefaultMultiSourceConfig 默认多数据源配置
@@ -81,10 +228,14 @@ var DefaultMultiSourceConfig = MultiSourceConfig{
 	// 全局默认值
 	ListenAddress:        ":9200",
 	MetricPath:           "/metrics",
+	EnableOpenMetrics:    true,
 	LogMaxSize:           10,
 	LogMaxBackups:        3,
 	LogMaxAge:            30,
 	LogLevel:             "info",
+	LogDir:               "./logs",
+	LogFormat:            LogFormatAuto,
+	LogOutput:            LogOutputBoth,
 	EncodeConfigPwd:      false,
 	EnableBasicAuth:      false,
 	BasicAuthUsername:    "",
@@ -92,11 +243,48 @@ var DefaultMultiSourceConfig = MultiSourceConfig{
 	RetryIntervalSeconds: 30,
 	EnableHealthPing:     true,
 
+	// 日志去重默认值
+	LogDedupIntervalSeconds: 60,
+
 	// 全局超时控制默认值
 	GlobalTimeoutSeconds: 5, // 默认5秒全局超时
 
 	// 采集模式默认值
 	CollectionMode: "blocking", // 默认使用阻塞模式，不丢失指标
+
+	// 抓取合并与并发控制默认值
+	ScrapeCoalesceSeconds: 2,
+	MaxConcurrentScrapes:  10,
+
+	// 优雅停机与探针默认值
+	ShutdownDrainSeconds:       5,
+	ShutdownTimeoutSeconds:     10,
+	ReadyMinHealthyDatasources: 1,
+
+	// 缓存默认值
+	CacheMaxEntries: 10000,
+
+	// 通知默认值
+	WebhookDedupSeconds: 300,
+	EventHistorySize:    200,
+
+	// 推送默认值
+	RemoteWriteIntervalSeconds: 30,
+	OTLPIntervalSeconds:        30,
+
+	// 访问控制默认值
+	AuthFailureLimit:         10,
+	AuthFailureWindowSeconds: 60,
+	AuthLockoutSeconds:       300,
+
+	// Zabbix 默认值
+	ZabbixIntervalSeconds:          60,
+	ZabbixHostLabel:                "datasource",
+	ZabbixDiscoveryIntervalSeconds: 3600,
+	ZabbixTimeoutSeconds:           10,
+
+	// 巡检报告默认阈值
+	Report: DefaultReportConfig,
 }
 
 // DefaultDataSourceConfig 默认数据源配置
@@ -126,6 +314,10 @@ var DefaultDataSourceConfig = DataSourceConfig{
 
 	// 其他默认值
 	CustomMetricsFile: "", // 默认为空，需要用户显式配置
+
+	// 拓扑自动发现默认值
+	Discovery:                false,
+	DiscoveryIntervalSeconds: 300,
 }
 
 // ApplyDefaults 填充数值型和描述性字段的默认值，不会覆盖布尔开关。
@@ -157,6 +349,9 @@ func (ds *DataSourceConfig) applyDefaults() {
 	if ds.SlowSqlMaxRows == 0 {
 		ds.SlowSqlMaxRows = DefaultDataSourceConfig.SlowSqlMaxRows
 	}
+	if ds.DiscoveryIntervalSeconds == 0 {
+		ds.DiscoveryIntervalSeconds = DefaultDataSourceConfig.DiscoveryIntervalSeconds
+	}
 	// CustomMetricsFile 不设置默认值，保持用户配置的原样
 	// 如果用户没配置，就是空字符串
 	if ds.Description == "" {
@@ -184,18 +379,43 @@ func (ds *DataSourceConfig) Validate() error {
 	if ds.MaxOpenConns < 1 || ds.MaxOpenConns > 100 {
 		return fmt.Errorf("数据源 %s: 最大打开连接数必须在 1-100 之间 (maxOpenConns)", ds.Name)
 	}
+	if ds.LogLevel != "" && !isValidLogLevel(ds.LogLevel) {
+		return fmt.Errorf("数据源 %s: 无效的日志级别: %s (必须是 'debug'、'info'、'warn' 或 'error') (logLevel)", ds.Name, ds.LogLevel)
+	}
+	if ds.Discovery {
+		if ds.DiscoveryIntervalSeconds < 10 {
+			return fmt.Errorf("数据源 %s: 发现周期不能小于 10 秒 (discoveryIntervalSeconds)", ds.Name)
+		}
+		if ds.DiscoveryPort < 0 || ds.DiscoveryPort > 65535 {
+			return fmt.Errorf("数据源 %s: 成员端口必须在 0-65535 之间 (discoveryPort)", ds.Name)
+		}
+	}
 
 	return nil
 }
 
+// isValidLogLevel 判断日志级别是否有效
+func isValidLogLevel(level string) bool {
+	switch strings.ToLower(level) {
+	case "debug", "info", "warn", "error":
+		return true
+	}
+	return false
+}
+
 // ParseLabels 解析标签字符串
 func (ds *DataSourceConfig) ParseLabels() map[string]string {
+	return parseLabelString(ds.Labels)
+}
+
+// parseLabelString 解析 "key1=val1,key2=val2" 格式的标签字符串，忽略格式不正确的项
+func parseLabelString(s string) map[string]string {
 	labels := make(map[string]string)
-	if ds.Labels == "" {
+	if s == "" {
 		return labels
 	}
 
-	pairs := strings.Split(ds.Labels, ",")
+	pairs := strings.Split(s, ",")
 	for _, pair := range pairs {
 		parts := strings.SplitN(strings.TrimSpace(pair), "=", 2)
 		if len(parts) == 2 {
@@ -211,9 +431,10 @@ func (ds *DataSourceConfig) ParseLabels() map[string]string {
 
 // GetDataSourceByName 根据名称获取数据源配置
 func (msc *MultiSourceConfig) GetDataSourceByName(name string) *DataSourceConfig {
-	for i := range msc.DataSources {
-		if msc.DataSources[i].Name == name {
-			return &msc.DataSources[i]
+	dataSources := msc.DataSourceList()
+	for i := range dataSources {
+		if dataSources[i].Name == name {
+			return &dataSources[i]
 		}
 	}
 	return nil
@@ -229,11 +450,66 @@ func (msc *MultiSourceConfig) ValidateAll() error {
 		return fmt.Errorf("指标路径不能为空 (metricPath)")
 	}
 
+	// 验证日志配置
+	switch msc.LogFormat {
+	case LogFormatAuto, LogFormatJSON, LogFormatConsole, LogFormatLogfmt:
+	default:
+		return fmt.Errorf("无效的日志格式: %s (必须是 'auto'、'json'、'console' 或 'logfmt')", msc.LogFormat)
+	}
+	switch msc.LogOutput {
+	case LogOutputFile, LogOutputStdout, LogOutputBoth:
+	default:
+		return fmt.Errorf("无效的日志输出位置: %s (必须是 'file'、'stdout' 或 'both')", msc.LogOutput)
+	}
+
 	// 验证采集模式
 	if msc.CollectionMode != "" && msc.CollectionMode != "blocking" && msc.CollectionMode != "fast" {
 		return fmt.Errorf("无效的采集模式: %s (必须是 'blocking' 或 'fast')", msc.CollectionMode)
 	}
 
+	// 验证 Webhook 配置
+	for i := range msc.Webhooks {
+		if err := msc.Webhooks[i].Validate(); err != nil {
+			return err
+		}
+	}
+
+	// 验证 remote_write 配置
+	for i := range msc.RemoteWrite {
+		if err := msc.RemoteWrite[i].Validate(); err != nil {
+			return err
+		}
+	}
+
+	// 验证 OTLP 配置
+	for i := range msc.OTLP {
+		if err := msc.OTLP[i].Validate(); err != nil {
+			return err
+		}
+	}
+
+	// 验证巡检阈值
+	if err := msc.Report.Validate(); err != nil {
+		return err
+	}
+
+	// 验证访问控制配置
+	for i := range msc.AccessRules {
+		if err := msc.AccessRules[i].Validate(); err != nil {
+			return err
+		}
+	}
+	if _, err := ParseCIDRs(msc.TrustedProxies); err != nil {
+		return fmt.Errorf("%w (trustedProxies)", err)
+	}
+
+	// 验证 Zabbix 配置
+	if msc.ZabbixServer != "" {
+		if _, _, err := net.SplitHostPort(msc.ZabbixServer); err != nil {
+			return fmt.Errorf("无效的 Zabbix 地址 %s，格式应为 host:port (zabbixServer)", msc.ZabbixServer)
+		}
+	}
+
 	// 验证数据源配置
 	if len(msc.DataSources) == 0 {
 		return fmt.Errorf("至少需要配置一个数据源")
@@ -298,6 +574,20 @@ func (msc *MultiSourceConfig) ApplyAllDefaults() {
 	if msc.LogLevel == "" {
 		msc.LogLevel = DefaultMultiSourceConfig.LogLevel
 	}
+	if msc.LogDir == "" {
+		msc.LogDir = DefaultMultiSourceConfig.LogDir
+	}
+	if msc.LogFormat == "" {
+		msc.LogFormat = DefaultMultiSourceConfig.LogFormat
+	}
+	msc.LogFormat = strings.ToLower(msc.LogFormat)
+	if msc.LogOutput == "" {
+		msc.LogOutput = DefaultMultiSourceConfig.LogOutput
+	}
+	msc.LogOutput = strings.ToLower(msc.LogOutput)
+	if msc.LogDedupIntervalSeconds < 0 {
+		msc.LogDedupIntervalSeconds = DefaultMultiSourceConfig.LogDedupIntervalSeconds
+	}
 
 	// 应用全局超时控制默认值
 	if msc.GlobalTimeoutSeconds == 0 {
@@ -311,10 +601,97 @@ func (msc *MultiSourceConfig) ApplyAllDefaults() {
 	if msc.RetryIntervalSeconds == 0 {
 		msc.RetryIntervalSeconds = DefaultMultiSourceConfig.RetryIntervalSeconds
 	}
+
+	// 抓取合并与并发上限允许显式配置为0（关闭），仅修正非法的负值
+	if msc.ScrapeCoalesceSeconds < 0 {
+		msc.ScrapeCoalesceSeconds = DefaultMultiSourceConfig.ScrapeCoalesceSeconds
+	}
+	if msc.MaxConcurrentScrapes < 0 {
+		msc.MaxConcurrentScrapes = DefaultMultiSourceConfig.MaxConcurrentScrapes
+	}
 	if !msc.healthPingConfigured {
 		msc.EnableHealthPing = DefaultMultiSourceConfig.EnableHealthPing
 	}
 
+	if msc.CacheMaxEntries <= 0 {
+		msc.CacheMaxEntries = DefaultMultiSourceConfig.CacheMaxEntries
+	}
+
+	// 去重窗口允许显式配置为0（不去重），仅修正非法的负值
+	if msc.WebhookDedupSeconds < 0 {
+		msc.WebhookDedupSeconds = DefaultMultiSourceConfig.WebhookDedupSeconds
+	}
+	if msc.EventHistorySize <= 0 {
+		msc.EventHistorySize = DefaultMultiSourceConfig.EventHistorySize
+	}
+	for i := range msc.Webhooks {
+		msc.Webhooks[i].applyDefaults()
+		if msc.Webhooks[i].Name == "" {
+			msc.Webhooks[i].Name = fmt.Sprintf("webhook-%d", i+1)
+		}
+	}
+
+	if msc.RemoteWriteIntervalSeconds <= 0 {
+		msc.RemoteWriteIntervalSeconds = DefaultMultiSourceConfig.RemoteWriteIntervalSeconds
+	}
+	for i := range msc.RemoteWrite {
+		msc.RemoteWrite[i].applyDefaults()
+		if msc.RemoteWrite[i].Name == "" {
+			msc.RemoteWrite[i].Name = fmt.Sprintf("remote-write-%d", i+1)
+		}
+	}
+
+	if msc.OTLPIntervalSeconds <= 0 {
+		msc.OTLPIntervalSeconds = DefaultMultiSourceConfig.OTLPIntervalSeconds
+	}
+	for i := range msc.OTLP {
+		msc.OTLP[i].applyDefaults()
+		if msc.OTLP[i].Name == "" {
+			msc.OTLP[i].Name = fmt.Sprintf("otlp-%d", i+1)
+		}
+	}
+
+	for i := range msc.AccessRules {
+		if msc.AccessRules[i].Name == "" {
+			msc.AccessRules[i].Name = fmt.Sprintf("access-rule-%d", i+1)
+		}
+	}
+	// 失败次数允许显式配置为0（不限制），仅修正非法的负值
+	if msc.AuthFailureLimit < 0 {
+		msc.AuthFailureLimit = DefaultMultiSourceConfig.AuthFailureLimit
+	}
+	if msc.AuthFailureWindowSeconds <= 0 {
+		msc.AuthFailureWindowSeconds = DefaultMultiSourceConfig.AuthFailureWindowSeconds
+	}
+	if msc.AuthLockoutSeconds <= 0 {
+		msc.AuthLockoutSeconds = DefaultMultiSourceConfig.AuthLockoutSeconds
+	}
+
+	if msc.ZabbixIntervalSeconds <= 0 {
+		msc.ZabbixIntervalSeconds = DefaultMultiSourceConfig.ZabbixIntervalSeconds
+	}
+	if msc.ZabbixHostLabel == "" {
+		msc.ZabbixHostLabel = DefaultMultiSourceConfig.ZabbixHostLabel
+	}
+	// 发现数据推送周期允许显式配置为0（不推送），仅修正非法的负值
+	if msc.ZabbixDiscoveryIntervalSeconds < 0 {
+		msc.ZabbixDiscoveryIntervalSeconds = DefaultMultiSourceConfig.ZabbixDiscoveryIntervalSeconds
+	}
+	if msc.ZabbixTimeoutSeconds <= 0 {
+		msc.ZabbixTimeoutSeconds = DefaultMultiSourceConfig.ZabbixTimeoutSeconds
+	}
+
+	// 停机与探针参数允许显式配置为0，仅修正非法的负值
+	if msc.ShutdownDrainSeconds < 0 {
+		msc.ShutdownDrainSeconds = DefaultMultiSourceConfig.ShutdownDrainSeconds
+	}
+	if msc.ShutdownTimeoutSeconds < 0 {
+		msc.ShutdownTimeoutSeconds = DefaultMultiSourceConfig.ShutdownTimeoutSeconds
+	}
+	if msc.ReadyMinHealthyDatasources < 0 {
+		msc.ReadyMinHealthyDatasources = DefaultMultiSourceConfig.ReadyMinHealthyDatasources
+	}
+
 	// 为每个数据源应用默认值
 	for i := range msc.DataSources {
 		msc.DataSources[i].applyDefaults()
@@ -328,41 +705,88 @@ func (msc *MultiSourceConfig) StringCategorized() string {
 	sb.WriteString("\n========== Configuration Summary ==========\n")
 
 	// 服务配置 - 使用完整参数名
-	sb.WriteString(fmt.Sprintf("[Service] listenAddress=%s, metricPath=%s, version=%s\n",
-		msc.ListenAddress, msc.MetricPath, msc.Version))
+	sb.WriteString(fmt.Sprintf("[Service] listenAddress=%s, metricPath=%s, version=%s, enableOpenMetrics=%v\n",
+		msc.ListenAddress, msc.MetricPath, msc.Version, msc.EnableOpenMetrics))
 
 	// 日志配置 - 使用完整参数名
-	sb.WriteString(fmt.Sprintf("[Logging] logLevel=%s, logMaxSize=%dMB, logMaxBackups=%d, logMaxAge=%d days\n",
-		msc.LogLevel, msc.LogMaxSize, msc.LogMaxBackups, msc.LogMaxAge))
+	sb.WriteString(fmt.Sprintf("[Logging] logLevel=%s, logOutput=%s, logFormat=%s, logDir=%s, logMaxSize=%dMB, logMaxBackups=%d, logMaxAge=%d days, logDedupIntervalSeconds=%ds\n",
+		msc.LogLevel, msc.LogOutput, msc.LogFormat, msc.LogDir, msc.LogMaxSize, msc.LogMaxBackups, msc.LogMaxAge, msc.LogDedupIntervalSeconds))
 
 	// 安全配置 - 使用完整参数名
 	authInfo := fmt.Sprintf("enableBasicAuth=%v", msc.EnableBasicAuth)
 	if msc.EnableBasicAuth {
 		authInfo += fmt.Sprintf(", basicAuthUsername=%s", msc.BasicAuthUsername)
 	}
-	sb.WriteString(fmt.Sprintf("[Security] %s, encodeConfigPwd=%v\n",
-		authInfo, msc.EncodeConfigPwd))
+	authInfo += fmt.Sprintf(", basicAuthUsersFile=%s, bearerTokensFile=%s, basicAuthLegacyHashCompare=%v",
+		msc.BasicAuthUsersFile, msc.BearerTokensFile, msc.BasicAuthLegacyHashCompare)
+	sb.WriteString(fmt.Sprintf("[Security] %s, encodeConfigPwd=%v, enableAdminApi=%v\n",
+		authInfo, msc.EncodeConfigPwd, msc.EnableAdminAPI))
+	ruleNames := make([]string, 0, len(msc.AccessRules))
+	for _, rule := range msc.AccessRules {
+		ruleNames = append(ruleNames, rule.Name)
+	}
+	sb.WriteString(fmt.Sprintf("[Access] accessRules=%d [%s], trustedProxies=[%s], authFailureLimit=%d, authFailureWindowSeconds=%ds, authLockoutSeconds=%ds\n",
+		len(msc.AccessRules), strings.Join(ruleNames, ", "), strings.Join(msc.TrustedProxies, ", "),
+		msc.AuthFailureLimit, msc.AuthFailureWindowSeconds, msc.AuthLockoutSeconds))
 
 	// 性能配置 - 使用完整参数名
-	sb.WriteString(fmt.Sprintf("[Performance] globalTimeoutSeconds=%ds, collectionMode=%s, retryIntervalSeconds=%ds, enableHealthPing=%v\n",
-		msc.GlobalTimeoutSeconds, msc.CollectionMode, msc.RetryIntervalSeconds, msc.IsHealthPingEnabled()))
-
-	// 数据源摘要 - 一行
+	sb.WriteString(fmt.Sprintf("[Performance] globalTimeoutSeconds=%ds, collectionMode=%s, retryIntervalSeconds=%ds, enableHealthPing=%v, scrapeCoalesceSeconds=%ds, maxConcurrentScrapes=%d\n",
+		msc.GlobalTimeoutSeconds, msc.CollectionMode, msc.RetryIntervalSeconds, msc.IsHealthPingEnabled(), msc.ScrapeCoalesceSeconds, msc.MaxConcurrentScrapes))
+
+	// 停机与探针配置 - 使用完整参数名
+	sb.WriteString(fmt.Sprintf("[Lifecycle] shutdownDrainSeconds=%ds, shutdownTimeoutSeconds=%ds, readyMinHealthyDatasources=%d\n",
+		msc.ShutdownDrainSeconds, msc.ShutdownTimeoutSeconds, msc.ReadyMinHealthyDatasources))
+
+	// 缓存与告警状态持久化配置
+	sb.WriteString(fmt.Sprintf("[State] cacheMaxEntries=%d, alarmStateFile=%s\n", msc.CacheMaxEntries, msc.AlarmStateFile))
+
+	// 通知配置 - 仅输出名称与类型，不输出地址与密钥
+	var webhookNames []string
+	for _, w := range msc.Webhooks {
+		webhookNames = append(webhookNames, fmt.Sprintf("%s(%s)", w.Name, w.Type))
+	}
+	sb.WriteString(fmt.Sprintf("[Notify] webhooks=%d (%s), webhookDedupSeconds=%ds, eventHistorySize=%d\n",
+		len(msc.Webhooks), strings.Join(webhookNames, ", "), msc.WebhookDedupSeconds, msc.EventHistorySize))
+
+	// 推送配置 - 仅输出名称，不输出地址与凭据
+	var remoteWriteNames []string
+	for _, r := range msc.RemoteWrite {
+		remoteWriteNames = append(remoteWriteNames, r.Name)
+	}
+	var otlpNames []string
+	for _, o := range msc.OTLP {
+		otlpNames = append(otlpNames, o.Name)
+	}
+	sb.WriteString(fmt.Sprintf("[Push] remoteWrite=%d (%s), remoteWriteIntervalSeconds=%ds, otlp=%d (%s), otlpIntervalSeconds=%ds\n",
+		len(msc.RemoteWrite), strings.Join(remoteWriteNames, ", "), msc.RemoteWriteIntervalSeconds,
+		len(msc.OTLP), strings.Join(otlpNames, ", "), msc.OTLPIntervalSeconds))
+	sb.WriteString(fmt.Sprintf("[Zabbix] zabbixServer=%s, zabbixIntervalSeconds=%ds, zabbixHostLabel=%s, zabbixDiscoveryIntervalSeconds=%ds, zabbixTimeoutSeconds=%ds\n",
+		msc.ZabbixServer, msc.ZabbixIntervalSeconds, msc.ZabbixHostLabel, msc.ZabbixDiscoveryIntervalSeconds, msc.ZabbixTimeoutSeconds))
+	sb.WriteString(fmt.Sprintf("[Report] tablespaceUsage=%v%%/%v%%, license=%d/%d days, userExpiry=%d/%d days, archiveRequired=%v, redoSwitch=%d/%d minutes, errorLog=%d/%d, bufferPoolHit=%v%%/%v%% (warn/fail)\n",
+		msc.Report.TablespaceUsageWarnPercent, msc.Report.TablespaceUsageFailPercent,
+		msc.Report.LicenseWarnDays, msc.Report.LicenseFailDays,
+		msc.Report.UserExpiryWarnDays, msc.Report.UserExpiryFailDays, msc.Report.ArchiveRequired,
+		msc.Report.RedoSwitchWarnMinutes, msc.Report.RedoSwitchFailMinutes,
+		msc.Report.ErrorLogWarnCount, msc.Report.ErrorLogFailCount,
+		msc.Report.BufferPoolHitWarnPercent, msc.Report.BufferPoolHitFailPercent))
+
+	// 数据源摘要 - 一行（运行时可能被管理接口修改，使用当前列表快照）
+	dataSources := msc.DataSourceList()
 	enabledCount := 0
 	var dsNames []string
-	for _, ds := range msc.DataSources {
+	for _, ds := range dataSources {
 		if ds.Enabled {
 			enabledCount++
 			dsNames = append(dsNames, ds.Name)
 		}
 	}
 	sb.WriteString(fmt.Sprintf("[DataSources] total=%d, enabled=%d (%s)\n",
-		len(msc.DataSources), enabledCount, strings.Join(dsNames, ", ")))
+		len(dataSources), enabledCount, strings.Join(dsNames, ", ")))
 
 	// 调试级别时输出每个数据源的详细配置
 	if strings.ToLower(msc.LogLevel) == "debug" {
 		sb.WriteString("\n---------- Debug: DataSource Details ----------\n")
-		for i, ds := range msc.DataSources {
+		for i, ds := range dataSources {
 			sb.WriteString(fmt.Sprintf("[DS-%d] %s:\n", i+1, ds.Name))
 			// 基本信息 - 使用完整参数名
 			sb.WriteString(fmt.Sprintf("  dbHost=%s, dbUser=%s, enabled=%v\n",
@@ -395,6 +819,17 @@ func (msc *MultiSourceConfig) StringCategorized() string {
 
 			// 显示自定义指标文件路径（空值表示未配置）
 			sb.WriteString(fmt.Sprintf("  customMetricsFile=%s\n", ds.CustomMetricsFile))
+
+			// 显示数据源日志级别（如果有）
+			if ds.LogLevel != "" {
+				sb.WriteString(fmt.Sprintf("  logLevel=%s\n", ds.LogLevel))
+			}
+
+			// 拓扑自动发现配置
+			if ds.Discovery {
+				sb.WriteString(fmt.Sprintf("  discovery=%v, discoveryIntervalSeconds=%ds, discoveryPort=%d, discoveryUser=%s\n",
+					ds.Discovery, ds.DiscoveryIntervalSeconds, ds.DiscoveryPort, ds.DiscoveryUser))
+			}
 		}
 		sb.WriteString("----------------------------------------------\n")
 	}
@@ -419,6 +854,11 @@ func (msc *MultiSourceConfig) GetRetryIntervalSeconds() int {
 	return msc.RetryIntervalSeconds
 }
 
+// IsAuthEnabled 返回是否启用访问认证：开启 Basic 认证或配置了用户文件、令牌文件时启用
+func (msc *MultiSourceConfig) IsAuthEnabled() bool {
+	return msc.EnableBasicAuth || msc.BasicAuthUsersFile != "" || msc.BearerTokensFile != ""
+}
+
 // IsHealthPingEnabled 返回是否启用周期性健康检查
 func (msc *MultiSourceConfig) IsHealthPingEnabled() bool {
 	if msc == nil {
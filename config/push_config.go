@@ -0,0 +1,138 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// TLSConfig 推送目标的 TLS 配置
+type TLSConfig struct {
+	CAFile             string `toml:"caFile"`             // 校验服务端证书的 CA 文件，为空时使用系统 CA
+	CertFile           string `toml:"certFile"`           // 客户端证书文件（双向认证），需与 keyFile 同时配置
+	KeyFile            string `toml:"keyFile"`            // 客户端私钥文件
+	ServerName         string `toml:"serverName"`         // 校验证书时使用的服务端名称，为空时取地址中的主机名
+	InsecureSkipVerify bool   `toml:"insecureSkipVerify"` // 跳过服务端证书校验，仅建议测试时使用
+}
+
+// Validate 验证 TLS 配置
+func (t *TLSConfig) Validate() error {
+	if (t.CertFile == "") != (t.KeyFile == "") {
+		return fmt.Errorf("客户端证书与私钥必须同时配置 (certFile/keyFile)")
+	}
+	return nil
+}
+
+// RemoteWriteConfig Prometheus remote_write 推送目标配置
+type RemoteWriteConfig struct {
+	Name           string    `toml:"name"`           // 名称，用于日志与自监控指标
+	URL            string    `toml:"url"`            // 推送地址，如 http://prometheus:9090/api/v1/write
+	Username       string    `toml:"username"`       // Basic 认证用户名，可选
+	Password       string    `toml:"password"`       // Basic 认证密码，支持ENC()加密格式
+	BearerToken    string    `toml:"bearerToken"`    // Bearer 令牌，支持ENC()加密格式，不能与 Basic 认证同时配置
+	TimeoutSeconds int       `toml:"timeoutSeconds"` // 单次请求超时（秒）
+	QueueSize      int       `toml:"queueSize"`      // 发送失败时最多缓存的批次数量，超出后丢弃最旧的批次
+	QueueDir       string    `toml:"queueDir"`       // 磁盘队列目录，为空时仅缓存在内存中，重启后丢失
+	ExternalLabels string    `toml:"externalLabels"` // 附加到所有序列的标签，格式: "key1=val1,key2=val2"
+	TLS            TLSConfig `toml:"tls"`            // TLS 配置
+}
+
+// DefaultRemoteWriteConfig 默认 remote_write 配置
+var DefaultRemoteWriteConfig = RemoteWriteConfig{
+	TimeoutSeconds: 10,
+	QueueSize:      120,
+}
+
+// applyDefaults 为 remote_write 目标应用默认值
+func (r *RemoteWriteConfig) applyDefaults() {
+	if r.TimeoutSeconds <= 0 {
+		r.TimeoutSeconds = DefaultRemoteWriteConfig.TimeoutSeconds
+	}
+	if r.QueueSize <= 0 {
+		r.QueueSize = DefaultRemoteWriteConfig.QueueSize
+	}
+}
+
+// Validate 验证 remote_write 目标配置
+func (r *RemoteWriteConfig) Validate() error {
+	if r.URL == "" {
+		return fmt.Errorf("remoteWrite %s: 推送地址不能为空 (url)", r.Name)
+	}
+	if !strings.HasPrefix(r.URL, "http://") && !strings.HasPrefix(r.URL, "https://") {
+		return fmt.Errorf("remoteWrite %s: 推送地址必须以 http:// 或 https:// 开头 (url)", r.Name)
+	}
+	if r.BearerToken != "" && (r.Username != "" || r.Password != "") {
+		return fmt.Errorf("remoteWrite %s: Basic 认证与 Bearer 令牌不能同时配置 (username/password/bearerToken)", r.Name)
+	}
+	if err := r.TLS.Validate(); err != nil {
+		return fmt.Errorf("remoteWrite %s: %w", r.Name, err)
+	}
+	return nil
+}
+
+// ParseExternalLabels 解析附加标签
+func (r *RemoteWriteConfig) ParseExternalLabels() map[string]string {
+	return parseLabelString(r.ExternalLabels)
+}
+
+// OTLP 压缩方式
+const (
+	OTLPCompressionGzip = "gzip" // gzip 压缩
+	OTLPCompressionNone = "none" // 不压缩
+)
+
+// OTLPConfig OpenTelemetry OTLP/HTTP 指标导出目标配置
+type OTLPConfig struct {
+	Name               string            `toml:"name"`               // 名称，用于日志与自监控指标
+	Endpoint           string            `toml:"endpoint"`           // 导出地址，如 http://otel-collector:4318/v1/metrics
+	Headers            map[string]string `toml:"headers"`            // 附加请求头（如认证信息），值支持ENC()加密格式
+	Compression        string            `toml:"compression"`        // 压缩方式：gzip/none
+	TimeoutSeconds     int               `toml:"timeoutSeconds"`     // 单次请求超时（秒）
+	MaxRetries         int               `toml:"maxRetries"`         // 失败重试次数
+	ResourceAttributes string            `toml:"resourceAttributes"` // 附加到所有 Resource 的属性，格式: "key1=val1,key2=val2"
+	TLS                TLSConfig         `toml:"tls"`                // TLS 配置
+}
+
+// DefaultOTLPConfig 默认 OTLP 配置
+var DefaultOTLPConfig = OTLPConfig{
+	Compression:    OTLPCompressionGzip,
+	TimeoutSeconds: 10,
+	MaxRetries:     3,
+}
+
+// applyDefaults 为 OTLP 目标应用默认值
+func (o *OTLPConfig) applyDefaults() {
+	if o.Compression == "" {
+		o.Compression = DefaultOTLPConfig.Compression
+	}
+	o.Compression = strings.ToLower(o.Compression)
+	if o.TimeoutSeconds <= 0 {
+		o.TimeoutSeconds = DefaultOTLPConfig.TimeoutSeconds
+	}
+	if o.MaxRetries <= 0 {
+		o.MaxRetries = DefaultOTLPConfig.MaxRetries
+	}
+}
+
+// Validate 验证 OTLP 目标配置
+func (o *OTLPConfig) Validate() error {
+	if o.Endpoint == "" {
+		return fmt.Errorf("otlp %s: 导出地址不能为空 (endpoint)", o.Name)
+	}
+	if !strings.HasPrefix(o.Endpoint, "http://") && !strings.HasPrefix(o.Endpoint, "https://") {
+		return fmt.Errorf("otlp %s: 导出地址必须以 http:// 或 https:// 开头，仅支持 OTLP/HTTP (endpoint)", o.Name)
+	}
+	switch o.Compression {
+	case OTLPCompressionGzip, OTLPCompressionNone:
+	default:
+		return fmt.Errorf("otlp %s: 无效的压缩方式 %s (必须是 gzip 或 none)", o.Name, o.Compression)
+	}
+	if err := o.TLS.Validate(); err != nil {
+		return fmt.Errorf("otlp %s: %w", o.Name, err)
+	}
+	return nil
+}
+
+// ParseResourceAttributes 解析附加的 Resource 属性
+func (o *OTLPConfig) ParseResourceAttributes() map[string]string {
+	return parseLabelString(o.ResourceAttributes)
+}
@@ -0,0 +1,146 @@
+package config
+
+import "fmt"
+
+// ReportConfig 巡检报告的检查阈值，阈值为0表示不判定对应级别
+type ReportConfig struct {
+	TablespaceUsageWarnPercent float64 `toml:"tablespaceUsageWarnPercent"` // 表空间使用率达到该值判定为警告（%）
+	TablespaceUsageFailPercent float64 `toml:"tablespaceUsageFailPercent"` // 表空间使用率达到该值判定为失败（%）
+	LicenseWarnDays            int     `toml:"licenseWarnDays"`            // 授权剩余天数不超过该值判定为警告
+	LicenseFailDays            int     `toml:"licenseFailDays"`            // 授权剩余天数不超过该值判定为失败
+	UserExpiryWarnDays         int     `toml:"userExpiryWarnDays"`         // 用户密码剩余有效天数不超过该值判定为警告
+	UserExpiryFailDays         int     `toml:"userExpiryFailDays"`         // 用户密码剩余有效天数不超过该值判定为失败
+	ArchiveRequired            bool    `toml:"archiveRequired"`            // 未开启归档时判定为失败，否则判定为通过
+	RedoSwitchWarnMinutes      int     `toml:"redoSwitchWarnMinutes"`      // 距最近一次 redo 日志切换超过该时间判定为警告（分钟）
+	RedoSwitchFailMinutes      int     `toml:"redoSwitchFailMinutes"`      // 距最近一次 redo 日志切换超过该时间判定为失败（分钟）
+	ErrorLogWarnCount          int     `toml:"errorLogWarnCount"`          // 最近5分钟实例错误日志条数达到该值判定为警告
+	ErrorLogFailCount          int     `toml:"errorLogFailCount"`          // 最近5分钟实例错误日志条数达到该值判定为失败
+	BufferPoolHitWarnPercent   float64 `toml:"bufferPoolHitWarnPercent"`   // 缓冲池命中率低于该值判定为警告（%）
+	BufferPoolHitFailPercent   float64 `toml:"bufferPoolHitFailPercent"`   // 缓冲池命中率低于该值判定为失败（%）
+}
+
+// DefaultReportConfig 默认巡检阈值
+var DefaultReportConfig = ReportConfig{
+	TablespaceUsageWarnPercent: 80,
+	TablespaceUsageFailPercent: 90,
+	LicenseWarnDays:            60,
+	LicenseFailDays:            15,
+	UserExpiryWarnDays:         30,
+	UserExpiryFailDays:         7,
+	ArchiveRequired:            true,
+	RedoSwitchWarnMinutes:      1440,
+	RedoSwitchFailMinutes:      0,
+	ErrorLogWarnCount:          1,
+	ErrorLogFailCount:          10,
+	BufferPoolHitWarnPercent:   95,
+	BufferPoolHitFailPercent:   90,
+}
+
+// rawReportConfig 对应配置文件中的 [report]，使用指针字段区分未配置与配置为0
+type rawReportConfig struct {
+	TablespaceUsageWarnPercent *float64 `toml:"tablespaceUsageWarnPercent"`
+	TablespaceUsageFailPercent *float64 `toml:"tablespaceUsageFailPercent"`
+	LicenseWarnDays            *int     `toml:"licenseWarnDays"`
+	LicenseFailDays            *int     `toml:"licenseFailDays"`
+	UserExpiryWarnDays         *int     `toml:"userExpiryWarnDays"`
+	UserExpiryFailDays         *int     `toml:"userExpiryFailDays"`
+	ArchiveRequired            *bool    `toml:"archiveRequired"`
+	RedoSwitchWarnMinutes      *int     `toml:"redoSwitchWarnMinutes"`
+	RedoSwitchFailMinutes      *int     `toml:"redoSwitchFailMinutes"`
+	ErrorLogWarnCount          *int     `toml:"errorLogWarnCount"`
+	ErrorLogFailCount          *int     `toml:"errorLogFailCount"`
+	BufferPoolHitWarnPercent   *float64 `toml:"bufferPoolHitWarnPercent"`
+	BufferPoolHitFailPercent   *float64 `toml:"bufferPoolHitFailPercent"`
+}
+
+// toConfig 将配置的阈值覆盖到默认值上
+func (raw *rawReportConfig) toConfig() ReportConfig {
+	cfg := DefaultReportConfig
+	if raw == nil {
+		return cfg
+	}
+	setFloat := func(dst *float64, src *float64) {
+		if src != nil {
+			*dst = *src
+		}
+	}
+	setInt := func(dst *int, src *int) {
+		if src != nil {
+			*dst = *src
+		}
+	}
+	setFloat(&cfg.TablespaceUsageWarnPercent, raw.TablespaceUsageWarnPercent)
+	setFloat(&cfg.TablespaceUsageFailPercent, raw.TablespaceUsageFailPercent)
+	setInt(&cfg.LicenseWarnDays, raw.LicenseWarnDays)
+	setInt(&cfg.LicenseFailDays, raw.LicenseFailDays)
+	setInt(&cfg.UserExpiryWarnDays, raw.UserExpiryWarnDays)
+	setInt(&cfg.UserExpiryFailDays, raw.UserExpiryFailDays)
+	if raw.ArchiveRequired != nil {
+		cfg.ArchiveRequired = *raw.ArchiveRequired
+	}
+	setInt(&cfg.RedoSwitchWarnMinutes, raw.RedoSwitchWarnMinutes)
+	setInt(&cfg.RedoSwitchFailMinutes, raw.RedoSwitchFailMinutes)
+	setInt(&cfg.ErrorLogWarnCount, raw.ErrorLogWarnCount)
+	setInt(&cfg.ErrorLogFailCount, raw.ErrorLogFailCount)
+	setFloat(&cfg.BufferPoolHitWarnPercent, raw.BufferPoolHitWarnPercent)
+	setFloat(&cfg.BufferPoolHitFailPercent, raw.BufferPoolHitFailPercent)
+	return cfg
+}
+
+// Validate 验证巡检阈值：不能为负数，百分比不超过100，同时配置警告与失败阈值时失败阈值应更严格
+func (r *ReportConfig) Validate() error {
+	percents := []struct {
+		name  string
+		value float64
+	}{
+		{"tablespaceUsageWarnPercent", r.TablespaceUsageWarnPercent},
+		{"tablespaceUsageFailPercent", r.TablespaceUsageFailPercent},
+		{"bufferPoolHitWarnPercent", r.BufferPoolHitWarnPercent},
+		{"bufferPoolHitFailPercent", r.BufferPoolHitFailPercent},
+	}
+	for _, p := range percents {
+		if p.value < 0 || p.value > 100 {
+			return fmt.Errorf("巡检阈值必须在0到100之间: %v (report.%s)", p.value, p.name)
+		}
+	}
+	counts := []struct {
+		name  string
+		value int
+	}{
+		{"licenseWarnDays", r.LicenseWarnDays},
+		{"licenseFailDays", r.LicenseFailDays},
+		{"userExpiryWarnDays", r.UserExpiryWarnDays},
+		{"userExpiryFailDays", r.UserExpiryFailDays},
+		{"redoSwitchWarnMinutes", r.RedoSwitchWarnMinutes},
+		{"redoSwitchFailMinutes", r.RedoSwitchFailMinutes},
+		{"errorLogWarnCount", r.ErrorLogWarnCount},
+		{"errorLogFailCount", r.ErrorLogFailCount},
+	}
+	for _, c := range counts {
+		if c.value < 0 {
+			return fmt.Errorf("巡检阈值不能为负数: %d (report.%s)", c.value, c.name)
+		}
+	}
+
+	// 数值越大越严重的检查项，失败阈值不能小于警告阈值
+	if r.TablespaceUsageWarnPercent > 0 && r.TablespaceUsageFailPercent > 0 && r.TablespaceUsageFailPercent < r.TablespaceUsageWarnPercent {
+		return fmt.Errorf("表空间使用率失败阈值不能小于警告阈值 (report.tablespaceUsageFailPercent)")
+	}
+	if r.RedoSwitchWarnMinutes > 0 && r.RedoSwitchFailMinutes > 0 && r.RedoSwitchFailMinutes < r.RedoSwitchWarnMinutes {
+		return fmt.Errorf("redo 日志切换失败阈值不能小于警告阈值 (report.redoSwitchFailMinutes)")
+	}
+	if r.ErrorLogWarnCount > 0 && r.ErrorLogFailCount > 0 && r.ErrorLogFailCount < r.ErrorLogWarnCount {
+		return fmt.Errorf("实例错误日志失败阈值不能小于警告阈值 (report.errorLogFailCount)")
+	}
+	// 数值越小越严重的检查项，失败阈值不能大于警告阈值
+	if r.LicenseWarnDays > 0 && r.LicenseFailDays > r.LicenseWarnDays {
+		return fmt.Errorf("授权剩余天数失败阈值不能大于警告阈值 (report.licenseFailDays)")
+	}
+	if r.UserExpiryWarnDays > 0 && r.UserExpiryFailDays > r.UserExpiryWarnDays {
+		return fmt.Errorf("用户密码有效期失败阈值不能大于警告阈值 (report.userExpiryFailDays)")
+	}
+	if r.BufferPoolHitWarnPercent > 0 && r.BufferPoolHitFailPercent > r.BufferPoolHitWarnPercent {
+		return fmt.Errorf("缓冲池命中率失败阈值不能大于警告阈值 (report.bufferPoolHitFailPercent)")
+	}
+	return nil
+}
@@ -56,22 +56,56 @@ func LoadMultiSourceConfig(configFile string) (*MultiSourceConfig, error) {
 
 // rawMultiSourceConfig 对应配置文件的原始映射，使用指针布尔字段以保留“是否显式配置”信息。
 type rawMultiSourceConfig struct {
-	ListenAddress        string                `toml:"listenAddress"`
-	MetricPath           string                `toml:"metricPath"`
-	Version              string                `toml:"version"`
-	LogMaxSize           int                   `toml:"logMaxSize"`
-	LogMaxBackups        int                   `toml:"logMaxBackups"`
-	LogMaxAge            int                   `toml:"logMaxAge"`
-	LogLevel             string                `toml:"logLevel"`
-	EncodeConfigPwd      bool                  `toml:"encodeConfigPwd"`
-	EnableBasicAuth      bool                  `toml:"enableBasicAuth"`
-	BasicAuthUsername    string                `toml:"basicAuthUsername"`
-	BasicAuthPassword    string                `toml:"basicAuthPassword"`
-	GlobalTimeoutSeconds int                   `toml:"globalTimeoutSeconds"`
-	CollectionMode       string                `toml:"collectionMode"`
-	RetryIntervalSeconds int                   `toml:"retryIntervalSeconds"`
-	EnableHealthPing     *bool                 `toml:"enableHealthPing"`
-	DataSources          []rawDataSourceConfig `toml:"datasource"`
+	ListenAddress              string                `toml:"listenAddress"`
+	MetricPath                 string                `toml:"metricPath"`
+	Version                    string                `toml:"version"`
+	EnableOpenMetrics          *bool                 `toml:"enableOpenMetrics"`
+	LogMaxSize                 int                   `toml:"logMaxSize"`
+	LogMaxBackups              int                   `toml:"logMaxBackups"`
+	LogMaxAge                  int                   `toml:"logMaxAge"`
+	LogLevel                   string                `toml:"logLevel"`
+	LogDir                     string                `toml:"logDir"`
+	LogFormat                  string                `toml:"logFormat"`
+	LogOutput                  string                `toml:"logOutput"`
+	LogDedupIntervalSeconds    *int                  `toml:"logDedupIntervalSeconds"`
+	EncodeConfigPwd            bool                  `toml:"encodeConfigPwd"`
+	EnableBasicAuth            bool                  `toml:"enableBasicAuth"`
+	BasicAuthUsername          string                `toml:"basicAuthUsername"`
+	BasicAuthPassword          string                `toml:"basicAuthPassword"`
+	BasicAuthUsersFile         string                `toml:"basicAuthUsersFile"`
+	BearerTokensFile           string                `toml:"bearerTokensFile"`
+	BasicAuthLegacyHashCompare bool                  `toml:"basicAuthLegacyHashCompare"`
+	GlobalTimeoutSeconds       int                   `toml:"globalTimeoutSeconds"`
+	CollectionMode             string                `toml:"collectionMode"`
+	RetryIntervalSeconds       int                   `toml:"retryIntervalSeconds"`
+	EnableHealthPing           *bool                 `toml:"enableHealthPing"`
+	ScrapeCoalesceSeconds      *int                  `toml:"scrapeCoalesceSeconds"`
+	MaxConcurrentScrapes       *int                  `toml:"maxConcurrentScrapes"`
+	ShutdownDrainSeconds       *int                  `toml:"shutdownDrainSeconds"`
+	ShutdownTimeoutSeconds     *int                  `toml:"shutdownTimeoutSeconds"`
+	ReadyMinHealthyDatasources *int                  `toml:"readyMinHealthyDatasources"`
+	AlarmStateFile             string                `toml:"alarmStateFile"`
+	CacheMaxEntries            int                   `toml:"cacheMaxEntries"`
+	WebhookDedupSeconds        *int                  `toml:"webhookDedupSeconds"`
+	EventHistorySize           int                   `toml:"eventHistorySize"`
+	Webhooks                   []WebhookConfig       `toml:"webhook"`
+	RemoteWriteIntervalSeconds int                   `toml:"remoteWriteIntervalSeconds"`
+	RemoteWrite                []RemoteWriteConfig   `toml:"remoteWrite"`
+	OTLPIntervalSeconds        int                   `toml:"otlpIntervalSeconds"`
+	OTLP                       []OTLPConfig          `toml:"otlp"`
+	ZabbixServer               string                `toml:"zabbixServer"`
+	ZabbixIntervalSeconds      int                   `toml:"zabbixIntervalSeconds"`
+	ZabbixHostLabel            string                `toml:"zabbixHostLabel"`
+	ZabbixDiscoveryInterval    *int                  `toml:"zabbixDiscoveryIntervalSeconds"`
+	ZabbixTimeoutSeconds       int                   `toml:"zabbixTimeoutSeconds"`
+	Report                     *rawReportConfig      `toml:"report"`
+	EnableAdminAPI             bool                  `toml:"enableAdminApi"`
+	AccessRules                []AccessRuleConfig    `toml:"accessRule"`
+	TrustedProxies             []string              `toml:"trustedProxies"`
+	AuthFailureLimit           *int                  `toml:"authFailureLimit"`
+	AuthFailureWindowSeconds   int                   `toml:"authFailureWindowSeconds"`
+	AuthLockoutSeconds         int                   `toml:"authLockoutSeconds"`
+	DataSources                []rawDataSourceConfig `toml:"datasource"`
 }
 
 // toConfig 将原始结构转换为应用了默认值的最终配置结构。
@@ -87,6 +121,9 @@ func (raw rawMultiSourceConfig) toConfig() *MultiSourceConfig {
 	if raw.Version != "" {
 		cfg.Version = raw.Version
 	}
+	if raw.EnableOpenMetrics != nil {
+		cfg.EnableOpenMetrics = *raw.EnableOpenMetrics
+	}
 	if raw.LogMaxSize != 0 {
 		cfg.LogMaxSize = raw.LogMaxSize
 	}
@@ -99,6 +136,18 @@ func (raw rawMultiSourceConfig) toConfig() *MultiSourceConfig {
 	if raw.LogLevel != "" {
 		cfg.LogLevel = raw.LogLevel
 	}
+	if raw.LogDir != "" {
+		cfg.LogDir = raw.LogDir
+	}
+	if raw.LogFormat != "" {
+		cfg.LogFormat = raw.LogFormat
+	}
+	if raw.LogOutput != "" {
+		cfg.LogOutput = raw.LogOutput
+	}
+	if raw.LogDedupIntervalSeconds != nil {
+		cfg.LogDedupIntervalSeconds = *raw.LogDedupIntervalSeconds
+	}
 	cfg.EncodeConfigPwd = raw.EncodeConfigPwd
 	cfg.EnableBasicAuth = raw.EnableBasicAuth
 	if raw.BasicAuthUsername != "" {
@@ -107,6 +156,9 @@ func (raw rawMultiSourceConfig) toConfig() *MultiSourceConfig {
 	if raw.BasicAuthPassword != "" {
 		cfg.BasicAuthPassword = raw.BasicAuthPassword
 	}
+	cfg.BasicAuthUsersFile = raw.BasicAuthUsersFile
+	cfg.BearerTokensFile = raw.BearerTokensFile
+	cfg.BasicAuthLegacyHashCompare = raw.BasicAuthLegacyHashCompare
 	if raw.GlobalTimeoutSeconds != 0 {
 		cfg.GlobalTimeoutSeconds = raw.GlobalTimeoutSeconds
 	}
@@ -120,6 +172,66 @@ func (raw rawMultiSourceConfig) toConfig() *MultiSourceConfig {
 		cfg.EnableHealthPing = *raw.EnableHealthPing
 		cfg.healthPingConfigured = true
 	}
+	if raw.ShutdownDrainSeconds != nil {
+		cfg.ShutdownDrainSeconds = *raw.ShutdownDrainSeconds
+	}
+	if raw.ShutdownTimeoutSeconds != nil {
+		cfg.ShutdownTimeoutSeconds = *raw.ShutdownTimeoutSeconds
+	}
+	if raw.ReadyMinHealthyDatasources != nil {
+		cfg.ReadyMinHealthyDatasources = *raw.ReadyMinHealthyDatasources
+	}
+	if raw.ScrapeCoalesceSeconds != nil {
+		cfg.ScrapeCoalesceSeconds = *raw.ScrapeCoalesceSeconds
+	}
+	if raw.MaxConcurrentScrapes != nil {
+		cfg.MaxConcurrentScrapes = *raw.MaxConcurrentScrapes
+	}
+	cfg.AlarmStateFile = raw.AlarmStateFile
+	if raw.CacheMaxEntries != 0 {
+		cfg.CacheMaxEntries = raw.CacheMaxEntries
+	}
+	if raw.WebhookDedupSeconds != nil {
+		cfg.WebhookDedupSeconds = *raw.WebhookDedupSeconds
+	}
+	if raw.EventHistorySize != 0 {
+		cfg.EventHistorySize = raw.EventHistorySize
+	}
+	cfg.Webhooks = raw.Webhooks
+	if raw.RemoteWriteIntervalSeconds != 0 {
+		cfg.RemoteWriteIntervalSeconds = raw.RemoteWriteIntervalSeconds
+	}
+	cfg.RemoteWrite = raw.RemoteWrite
+	if raw.OTLPIntervalSeconds != 0 {
+		cfg.OTLPIntervalSeconds = raw.OTLPIntervalSeconds
+	}
+	cfg.OTLP = raw.OTLP
+	cfg.ZabbixServer = raw.ZabbixServer
+	if raw.ZabbixIntervalSeconds != 0 {
+		cfg.ZabbixIntervalSeconds = raw.ZabbixIntervalSeconds
+	}
+	if raw.ZabbixHostLabel != "" {
+		cfg.ZabbixHostLabel = raw.ZabbixHostLabel
+	}
+	if raw.ZabbixDiscoveryInterval != nil {
+		cfg.ZabbixDiscoveryIntervalSeconds = *raw.ZabbixDiscoveryInterval
+	}
+	if raw.ZabbixTimeoutSeconds != 0 {
+		cfg.ZabbixTimeoutSeconds = raw.ZabbixTimeoutSeconds
+	}
+	cfg.Report = raw.Report.toConfig()
+	cfg.EnableAdminAPI = raw.EnableAdminAPI
+	cfg.AccessRules = raw.AccessRules
+	cfg.TrustedProxies = raw.TrustedProxies
+	if raw.AuthFailureLimit != nil {
+		cfg.AuthFailureLimit = *raw.AuthFailureLimit
+	}
+	if raw.AuthFailureWindowSeconds != 0 {
+		cfg.AuthFailureWindowSeconds = raw.AuthFailureWindowSeconds
+	}
+	if raw.AuthLockoutSeconds != 0 {
+		cfg.AuthLockoutSeconds = raw.AuthLockoutSeconds
+	}
 
 	cfg.DataSources = make([]DataSourceConfig, len(raw.DataSources))
 	for i, dsRaw := range raw.DataSources {
@@ -131,27 +243,33 @@ func (raw rawMultiSourceConfig) toConfig() *MultiSourceConfig {
 
 // rawDataSourceConfig 保留数据源级布尔字段的显式设置情况。
 type rawDataSourceConfig struct {
-	Name                    string `toml:"name"`
-	Description             string `toml:"description"`
-	Enabled                 *bool  `toml:"enabled"`
-	DbHost                  string `toml:"dbHost"`
-	DbUser                  string `toml:"dbUser"`
-	DbPwd                   string `toml:"dbPwd"`
-	QueryTimeout            int    `toml:"queryTimeout"`
-	MaxOpenConns            int    `toml:"maxOpenConns"`
-	MaxIdleConns            int    `toml:"maxIdleConns"` // Deprecated
-	ConnMaxLifetime         int    `toml:"connMaxLifetime"`
-	BigKeyDataCacheTime     int    `toml:"bigKeyDataCacheTime"`
-	AlarmKeyCacheTime       int    `toml:"alarmKeyCacheTime"`
-	CheckSlowSQL            *bool  `toml:"checkSlowSQL"`
-	SlowSqlTime             int    `toml:"slowSqlTime"`
-	SlowSqlMaxRows          int    `toml:"slowSqlMaxRows"`
-	RegisterHostMetrics     *bool  `toml:"registerHostMetrics"`
-	RegisterDatabaseMetrics *bool  `toml:"registerDatabaseMetrics"`
-	RegisterDmhsMetrics     *bool  `toml:"registerDmhsMetrics"`
-	RegisterCustomMetrics   *bool  `toml:"registerCustomMetrics"`
-	Labels                  string `toml:"labels"`
-	CustomMetricsFile       string `toml:"customMetricsFile"`
+	Name                     string `toml:"name"`
+	Description              string `toml:"description"`
+	Enabled                  *bool  `toml:"enabled"`
+	DbHost                   string `toml:"dbHost"`
+	DbUser                   string `toml:"dbUser"`
+	DbPwd                    string `toml:"dbPwd"`
+	QueryTimeout             int    `toml:"queryTimeout"`
+	MaxOpenConns             int    `toml:"maxOpenConns"`
+	MaxIdleConns             int    `toml:"maxIdleConns"` // Deprecated
+	ConnMaxLifetime          int    `toml:"connMaxLifetime"`
+	BigKeyDataCacheTime      int    `toml:"bigKeyDataCacheTime"`
+	AlarmKeyCacheTime        int    `toml:"alarmKeyCacheTime"`
+	CheckSlowSQL             *bool  `toml:"checkSlowSQL"`
+	SlowSqlTime              int    `toml:"slowSqlTime"`
+	SlowSqlMaxRows           int    `toml:"slowSqlMaxRows"`
+	RegisterHostMetrics      *bool  `toml:"registerHostMetrics"`
+	RegisterDatabaseMetrics  *bool  `toml:"registerDatabaseMetrics"`
+	RegisterDmhsMetrics      *bool  `toml:"registerDmhsMetrics"`
+	RegisterCustomMetrics    *bool  `toml:"registerCustomMetrics"`
+	Labels                   string `toml:"labels"`
+	CustomMetricsFile        string `toml:"customMetricsFile"`
+	LogLevel                 string `toml:"logLevel"`
+	Discovery                *bool  `toml:"discovery"`
+	DiscoveryIntervalSeconds int    `toml:"discoveryIntervalSeconds"`
+	DiscoveryPort            int    `toml:"discoveryPort"`
+	DiscoveryUser            string `toml:"discoveryUser"`
+	DiscoveryPwd             string `toml:"discoveryPwd"`
 }
 
 // toConfig 将原始数据源配置转换为最终结构，并在必要时套用默认值。
@@ -204,6 +322,16 @@ func (raw rawDataSourceConfig) toConfig() DataSourceConfig {
 	}
 	cfg.Labels = raw.Labels
 	cfg.CustomMetricsFile = raw.CustomMetricsFile
+	cfg.LogLevel = strings.ToLower(strings.TrimSpace(raw.LogLevel))
+	if raw.Discovery != nil {
+		cfg.Discovery = *raw.Discovery
+	}
+	if raw.DiscoveryIntervalSeconds != 0 {
+		cfg.DiscoveryIntervalSeconds = raw.DiscoveryIntervalSeconds
+	}
+	cfg.DiscoveryPort = raw.DiscoveryPort
+	cfg.DiscoveryUser = raw.DiscoveryUser
+	cfg.DiscoveryPwd = raw.DiscoveryPwd
 
 	cfg.ApplyDefaults()
 
@@ -226,27 +354,7 @@ func MergeMultiSourceConfigFromCmdArgs(config *MultiSourceConfig, args *CmdArgs)
 	// 如果命令行指定了完整的数据库连接参数，替换配置文件中的数据源
 	if hasDbHost && hasDbUser && hasDbPwd {
 		// 使用命令行参数创建新的数据源
-		config.DataSources = []DataSourceConfig{{
-			Name:                    fmt.Sprintf("cmdline_%s", strings.Split(*args.DbHost, ":")[0]),
-			Description:             fmt.Sprintf("DataSource from command line (Host: %s)", *args.DbHost),
-			Enabled:                 true,
-			DbHost:                  *args.DbHost,
-			DbUser:                  *args.DbUser,
-			DbPwd:                   *args.DbPwd,
-			QueryTimeout:            *args.QueryTimeout,
-			MaxOpenConns:            *args.MaxOpenConns,
-			ConnMaxLifetime:         *args.ConnMaxLife,
-			BigKeyDataCacheTime:     *args.BigKeyDataCacheTime,
-			AlarmKeyCacheTime:       *args.AlarmKeyCacheTime,
-			CheckSlowSQL:            *args.CheckSlowSQL,
-			SlowSqlTime:             *args.SlowSqlTime,
-			SlowSqlMaxRows:          *args.SlowSqlMaxRows,
-			RegisterHostMetrics:     *args.RegisterHostMetrics,
-			RegisterDatabaseMetrics: *args.RegisterDatabaseMetrics,
-			RegisterDmhsMetrics:     *args.RegisterDmhsMetrics,
-			RegisterCustomMetrics:   *args.RegisterCustomMetrics,
-			CustomMetricsFile:       "", // 命令行模式下默认不使用自定义指标文件
-		}}
+		config.DataSources = []DataSourceConfig{cmdArgsDataSource(args)}
 	} else if hasDbHost || hasDbUser || hasDbPwd {
 		// 如果只指定了部分数据库连接参数，报错
 		fmt.Println("错误：数据库连接参数不完整！")
@@ -259,23 +367,9 @@ func MergeMultiSourceConfigFromCmdArgs(config *MultiSourceConfig, args *CmdArgs)
 	// 如果是命令行模式（指定了数据库参数），使用命令行的所有参数
 	// 否则保持配置文件的值不变（配置文件已经应用了默认值）
 	if hasDbHost && hasDbUser && hasDbPwd {
-		// 命令行模式：覆盖所有全局参数
-		config.ListenAddress = *args.ListenAddr
-		config.MetricPath = *args.MetricPath
-		config.LogMaxSize = *args.LogMaxSize
-		config.LogMaxBackups = *args.LogMaxBackups
-		config.LogMaxAge = *args.LogMaxAge
-		config.LogLevel = *args.LogLevel
-		config.EncodeConfigPwd = *args.EncodeConfigPwd
-		config.EnableBasicAuth = *args.EnableBasicAuth
-		config.BasicAuthUsername = *args.BasicAuthUsername
-		config.BasicAuthPassword = *args.BasicAuthPassword
-		config.GlobalTimeoutSeconds = *args.GlobalTimeoutSeconds
-		config.CollectionMode = *args.CollectionMode
-		if args.EnableHealthPing != nil {
-			config.EnableHealthPing = *args.EnableHealthPing
-			config.healthPingConfigured = true
-		}
+		// 命令行模式：覆盖所有全局参数，并与配置文件模式一样修正零值与非法的负值
+		applyCmdArgsGlobals(config, args)
+		config.ApplyAllDefaults()
 	}
 	// 配置文件模式：不覆盖，保持配置文件的值
 
@@ -290,6 +384,80 @@ func MergeMultiSourceConfigFromCmdArgs(config *MultiSourceConfig, args *CmdArgs)
 	}
 }
 
+// cmdArgsDataSource 根据命令行指定的数据库连接参数创建数据源，名称为 cmdline_<主机地址>
+func cmdArgsDataSource(args *CmdArgs) DataSourceConfig {
+	return DataSourceConfig{
+		Name:                    fmt.Sprintf("cmdline_%s", strings.Split(*args.DbHost, ":")[0]),
+		Description:             fmt.Sprintf("DataSource from command line (Host: %s)", *args.DbHost),
+		Enabled:                 true,
+		DbHost:                  *args.DbHost,
+		DbUser:                  *args.DbUser,
+		DbPwd:                   *args.DbPwd,
+		QueryTimeout:            *args.QueryTimeout,
+		MaxOpenConns:            *args.MaxOpenConns,
+		ConnMaxLifetime:         *args.ConnMaxLife,
+		BigKeyDataCacheTime:     *args.BigKeyDataCacheTime,
+		AlarmKeyCacheTime:       *args.AlarmKeyCacheTime,
+		CheckSlowSQL:            *args.CheckSlowSQL,
+		SlowSqlTime:             *args.SlowSqlTime,
+		SlowSqlMaxRows:          *args.SlowSqlMaxRows,
+		RegisterHostMetrics:     *args.RegisterHostMetrics,
+		RegisterDatabaseMetrics: *args.RegisterDatabaseMetrics,
+		RegisterDmhsMetrics:     *args.RegisterDmhsMetrics,
+		RegisterCustomMetrics:   *args.RegisterCustomMetrics,
+		CustomMetricsFile:       "", // 命令行模式下默认不使用自定义指标文件
+	}
+}
+
+// applyCmdArgsGlobals 使用命令行参数覆盖全部全局参数
+func applyCmdArgsGlobals(config *MultiSourceConfig, args *CmdArgs) {
+	config.ListenAddress = *args.ListenAddr
+	config.MetricPath = *args.MetricPath
+	config.EnableOpenMetrics = *args.EnableOpenMetrics
+	config.LogMaxSize = *args.LogMaxSize
+	config.LogMaxBackups = *args.LogMaxBackups
+	config.LogMaxAge = *args.LogMaxAge
+	config.LogLevel = *args.LogLevel
+	config.LogDir = *args.LogDir
+	config.LogFormat = *args.LogFormat
+	config.LogOutput = *args.LogOutput
+	config.LogDedupIntervalSeconds = *args.LogDedupIntervalSeconds
+	config.EncodeConfigPwd = *args.EncodeConfigPwd
+	config.EnableBasicAuth = *args.EnableBasicAuth
+	config.BasicAuthUsername = *args.BasicAuthUsername
+	config.BasicAuthPassword = *args.BasicAuthPassword
+	config.BasicAuthUsersFile = *args.BasicAuthUsersFile
+	config.BearerTokensFile = *args.BearerTokensFile
+	config.BasicAuthLegacyHashCompare = *args.BasicAuthLegacyHashCompare
+	config.GlobalTimeoutSeconds = *args.GlobalTimeoutSeconds
+	config.CollectionMode = *args.CollectionMode
+	if args.EnableHealthPing != nil {
+		config.EnableHealthPing = *args.EnableHealthPing
+		config.healthPingConfigured = true
+	}
+	config.ScrapeCoalesceSeconds = *args.ScrapeCoalesceSeconds
+	config.MaxConcurrentScrapes = *args.MaxConcurrentScrapes
+	config.ShutdownDrainSeconds = *args.ShutdownDrainSeconds
+	config.ShutdownTimeoutSeconds = *args.ShutdownTimeoutSeconds
+	config.ReadyMinHealthyDatasources = *args.ReadyMinHealthyDatasources
+	config.AlarmStateFile = *args.AlarmStateFile
+	config.CacheMaxEntries = *args.CacheMaxEntries
+	config.WebhookDedupSeconds = *args.WebhookDedupSeconds
+	config.EventHistorySize = *args.EventHistorySize
+	config.RemoteWriteIntervalSeconds = *args.RemoteWriteIntervalSeconds
+	config.OTLPIntervalSeconds = *args.OTLPIntervalSeconds
+	config.ZabbixServer = *args.ZabbixServer
+	config.ZabbixIntervalSeconds = *args.ZabbixIntervalSeconds
+	config.ZabbixHostLabel = *args.ZabbixHostLabel
+	config.ZabbixDiscoveryIntervalSeconds = *args.ZabbixDiscoveryIntervalSeconds
+	config.ZabbixTimeoutSeconds = *args.ZabbixTimeoutSeconds
+	config.EnableAdminAPI = *args.EnableAdminAPI
+	config.TrustedProxies = *args.TrustedProxies
+	config.AuthFailureLimit = *args.AuthFailureLimit
+	config.AuthFailureWindowSeconds = *args.AuthFailureWindowSeconds
+	config.AuthLockoutSeconds = *args.AuthLockoutSeconds
+}
+
 // DecryptPasswords 解密配置中的密码
 func (msc *MultiSourceConfig) DecryptPasswords() error {
 	// 解密数据源密码
@@ -301,6 +469,13 @@ func (msc *MultiSourceConfig) DecryptPasswords() error {
 			}
 			msc.DataSources[i].DbPwd = decPwd
 		}
+		if strings.HasPrefix(msc.DataSources[i].DiscoveryPwd, "ENC(") && strings.HasSuffix(msc.DataSources[i].DiscoveryPwd, ")") {
+			decPwd, err := DecryptPassword(msc.DataSources[i].DiscoveryPwd)
+			if err != nil {
+				return fmt.Errorf("failed to decrypt discovery password for datasource %s: %w", msc.DataSources[i].Name, err)
+			}
+			msc.DataSources[i].DiscoveryPwd = decPwd
+		}
 	}
 
 	// 解密Basic Auth密码
@@ -312,6 +487,51 @@ func (msc *MultiSourceConfig) DecryptPasswords() error {
 		msc.BasicAuthPassword = decPwd
 	}
 
+	// 解密 Webhook 加签密钥
+	for i := range msc.Webhooks {
+		w := &msc.Webhooks[i]
+		if strings.HasPrefix(w.Secret, "ENC(") && strings.HasSuffix(w.Secret, ")") {
+			decSecret, err := DecryptPassword(w.Secret)
+			if err != nil {
+				return fmt.Errorf("failed to decrypt secret for webhook %s: %w", w.Name, err)
+			}
+			w.Secret = decSecret
+		}
+	}
+
+	// 解密 remote_write 凭据
+	for i := range msc.RemoteWrite {
+		r := &msc.RemoteWrite[i]
+		if strings.HasPrefix(r.Password, "ENC(") && strings.HasSuffix(r.Password, ")") {
+			decPwd, err := DecryptPassword(r.Password)
+			if err != nil {
+				return fmt.Errorf("failed to decrypt password for remoteWrite %s: %w", r.Name, err)
+			}
+			r.Password = decPwd
+		}
+		if strings.HasPrefix(r.BearerToken, "ENC(") && strings.HasSuffix(r.BearerToken, ")") {
+			decToken, err := DecryptPassword(r.BearerToken)
+			if err != nil {
+				return fmt.Errorf("failed to decrypt bearer token for remoteWrite %s: %w", r.Name, err)
+			}
+			r.BearerToken = decToken
+		}
+	}
+
+	// 解密 OTLP 请求头中的凭据
+	for i := range msc.OTLP {
+		o := &msc.OTLP[i]
+		for name, value := range o.Headers {
+			if strings.HasPrefix(value, "ENC(") && strings.HasSuffix(value, ")") {
+				decValue, err := DecryptPassword(value)
+				if err != nil {
+					return fmt.Errorf("failed to decrypt header %s for otlp %s: %w", name, o.Name, err)
+				}
+				o.Headers[name] = decValue
+			}
+		}
+	}
+
 	return nil
 }
 
@@ -331,15 +551,6 @@ func SaveMultiSourceConfig(config *MultiSourceConfig, configFile string) error {
 	}
 	defer file.Close()
 
-	// 应用默认值
-	saveConfig := *config
-	saveConfig.ApplyAllDefaults()
-
-	// 编码为TOML
-	encoder := toml.NewEncoder(file)
-	if err := encoder.Encode(saveConfig); err != nil {
-		return fmt.Errorf("failed to encode config to TOML: %w", err)
-	}
-
-	return nil
+	// 应用默认值并编码为TOML
+	return WriteMultiSourceConfig(file, config, "")
 }
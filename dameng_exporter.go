@@ -1,57 +1,91 @@
 package main
 
 import (
+	"context"
 	"dameng_exporter/auth"
+	"dameng_exporter/cache"
 	"dameng_exporter/collector"
 	"dameng_exporter/config"
 	"dameng_exporter/db"
 	"dameng_exporter/logger"
+	"dameng_exporter/notify"
+	"dameng_exporter/push"
+	"dameng_exporter/web"
+	"errors"
 	"fmt"
 	"net/http"
 	"os"
+	"os/signal"
 	"strconv"
+	"syscall"
+	"time"
 
 	"github.com/alecthomas/kingpin/v2"
 	"github.com/prometheus/client_golang/prometheus"
-	"github.com/prometheus/client_golang/prometheus/promhttp"
+	dto "github.com/prometheus/client_model/go"
 	"go.uber.org/zap"
 )
 
 // Version 定义版本号
 const Version = "v1.2.4"
 
-// parseFlags 解析命令行参数
-func parseFlags() *config.CmdArgs {
+// commandArgs 子命令名称与各子命令的参数
+type commandArgs struct {
+	name    string
+	query   *queryArgs
+	collect *collectArgs
+	report  *reportArgs
+	config  *configArgs
+}
+
+// parseFlags 解析命令行参数，返回全局参数与子命令参数
+func parseFlags() (*config.CmdArgs, *commandArgs) {
+	kingpin.Command("serve", "Run the exporter (default)").Default()
+	command := &commandArgs{
+		query:   registerQueryCommand(),
+		collect: registerCollectCommand(),
+		report:  registerReportCommand(),
+		config:  registerConfigCommand(),
+	}
+
 	args := &config.CmdArgs{
-		ConfigFile:              kingpin.Flag("configFile", "Path to configuration file").Default("./dameng_exporter.toml").String(),
-		ListenAddr:              kingpin.Flag("listenAddress", "Address to listen on").Default(config.DefaultMultiSourceConfig.ListenAddress).String(),
-		MetricPath:              kingpin.Flag("metricPath", "Path for metrics").Default(config.DefaultMultiSourceConfig.MetricPath).String(),
-		DbHost:                  kingpin.Flag("dbHost", "Database Host (when specified, requires dbUser and dbPwd)").String(),
-		DbUser:                  kingpin.Flag("dbUser", "Database user (required with dbHost)").String(),
-		DbPwd:                   kingpin.Flag("dbPwd", "Database password (required with dbHost)").String(),
-		DbName:                  kingpin.Flag("dbName", "Name for the database (optional, defaults to generated name)").String(),
-		QueryTimeout:            kingpin.Flag("queryTimeout", "Timeout for queries (Second)").Default(fmt.Sprint(config.DefaultDataSourceConfig.QueryTimeout)).Int(),
-		MaxOpenConns:            kingpin.Flag("maxOpenConns", "Maximum open connections (number)").Default(fmt.Sprint(config.DefaultDataSourceConfig.MaxOpenConns)).Int(),
-		ConnMaxLife:             kingpin.Flag("connMaxLifetime", "Connection maximum lifetime (Minute)").Default(fmt.Sprint(config.DefaultDataSourceConfig.ConnMaxLifetime)).Int(),
-		CheckSlowSQL:            kingpin.Flag("checkSlowSql", "Check slow SQL,default:"+strconv.FormatBool(config.DefaultDataSourceConfig.CheckSlowSQL)).Default(strconv.FormatBool(config.DefaultDataSourceConfig.CheckSlowSQL)).Bool(),
-		SlowSqlTime:             kingpin.Flag("slowSqlTime", "Slow SQL time (Millisecond)").Default(fmt.Sprint(config.DefaultDataSourceConfig.SlowSqlTime)).Int(),
-		SlowSqlMaxRows:          kingpin.Flag("slowSqlLimitRows", "Slow SQL return limit row").Default(fmt.Sprint(config.DefaultDataSourceConfig.SlowSqlMaxRows)).Int(),
-		RegisterHostMetrics:     kingpin.Flag("registerHostMetrics", "Register host metrics,default:"+strconv.FormatBool(config.DefaultDataSourceConfig.RegisterHostMetrics)).Default(strconv.FormatBool(config.DefaultDataSourceConfig.RegisterHostMetrics)).Bool(),
-		RegisterDatabaseMetrics: kingpin.Flag("registerDatabaseMetrics", "Register database metrics,default:"+strconv.FormatBool(config.DefaultDataSourceConfig.RegisterDatabaseMetrics)).Default(strconv.FormatBool(config.DefaultDataSourceConfig.RegisterDatabaseMetrics)).Bool(),
-		RegisterDmhsMetrics:     kingpin.Flag("registerDmhsMetrics", "Register dmhs metrics,default:"+strconv.FormatBool(config.DefaultDataSourceConfig.RegisterDmhsMetrics)).Default(strconv.FormatBool(config.DefaultDataSourceConfig.RegisterDmhsMetrics)).Bool(),
-		RegisterCustomMetrics:   kingpin.Flag("registerCustomMetrics", "Register custom metrics,default:"+strconv.FormatBool(config.DefaultDataSourceConfig.RegisterCustomMetrics)).Default(strconv.FormatBool(config.DefaultDataSourceConfig.RegisterCustomMetrics)).Bool(),
-		BigKeyDataCacheTime:     kingpin.Flag("bigKeyDataCacheTime", "Big key data cache time (Minute)").Default(fmt.Sprint(config.DefaultDataSourceConfig.BigKeyDataCacheTime)).Int(),
-		AlarmKeyCacheTime:       kingpin.Flag("alarmKeyCacheTime", "Alarm key cache time (Minute)").Default(fmt.Sprint(config.DefaultDataSourceConfig.AlarmKeyCacheTime)).Int(),
-		LogMaxSize:              kingpin.Flag("logMaxSize", "Maximum log file size(MB)").Default(fmt.Sprint(config.DefaultMultiSourceConfig.LogMaxSize)).Int(),
-		LogMaxBackups:           kingpin.Flag("logMaxBackups", "Maximum log file backups (number)").Default(fmt.Sprint(config.DefaultMultiSourceConfig.LogMaxBackups)).Int(),
-		LogMaxAge:               kingpin.Flag("logMaxAge", "Maximum log file age (Day)").Default(fmt.Sprint(config.DefaultMultiSourceConfig.LogMaxAge)).Int(),
-		LogLevel:                kingpin.Flag("logLevel", "Log level (debug|info|warn|error)").Default(config.DefaultMultiSourceConfig.LogLevel).String(),
-		EncryptPwd:              kingpin.Flag("encryptPwd", "Password to encrypt and exit").Default("").String(),
-		EncodeConfigPwd:         kingpin.Flag("encodeConfigPwd", "Encode the password in the config file,default:"+strconv.FormatBool(config.DefaultMultiSourceConfig.EncodeConfigPwd)).Default(strconv.FormatBool(config.DefaultMultiSourceConfig.EncodeConfigPwd)).Bool(),
-		EnableBasicAuth:         kingpin.Flag("enableBasicAuth", "Enable basic auth for metrics endpoint,default:"+strconv.FormatBool(config.DefaultMultiSourceConfig.EnableBasicAuth)).Default(strconv.FormatBool(config.DefaultMultiSourceConfig.EnableBasicAuth)).Bool(),
-		BasicAuthUsername:       kingpin.Flag("basicAuthUsername", "Username for basic auth").Default(config.DefaultMultiSourceConfig.BasicAuthUsername).String(),
-		BasicAuthPassword:       kingpin.Flag("basicAuthPassword", "Password for basic auth").Default(config.DefaultMultiSourceConfig.BasicAuthPassword).String(),
-		EncryptBasicAuthPwd:     kingpin.Flag("encryptBasicAuthPwd", "Password to encrypt for basic auth and exit").Default("").String(),
+		ConfigFile:                 kingpin.Flag("configFile", "Path to configuration file").Default("./dameng_exporter.toml").String(),
+		ListenAddr:                 kingpin.Flag("listenAddress", "Address to listen on").Default(config.DefaultMultiSourceConfig.ListenAddress).String(),
+		MetricPath:                 kingpin.Flag("metricPath", "Path for metrics").Default(config.DefaultMultiSourceConfig.MetricPath).String(),
+		EnableOpenMetrics:          kingpin.Flag("enableOpenMetrics", "Serve OpenMetrics format (with _created and exemplars) when negotiated by the client,default:"+strconv.FormatBool(config.DefaultMultiSourceConfig.EnableOpenMetrics)).Default(strconv.FormatBool(config.DefaultMultiSourceConfig.EnableOpenMetrics)).Bool(),
+		DbHost:                     kingpin.Flag("dbHost", "Database Host (when specified, requires dbUser and dbPwd)").String(),
+		DbUser:                     kingpin.Flag("dbUser", "Database user (required with dbHost)").String(),
+		DbPwd:                      kingpin.Flag("dbPwd", "Database password (required with dbHost)").String(),
+		DbName:                     kingpin.Flag("dbName", "Name for the database (optional, defaults to generated name)").String(),
+		QueryTimeout:               kingpin.Flag("queryTimeout", "Timeout for queries (Second)").Default(fmt.Sprint(config.DefaultDataSourceConfig.QueryTimeout)).Int(),
+		MaxOpenConns:               kingpin.Flag("maxOpenConns", "Maximum open connections (number)").Default(fmt.Sprint(config.DefaultDataSourceConfig.MaxOpenConns)).Int(),
+		ConnMaxLife:                kingpin.Flag("connMaxLifetime", "Connection maximum lifetime (Minute)").Default(fmt.Sprint(config.DefaultDataSourceConfig.ConnMaxLifetime)).Int(),
+		CheckSlowSQL:               kingpin.Flag("checkSlowSql", "Check slow SQL,default:"+strconv.FormatBool(config.DefaultDataSourceConfig.CheckSlowSQL)).Default(strconv.FormatBool(config.DefaultDataSourceConfig.CheckSlowSQL)).Bool(),
+		SlowSqlTime:                kingpin.Flag("slowSqlTime", "Slow SQL time (Millisecond)").Default(fmt.Sprint(config.DefaultDataSourceConfig.SlowSqlTime)).Int(),
+		SlowSqlMaxRows:             kingpin.Flag("slowSqlLimitRows", "Slow SQL return limit row").Default(fmt.Sprint(config.DefaultDataSourceConfig.SlowSqlMaxRows)).Int(),
+		RegisterHostMetrics:        kingpin.Flag("registerHostMetrics", "Register host metrics,default:"+strconv.FormatBool(config.DefaultDataSourceConfig.RegisterHostMetrics)).Default(strconv.FormatBool(config.DefaultDataSourceConfig.RegisterHostMetrics)).Bool(),
+		RegisterDatabaseMetrics:    kingpin.Flag("registerDatabaseMetrics", "Register database metrics,default:"+strconv.FormatBool(config.DefaultDataSourceConfig.RegisterDatabaseMetrics)).Default(strconv.FormatBool(config.DefaultDataSourceConfig.RegisterDatabaseMetrics)).Bool(),
+		RegisterDmhsMetrics:        kingpin.Flag("registerDmhsMetrics", "Register dmhs metrics,default:"+strconv.FormatBool(config.DefaultDataSourceConfig.RegisterDmhsMetrics)).Default(strconv.FormatBool(config.DefaultDataSourceConfig.RegisterDmhsMetrics)).Bool(),
+		RegisterCustomMetrics:      kingpin.Flag("registerCustomMetrics", "Register custom metrics,default:"+strconv.FormatBool(config.DefaultDataSourceConfig.RegisterCustomMetrics)).Default(strconv.FormatBool(config.DefaultDataSourceConfig.RegisterCustomMetrics)).Bool(),
+		BigKeyDataCacheTime:        kingpin.Flag("bigKeyDataCacheTime", "Big key data cache time (Minute)").Default(fmt.Sprint(config.DefaultDataSourceConfig.BigKeyDataCacheTime)).Int(),
+		AlarmKeyCacheTime:          kingpin.Flag("alarmKeyCacheTime", "Alarm key cache time (Minute)").Default(fmt.Sprint(config.DefaultDataSourceConfig.AlarmKeyCacheTime)).Int(),
+		LogMaxSize:                 kingpin.Flag("logMaxSize", "Maximum log file size(MB)").Default(fmt.Sprint(config.DefaultMultiSourceConfig.LogMaxSize)).Int(),
+		LogMaxBackups:              kingpin.Flag("logMaxBackups", "Maximum log file backups (number)").Default(fmt.Sprint(config.DefaultMultiSourceConfig.LogMaxBackups)).Int(),
+		LogMaxAge:                  kingpin.Flag("logMaxAge", "Maximum log file age (Day)").Default(fmt.Sprint(config.DefaultMultiSourceConfig.LogMaxAge)).Int(),
+		LogLevel:                   kingpin.Flag("logLevel", "Log level (debug|info|warn|error)").Default(config.DefaultMultiSourceConfig.LogLevel).String(),
+		LogDir:                     kingpin.Flag("logDir", "Directory of log files").Default(config.DefaultMultiSourceConfig.LogDir).String(),
+		LogFormat:                  kingpin.Flag("logFormat", "Log format (auto|json|console|logfmt), auto writes json to files and console to stdout").Default(config.DefaultMultiSourceConfig.LogFormat).String(),
+		LogOutput:                  kingpin.Flag("logOutput", "Log output (file|stdout|both)").Default(config.DefaultMultiSourceConfig.LogOutput).String(),
+		LogDedupIntervalSeconds:    kingpin.Flag("logDedupIntervalSeconds", "Interval in seconds for deduplicating datasource warning and error logs, 0 disables").Default(fmt.Sprint(config.DefaultMultiSourceConfig.LogDedupIntervalSeconds)).Int(),
+		EncryptPwd:                 kingpin.Flag("encryptPwd", "Password to encrypt and exit").Default("").String(),
+		EncodeConfigPwd:            kingpin.Flag("encodeConfigPwd", "Encode the password in the config file,default:"+strconv.FormatBool(config.DefaultMultiSourceConfig.EncodeConfigPwd)).Default(strconv.FormatBool(config.DefaultMultiSourceConfig.EncodeConfigPwd)).Bool(),
+		EnableBasicAuth:            kingpin.Flag("enableBasicAuth", "Enable basic auth for metrics endpoint,default:"+strconv.FormatBool(config.DefaultMultiSourceConfig.EnableBasicAuth)).Default(strconv.FormatBool(config.DefaultMultiSourceConfig.EnableBasicAuth)).Bool(),
+		BasicAuthUsername:          kingpin.Flag("basicAuthUsername", "Username for basic auth").Default(config.DefaultMultiSourceConfig.BasicAuthUsername).String(),
+		BasicAuthPassword:          kingpin.Flag("basicAuthPassword", "Password for basic auth").Default(config.DefaultMultiSourceConfig.BasicAuthPassword).String(),
+		BasicAuthUsersFile:         kingpin.Flag("basicAuthUsersFile", "htpasswd-style file of basic auth users with bcrypt, argon2id or sha512-crypt hashes and optional roles").Default(config.DefaultMultiSourceConfig.BasicAuthUsersFile).String(),
+		BasicAuthLegacyHashCompare: kingpin.Flag("basicAuthLegacyHashCompare", "Also accept a bcrypt hash sent as the basic auth password like earlier versions (insecure, for migration only)").Default(strconv.FormatBool(config.DefaultMultiSourceConfig.BasicAuthLegacyHashCompare)).Bool(),
+		BearerTokensFile:           kingpin.Flag("bearerTokensFile", "File of static bearer tokens with optional roles").Default(config.DefaultMultiSourceConfig.BearerTokensFile).String(),
+		EncryptBasicAuthPwd:        kingpin.Flag("encryptBasicAuthPwd", "Password to encrypt for basic auth and exit").Default("").String(),
 
 		// 全局超时控制参数
 		GlobalTimeoutSeconds: kingpin.Flag("globalTimeoutSeconds", "Global timeout for metrics collection (seconds)").Default(fmt.Sprint(config.DefaultMultiSourceConfig.GlobalTimeoutSeconds)).Int(),
@@ -59,18 +93,55 @@ func parseFlags() *config.CmdArgs {
 		// 采集模式参数
 		CollectionMode: kingpin.Flag("collectionMode", "Collection mode: blocking (default) or fast").Default(config.DefaultMultiSourceConfig.CollectionMode).String(),
 
+		// 抓取合并与并发控制参数
+		ScrapeCoalesceSeconds: kingpin.Flag("scrapeCoalesceSeconds", "Share one collection among identical scrapes arriving concurrently or within this window, 0 disables (seconds)").Default(fmt.Sprint(config.DefaultMultiSourceConfig.ScrapeCoalesceSeconds)).Int(),
+		MaxConcurrentScrapes:  kingpin.Flag("maxConcurrentScrapes", "Maximum number of concurrent collections, excess scrapes get 503, 0 means unlimited (number)").Default(fmt.Sprint(config.DefaultMultiSourceConfig.MaxConcurrentScrapes)).Int(),
+
 		// 健康检查参数
 		EnableHealthPing: kingpin.Flag("enableHealthPing", "Enable periodic health ping for datasource pools").Default(strconv.FormatBool(config.DefaultMultiSourceConfig.EnableHealthPing)).Bool(),
+
+		// 优雅停机与探针参数
+		ShutdownDrainSeconds:       kingpin.Flag("shutdownDrainSeconds", "Seconds to keep serving with /-/ready failing before shutdown (seconds)").Default(fmt.Sprint(config.DefaultMultiSourceConfig.ShutdownDrainSeconds)).Int(),
+		ShutdownTimeoutSeconds:     kingpin.Flag("shutdownTimeoutSeconds", "Maximum time to wait for in-flight requests during shutdown, 0 closes connections immediately (seconds)").Default(fmt.Sprint(config.DefaultMultiSourceConfig.ShutdownTimeoutSeconds)).Int(),
+		ReadyMinHealthyDatasources: kingpin.Flag("readyMinHealthyDatasources", "Minimum number of healthy datasources required by /-/ready").Default(fmt.Sprint(config.DefaultMultiSourceConfig.ReadyMinHealthyDatasources)).Int(),
+
+		// 告警状态持久化参数
+		AlarmStateFile: kingpin.Flag("alarmStateFile", "Path to the state file persisting alarm keys across restarts (empty disables)").Default(config.DefaultMultiSourceConfig.AlarmStateFile).String(),
+
+		// 缓存参数
+		CacheMaxEntries: kingpin.Flag("cacheMaxEntries", "Maximum number of cache entries (number)").Default(fmt.Sprint(config.DefaultMultiSourceConfig.CacheMaxEntries)).Int(),
+
+		WebhookDedupSeconds:      kingpin.Flag("webhookDedupSeconds", "Suppress identical datasource notifications within this window, 0 disables (seconds)").Default(fmt.Sprint(config.DefaultMultiSourceConfig.WebhookDedupSeconds)).Int(),
+		EnableAdminAPI:           kingpin.Flag("enableAdminApi", "Enable admin API to enable/disable/reconnect/add/delete datasources at runtime,default:"+strconv.FormatBool(config.DefaultMultiSourceConfig.EnableAdminAPI)).Default(strconv.FormatBool(config.DefaultMultiSourceConfig.EnableAdminAPI)).Bool(),
+		TrustedProxies:           kingpin.Flag("trustedProxies", "Trusted proxy address or CIDR whose X-Forwarded-For header is used as the client address (repeatable)").Strings(),
+		AuthFailureLimit:         kingpin.Flag("authFailureLimit", "Failed auth attempts allowed per client address within the window before lockout, 0 disables (number)").Default(fmt.Sprint(config.DefaultMultiSourceConfig.AuthFailureLimit)).Int(),
+		AuthFailureWindowSeconds: kingpin.Flag("authFailureWindowSeconds", "Window for counting failed auth attempts (seconds)").Default(fmt.Sprint(config.DefaultMultiSourceConfig.AuthFailureWindowSeconds)).Int(),
+		AuthLockoutSeconds:       kingpin.Flag("authLockoutSeconds", "Lockout duration after too many failed auth attempts (seconds)").Default(fmt.Sprint(config.DefaultMultiSourceConfig.AuthLockoutSeconds)).Int(),
+		EventHistorySize:         kingpin.Flag("eventHistorySize", "Number of recent datasource events kept in memory (number)").Default(fmt.Sprint(config.DefaultMultiSourceConfig.EventHistorySize)).Int(),
+
+		// 推送参数
+		RemoteWriteIntervalSeconds: kingpin.Flag("remoteWriteIntervalSeconds", "Interval between remote_write pushes, targets are configured in the config file (seconds)").Default(fmt.Sprint(config.DefaultMultiSourceConfig.RemoteWriteIntervalSeconds)).Int(),
+		OTLPIntervalSeconds:        kingpin.Flag("otlpIntervalSeconds", "Interval between OTLP metric exports, targets are configured in the config file (seconds)").Default(fmt.Sprint(config.DefaultMultiSourceConfig.OTLPIntervalSeconds)).Int(),
+
+		// Zabbix 推送参数
+		ZabbixServer:                   kingpin.Flag("zabbixServer", "Zabbix server/proxy trapper address (host:port) to push item values to, empty disables").Default(config.DefaultMultiSourceConfig.ZabbixServer).String(),
+		ZabbixIntervalSeconds:          kingpin.Flag("zabbixIntervalSeconds", "Interval between Zabbix item value pushes (seconds)").Default(fmt.Sprint(config.DefaultMultiSourceConfig.ZabbixIntervalSeconds)).Int(),
+		ZabbixHostLabel:                kingpin.Flag("zabbixHostLabel", "Label whose value is used as the Zabbix host name").Default(config.DefaultMultiSourceConfig.ZabbixHostLabel).String(),
+		ZabbixDiscoveryIntervalSeconds: kingpin.Flag("zabbixDiscoveryIntervalSeconds", "Interval between low-level discovery pushes through the trapper, 0 disables (seconds)").Default(fmt.Sprint(config.DefaultMultiSourceConfig.ZabbixDiscoveryIntervalSeconds)).Int(),
+		ZabbixTimeoutSeconds:           kingpin.Flag("zabbixTimeoutSeconds", "Timeout of a single Zabbix sender request (seconds)").Default(fmt.Sprint(config.DefaultMultiSourceConfig.ZabbixTimeoutSeconds)).Int(),
+
+		PushGatewayURL: kingpin.Flag("push.gateway.url", "Collect all datasources once, push to this Pushgateway URL and exit").Default("").String(),
+		PushGatewayJob: kingpin.Flag("push.gateway.job", "Job name used when pushing to Pushgateway").Default("dameng_exporter").String(),
 	}
-	kingpin.Parse()
-	return args
+	command.name = kingpin.Parse()
+	return args, command
 }
 
 func main() {
-	landingPage := []byte("<html><head><title>DAMENG DB Exporter " + Version + "</title></head><body><h1>DAMENG DB Exporter " + Version + "</h1><p><a href='/metrics'>Metrics</a></p></body></html>")
+	startTime := time.Now()
 
 	// 解析命令行参数
-	args := parseFlags()
+	args, command := parseFlags()
 	//加密密码口令返回
 	if execEncryptPwdCmd(args.EncryptPwd) {
 		return
@@ -79,6 +150,13 @@ func main() {
 	if auth.ExecEncryptBasicAuthPwdCmd(args.EncryptBasicAuthPwd) {
 		return
 	}
+	//config 子命令：迁移旧版配置或输出生效配置后退出，不加载旧版配置、不回写配置文件
+	switch command.name {
+	case "config migrate":
+		os.Exit(runConfigMigrate(command.config, args))
+	case "config dump":
+		os.Exit(runConfigDump(args))
+	}
 	//合并配置文件属性
 	mergeConfigParam(args)
 	// 设置版本号到全局变量（用于build info等）
@@ -88,16 +166,72 @@ func main() {
 		fmt.Println("Error: Failed to load configuration")
 		os.Exit(1)
 	}
+	// query、collect 与 report 子命令的结果可输出到标准输出，日志只保留警告以上，除非配置为 debug
+	if (command.name == "query" || command.name == "collect" || command.name == "report") && config.GlobalMultiConfig.LogLevel != "debug" {
+		config.GlobalMultiConfig.LogLevel = "warn"
+	}
 	// eg:初始化全局日志记录器，必须合并完配置在初始化 不然日志控制参数会失效
 	logger.InitLogger()
 	defer logger.Sync()
 
+	//query 子命令：执行一次自定义指标定义并输出结果后退出
+	if command.name == "query" {
+		code := runQuery(command.query)
+		logger.Sync()
+		os.Exit(code)
+	}
+
 	// 输出配置信息
 	logger.Logger.Infof("Configuration loaded with %d datasource(s)", len(config.GlobalMultiConfig.DataSources))
 
 	// 使用分类输出格式，每个类别一行
 	logger.Logger.Infof("%s", config.GlobalMultiConfig.StringCategorized())
 
+	// 设置缓存容量上限
+	cache.Default.SetMaxEntries(config.Global.GetCacheMaxEntries())
+
+	// 加载访问认证的用户文件与令牌文件
+	if err := auth.LoadCredentials(config.Global.GetBasicAuthUsersFile(), config.Global.GetBearerTokensFile()); err != nil {
+		logger.Logger.Fatalf("Failed to load auth credentials: %v", err)
+	}
+	if users, tokens := auth.CredentialCounts(); users > 0 || tokens > 0 {
+		logger.Logger.Infof("Loaded %d auth user(s) and %d bearer token(s)", users, tokens)
+	}
+	if config.Global.GetEnableBasicAuth() && config.Global.GetBasicAuthLegacyHashCompare() {
+		logger.Logger.Warn("basicAuthLegacyHashCompare is enabled, anyone who can read the password hash in the config can authenticate; disable it once all clients send the plaintext password")
+	}
+
+	// 加载告警状态文件，确保重启后仍能感知主备切换
+	if stateFile := config.Global.GetAlarmStateFile(); stateFile != "" {
+		loaded, err := config.InitAlarmStateStore(stateFile)
+		if err != nil {
+			logger.Logger.Warnf("Failed to load alarm state file %s, starting with empty state: %v", stateFile, err)
+		} else {
+			logger.Logger.Infof("Loaded %d alarm state key(s) from %s", loaded, stateFile)
+		}
+	}
+
+	//Pushgateway 模式：采集一次并推送后退出，退出码反映各数据源的结果
+	if *args.PushGatewayURL != "" {
+		code := runPushGateway(*args.PushGatewayURL, *args.PushGatewayJob)
+		logger.Sync()
+		os.Exit(code)
+	}
+
+	//collect 子命令：采集一次并输出巡检报告后退出
+	if command.name == "collect" {
+		code := runCollect(command.collect)
+		logger.Sync()
+		os.Exit(code)
+	}
+
+	//report 子命令：巡检一次并按阈值输出 HTML/Markdown 报告后退出
+	if command.name == "report" {
+		code := runReport(command.report)
+		logger.Sync()
+		os.Exit(code)
+	}
+
 	//项目开源地址
 	logger.Logger.Infof("The open source address of the project: https://github.com/gaoyuan98/dameng_exporter")
 
@@ -111,6 +245,15 @@ func main() {
 
 	logger.Logger.Infof("Initializing with %d datasource(s)", len(config.GlobalMultiConfig.DataSources))
 	poolManager := db.NewDBPoolManager(config.GlobalMultiConfig)
+
+	// 数据源状态变化通知：需在初始化连接池之前注册，才能收到首次连接失败事件；
+	// notifier.Close 先于 poolManager.Close 注册 defer，确保连接池停止后剩余事件仍能发出
+	notifier := notify.NewManager(config.Global.GetWebhooks(),
+		time.Duration(config.Global.GetWebhookDedupSeconds())*time.Second,
+		config.Global.GetEventHistorySize())
+	defer notifier.Close()
+	poolManager.AddEventListener(notifier.Handle)
+
 	err := poolManager.InitPools()
 	if err != nil {
 		logger.Logger.Fatalf("Failed to initialize datasource pools: %v", zap.Error(err))
@@ -121,17 +264,181 @@ func main() {
 	collector.RegisterMultiSourceCollectors(reg, poolManager)
 	logger.Logger.Info("Starting dameng_exporter version " + Version)
 	logger.Logger.Info("Please visit: http://localhost" + config.Global.GetListenAddress() + config.Global.GetMetricPath())
+	mux := http.NewServeMux()
 	//设置metric路径
-	http.Handle(config.Global.GetMetricPath(), auth.BasicAuthMiddleware(promhttp.HandlerFor(reg, promhttp.HandlerOpts{})))
-	//配置引导页
-	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
-		w.Write(landingPage)
-	})
-	//设置端口号
-	if err := http.ListenAndServe(config.Global.GetListenAddress(), nil); err != nil {
+	scrapes := web.NewScrapeCoordinator(
+		time.Duration(config.Global.GetScrapeCoalesceSeconds())*time.Second,
+		config.Global.GetMaxConcurrentScrapes())
+	reg.MustRegister(scrapes)
+	//remote_write 推送模式（配置了推送目标时启用），与抓取共用同一次采集结果
+	if targets := config.Global.GetRemoteWrite(); len(targets) > 0 {
+		remoteWriter, err := push.NewRemoteWriter(targets,
+			time.Duration(config.Global.GetRemoteWriteIntervalSeconds())*time.Second,
+			func() ([]*dto.MetricFamily, error) { return scrapes.Gather("", reg.Gather) })
+		if err != nil {
+			logger.Logger.Fatalf("Failed to initialize remote write: %v", err)
+		}
+		reg.MustRegister(remoteWriter)
+		remoteWriter.Start()
+		defer remoteWriter.Close()
+	}
+	//OpenTelemetry OTLP 导出（配置了导出目标时启用），与抓取共用同一次采集结果
+	if targets := config.Global.GetOTLP(); len(targets) > 0 {
+		otlpExporter, err := push.NewOTLPExporter(targets,
+			time.Duration(config.Global.GetOTLPIntervalSeconds())*time.Second, poolManager,
+			func() ([]*dto.MetricFamily, error) { return scrapes.Gather("", reg.Gather) })
+		if err != nil {
+			logger.Logger.Fatalf("Failed to initialize OTLP exporter: %v", err)
+		}
+		reg.MustRegister(otlpExporter)
+		otlpExporter.Start()
+		defer otlpExporter.Close()
+	}
+	//Zabbix 推送模式（配置了 zabbixServer 时启用），与抓取共用同一次采集结果
+	if server := config.Global.GetZabbixServer(); server != "" {
+		zabbixSender := push.NewZabbixSender(server,
+			time.Duration(config.Global.GetZabbixIntervalSeconds())*time.Second,
+			time.Duration(config.Global.GetZabbixDiscoveryIntervalSeconds())*time.Second,
+			time.Duration(config.Global.GetZabbixTimeoutSeconds())*time.Second,
+			config.Global.GetZabbixHostLabel(), poolManager,
+			func() ([]*dto.MetricFamily, error) { return scrapes.Gather("", reg.Gather) })
+		reg.MustRegister(zabbixSender)
+		zabbixSender.Start()
+		defer zabbixSender.Close()
+	}
+	mux.Handle(config.Global.GetMetricPath(), auth.Require(auth.RoleMetrics, web.MetricsHandler(reg, poolManager, scrapes)))
+	//存活与就绪探针（不做认证，便于 Kubernetes 探测）
+	mux.Handle(web.HealthyPath, web.HealthyHandler())
+	mux.Handle(web.ReadyPath, web.ReadyHandler(poolManager))
+	//Zabbix 低级别发现
+	mux.Handle("GET "+web.ZabbixDiscoveryPath, auth.Require(auth.RoleMetrics, web.ZabbixDiscoveryHandler(poolManager, scrapes)))
+	//数据源事件历史
+	mux.Handle(web.EventsPath, auth.Require(auth.RoleRead, web.EventsHandler(notifier.History())))
+	//数据源清单与健康状态
+	mux.Handle("GET "+web.DatasourcesPath, auth.Require(auth.RoleRead, web.DatasourcesHandler(poolManager)))
+	mux.Handle("GET "+web.DatasourcePath, auth.Require(auth.RoleRead, web.DatasourceHandler(poolManager)))
+	//巡检报告
+	mux.Handle("GET "+web.ReportPath, auth.Require(auth.RoleRead, web.ReportHandler(poolManager, scrapes)))
+	//数据源运维接口（默认关闭）
+	if config.Global.GetEnableAdminAPI() {
+		if !config.Global.GetAuthEnabled() {
+			logger.Logger.Warn("Admin API is enabled without authentication, anyone who can reach the exporter can modify datasources")
+		}
+		mux.Handle("POST "+web.DatasourcesPath, auth.Require(auth.RoleAdmin, web.AddDatasourceHandler(poolManager)))
+		mux.Handle("POST "+web.DatasourceActionPath, auth.Require(auth.RoleAdmin, web.DatasourceActionHandler(poolManager)))
+	}
+	//运行时日志级别（始终开放，启用认证时需要 admin 角色）
+	mux.Handle("GET "+web.LogLevelPath, auth.Require(auth.RoleAdmin, web.LogLevelHandler(poolManager)))
+	mux.Handle("PUT "+web.LogLevelPath, auth.Require(auth.RoleAdmin, web.LogLevelHandler(poolManager)))
+	//状态页
+	mux.Handle("/", auth.Require(auth.RoleRead, web.StatusHandler(poolManager, web.StatusOptions{
+		Version:    Version,
+		MetricPath: config.Global.GetMetricPath(),
+		StartTime:  startTime,
+		AdminAPI:   config.Global.GetEnableAdminAPI(),
+	})))
+
+	//访问控制：按路径限制客户端地址，认证失败次数过多时锁定客户端地址
+	accessGuard, err := auth.SetupGuard(config.Global.GetAccessRules(), config.Global.GetTrustedProxies(),
+		config.Global.GetAuthFailureLimit(),
+		time.Duration(config.Global.GetAuthFailureWindowSeconds())*time.Second,
+		time.Duration(config.Global.GetAuthLockoutSeconds())*time.Second)
+	if err != nil {
+		logger.Logger.Fatalf("Failed to initialize access control: %v", err)
+	}
+	reg.MustRegister(accessGuard)
+
+	server := &http.Server{
+		Addr:    config.Global.GetListenAddress(),
+		Handler: auth.AccessControl(mux),
+	}
+
+	//设置端口号，在独立协程中启动服务，主协程等待停止信号
+	serverErr := make(chan error, 1)
+	go func() {
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			serverErr <- err
+		}
+	}()
+
+	// SIGUSR1 切换 debug 日志，无需重启即可排查问题
+	logger.WatchDebugSignal()
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(sigChan)
+
+	select {
+	case sig := <-sigChan:
+		logger.Logger.Infof("Received signal %v, starting graceful shutdown", sig)
+		gracefulShutdown(server, sigChan)
+	case err := <-serverErr:
 		logger.Logger.Errorf("Error occur when start server %v", zap.Error(err))
 	}
 
+	// 关闭连接池、发送剩余通知与刷新日志由 defer 按 poolManager.Close -> notifier.Close -> logger.Sync 的顺序完成
+	logger.Logger.Info("Closing datasource pools")
+}
+
+// runPushGateway 对所有数据源执行一次完整采集，按数据源分组推送到 Pushgateway，返回进程退出码
+func runPushGateway(url, job string) int {
+	logger.Logger.Infof("Running in Pushgateway mode, pushing to %s with job %s", url, job)
+	poolManager := db.NewDBPoolManager(config.GlobalMultiConfig)
+	if err := poolManager.InitPools(); err != nil {
+		logger.Logger.Errorf("Failed to initialize datasource pools: %v", err)
+		return db.ExitAllFailed
+	}
+	defer poolManager.Close()
+
+	reg := prometheus.NewRegistry()
+	collector.RegisterMultiSourceCollectors(reg, poolManager)
+
+	results := push.PushToGateway(url, job, reg, poolManager)
+	for _, r := range results {
+		if r.Err != nil {
+			logger.Logger.Errorf("Datasource %s: %d series, grouping %v, failed: %v", r.DataSource, r.Series, r.Grouping, r.Err)
+		} else {
+			logger.Logger.Infof("Datasource %s: pushed %d series, grouping %v", r.DataSource, r.Series, r.Grouping)
+		}
+	}
+	code := push.GatewayExitCode(results)
+	logger.Logger.Infof("Pushgateway push finished with exit code %d", code)
+	return code
+}
+
+// gracefulShutdown 优雅停机：先让就绪探针失败并等待流量摘除，再等待在途请求完成
+func gracefulShutdown(server *http.Server, sigChan <-chan os.Signal) {
+	// 步骤1：标记停机，/-/ready 立即返回503
+	web.MarkShuttingDown()
+
+	// 步骤2：保持服务一段时间，等待负载均衡或 Prometheus 摘除本实例；再次收到信号则跳过等待
+	drain := time.Duration(config.Global.GetShutdownDrainSeconds()) * time.Second
+	if drain > 0 {
+		logger.Logger.Infof("Draining for %v before shutting down HTTP server", drain)
+		select {
+		case <-time.After(drain):
+		case sig := <-sigChan:
+			logger.Logger.Warnf("Received signal %v again, skipping drain period", sig)
+		}
+	}
+
+	// 步骤3：停止接收新请求，并在超时时间内等待在途请求完成；超时为0时直接关闭所有连接
+	timeout := time.Duration(config.Global.GetShutdownTimeoutSeconds()) * time.Second
+	if timeout == 0 {
+		if err := server.Close(); err != nil {
+			logger.Logger.Warnf("HTTP server close failed: %v", err)
+			return
+		}
+		logger.Logger.Info("HTTP server closed without waiting for in-flight requests")
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if err := server.Shutdown(ctx); err != nil {
+		logger.Logger.Warnf("HTTP server shutdown did not complete cleanly: %v", err)
+		return
+	}
+	logger.Logger.Info("HTTP server stopped")
 }
 
 // mergeConfigParam 合并配置文件和命令行参数
@@ -0,0 +1,211 @@
+package db
+
+import (
+	"dameng_exporter/cache"
+	"dameng_exporter/config"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+var (
+	// ErrDatasourceNotFound 数据源不存在
+	ErrDatasourceNotFound = errors.New("datasource not found")
+	// ErrDiscoveredDatasource 自动发现的成员由种子数据源管理，不支持运行时启停与增删
+	ErrDiscoveredDatasource = errors.New("discovered datasource is managed by its seed")
+	// ErrDatasourceDisabled 数据源已禁用
+	ErrDatasourceDisabled = errors.New("datasource is disabled")
+)
+
+// DisableDataSource 运行时禁用数据源：关闭连接池并移出健康与失败列表，该种子发现的成员一并移除
+func (m *DBPoolManager) DisableDataSource(name string) error {
+	if err := m.checkConfigured(name); err != nil {
+		return err
+	}
+	if _, err := m.config.SetDataSourceEnabled(name, false); err != nil {
+		return err
+	}
+
+	// 禁用期间的查询结果不再有效，告警状态保留以便重新启用后继续感知切换
+	m.detach(name, cache.KindBigKey)
+	m.logger.Info("数据源已禁用", zap.String("datasource", name))
+	return nil
+}
+
+// EnableDataSource 运行时启用数据源并立即尝试建立连接，连接失败时进入失败列表等待后台重试
+func (m *DBPoolManager) EnableDataSource(name string) error {
+	if err := m.checkConfigured(name); err != nil {
+		return err
+	}
+	cfg, err := m.config.SetDataSourceEnabled(name, true)
+	if err != nil {
+		return err
+	}
+
+	m.logger.Info("数据源已启用", zap.String("datasource", name))
+	m.connectNow(cfg)
+	if cfg.Discovery {
+		m.startDiscoveryLoop(cfg)
+	}
+	return nil
+}
+
+// ReconnectDataSource 立即重建指定数据源的连接，返回本次连接的错误
+func (m *DBPoolManager) ReconnectDataSource(name string) error {
+	cfg := m.lookupDataSourceConfig(name)
+	if cfg == nil {
+		return ErrDatasourceNotFound
+	}
+	if !cfg.Enabled {
+		return ErrDatasourceDisabled
+	}
+
+	m.logger.Info("手动触发数据源重连", zap.String("datasource", name))
+	return m.connectNow(cfg)
+}
+
+// AddDataSource 运行时新增数据源，启用时立即尝试建立连接
+func (m *DBPoolManager) AddDataSource(ds config.DataSourceConfig) (*config.DataSourceConfig, error) {
+	if ds.DiscoveredFrom != "" {
+		return nil, ErrDiscoveredDatasource
+	}
+
+	// 名称与地址不能与当前自动发现的成员冲突
+	cleanHost, _, _ := normalizeDBHost(ds.DbHost)
+	for _, member := range m.DiscoveredDataSources() {
+		if member.Name == ds.Name {
+			return nil, fmt.Errorf("数据源名称重复: %s (与自动发现的成员冲突)", ds.Name)
+		}
+		if memberHost, _, _ := normalizeDBHost(member.DbHost); ds.Enabled && memberHost == cleanHost {
+			return nil, fmt.Errorf("数据源地址重复: %s (已作为 '%s' 被自动发现)", cleanHost, member.Name)
+		}
+	}
+
+	cfg, err := m.config.AddDataSource(ds)
+	if err != nil {
+		return nil, err
+	}
+
+	m.logger.Info("运行时新增数据源",
+		zap.String("datasource", cfg.Name),
+		zap.String("host", cfg.DbHost))
+	if cfg.Enabled {
+		m.connectNow(cfg)
+		if cfg.Discovery {
+			m.startDiscoveryLoop(cfg)
+		}
+	}
+	return cfg, nil
+}
+
+// RemoveDataSource 运行时删除数据源：关闭连接池、清理状态与缓存，该种子发现的成员一并移除
+func (m *DBPoolManager) RemoveDataSource(name string) error {
+	if err := m.checkConfigured(name); err != nil {
+		return err
+	}
+	if _, err := m.config.RemoveDataSource(name); err != nil {
+		return err
+	}
+
+	m.detach(name)
+	m.logger.Info("运行时删除数据源", zap.String("datasource", name))
+	return nil
+}
+
+// checkConfigured 检查数据源是否为配置文件中的数据源
+func (m *DBPoolManager) checkConfigured(name string) error {
+	if m.config.GetDataSourceByName(name) != nil {
+		return nil
+	}
+	m.mu.RLock()
+	_, discovered := m.discovered[name]
+	m.mu.RUnlock()
+	if discovered {
+		return ErrDiscoveredDatasource
+	}
+	return ErrDatasourceNotFound
+}
+
+// OnDatasourceRemoved 注册数据源删除（运行时删除或自动发现的成员移除）后的清理回调，
+// 回调在持有管理器锁时同步调用，不能再调用管理器的方法
+func (m *DBPoolManager) OnDatasourceRemoved(hook func(name string)) {
+	if m == nil || hook == nil {
+		return
+	}
+	m.mu.Lock()
+	m.removalHooks = append(m.removalHooks, hook)
+	m.mu.Unlock()
+}
+
+// runRemovalHooksLocked 在持有锁的情况下调用数据源删除回调
+func (m *DBPoolManager) runRemovalHooksLocked(name string) {
+	for _, hook := range m.removalHooks {
+		hook(name)
+	}
+}
+
+// detach 将数据源及其发现的成员移出健康与失败列表并关闭连接，按 kinds 清理缓存（未指定时清理全部）
+func (m *DBPoolManager) detach(name string, kinds ...cache.Kind) {
+	var toClose []*sql.DB
+
+	m.mu.Lock()
+	if pool, ok := m.pools[name]; ok && pool != nil {
+		pool.markUnhealthy(time.Now())
+		if pool.DB != nil {
+			toClose = append(toClose, pool.DB)
+		}
+	}
+	delete(m.pools, name)
+	delete(m.failedSources, name)
+	cache.Default.InvalidateDataSource(name, kinds...)
+	if len(kinds) == 0 {
+		m.runRemovalHooksLocked(name)
+	}
+
+	for memberName, member := range m.discovered {
+		if member.DiscoveredFrom != name {
+			continue
+		}
+		if dbConn := m.removeDiscoveredLocked(memberName); dbConn != nil {
+			toClose = append(toClose, dbConn)
+		}
+	}
+	m.mu.Unlock()
+
+	for _, dbConn := range toClose {
+		if err := dbConn.Close(); err != nil {
+			m.logger.Error("关闭数据源连接失败",
+				zap.String("datasource", name),
+				zap.Error(err))
+		}
+	}
+}
+
+// connectNow 立即建立连接：成功后替换健康列表中的连接，失败时降级或登记失败等待后台重试
+func (m *DBPoolManager) connectNow(cfg *config.DataSourceConfig) error {
+	pool, err := m.createPool(cfg)
+	if err != nil {
+		m.logger.Warn("建立数据源连接失败，等待后台重试",
+			zap.String("datasource", cfg.Name),
+			zap.Error(err))
+		if existing := m.GetPool(cfg.Name); existing != nil {
+			m.demoteToFailed(existing, err)
+		} else {
+			m.noteFailedDataSource(cfg, err)
+		}
+		return err
+	}
+
+	if !m.promoteToHealthy(cfg, pool) {
+		if pool.DB != nil {
+			pool.DB.Close()
+		}
+		return fmt.Errorf("datasource %s is no longer managed", cfg.Name)
+	}
+
+	m.logger.Info("数据源连接成功，已加入健康列表", zap.String("datasource", cfg.Name))
+	return nil
+}
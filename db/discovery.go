@@ -0,0 +1,409 @@
+package db
+
+import (
+	"context"
+	"dameng_exporter/cache"
+	"dameng_exporter/config"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// clusterMember 通过视图发现的集群成员
+type clusterMember struct {
+	InstName string // 实例名称
+	Host     string // 实例地址
+	Port     string // 实例端口
+}
+
+// startDiscovery 为开启 discovery 的种子数据源启动拓扑发现协程
+func (m *DBPoolManager) startDiscovery() {
+	if m == nil || m.config == nil {
+		return
+	}
+
+	dataSources := m.config.DataSourceList()
+	for i := range dataSources {
+		seed := &dataSources[i]
+		if seed.Enabled && seed.Discovery {
+			m.startDiscoveryLoop(seed)
+		}
+	}
+}
+
+// startDiscoveryLoop 为种子数据源启动拓扑发现协程，同一种子只会存在一个协程
+func (m *DBPoolManager) startDiscoveryLoop(seed *config.DataSourceConfig) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// 管理器已关闭或该种子的协程仍在运行时不再启动
+	select {
+	case <-m.stopChan:
+		return
+	default:
+	}
+	if m.discoveryLoops[seed.Name] {
+		return
+	}
+	m.discoveryLoops[seed.Name] = true
+
+	m.logger.Info("已启用拓扑自动发现",
+		zap.String("datasource", seed.Name),
+		zap.Int("interval_seconds", seed.DiscoveryIntervalSeconds))
+
+	m.wg.Add(1)
+	go m.runDiscoveryLoop(seed.Name, seed.DiscoveryIntervalSeconds)
+}
+
+// runDiscoveryLoop 按种子数据源配置的周期执行拓扑发现，种子数据源被删除后退出
+func (m *DBPoolManager) runDiscoveryLoop(seedName string, intervalSeconds int) {
+	defer m.wg.Done()
+
+	interval := time.Duration(intervalSeconds) * time.Second
+	if interval <= 0 {
+		interval = time.Duration(config.DefaultDataSourceConfig.DiscoveryIntervalSeconds) * time.Second
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	// 启动后立即执行一次，尽快纳管已有成员
+	if !m.discoverSeed(seedName) {
+		return
+	}
+
+	for {
+		select {
+		case <-m.stopChan:
+			return
+		case <-ticker.C:
+			if !m.discoverSeed(seedName) {
+				return
+			}
+		}
+	}
+}
+
+// discoverSeed 按种子数据源的当前配置执行一轮发现，种子已被删除时注销协程并返回 false
+func (m *DBPoolManager) discoverSeed(seedName string) bool {
+	seed := m.config.GetDataSourceByName(seedName)
+	if seed == nil {
+		m.mu.Lock()
+		delete(m.discoveryLoops, seedName)
+		m.mu.Unlock()
+		m.logger.Info("种子数据源已删除，停止拓扑自动发现", zap.String("datasource", seedName))
+		return false
+	}
+
+	// 种子被禁用或关闭发现时暂停，重新启用后继续
+	if seed.Enabled && seed.Discovery {
+		m.discoverMembers(seed)
+	}
+	return true
+}
+
+// discoverMembers 从种子数据源读取拓扑视图，并同步发现的成员到连接池管理器
+func (m *DBPoolManager) discoverMembers(seed *config.DataSourceConfig) {
+	// 种子数据源不可用时保持现有成员不变，等待下一周期
+	pool := m.GetPool(seed.Name)
+	if pool == nil || pool.DB == nil || !pool.IsHealthy() {
+		m.logger.Debug("种子数据源当前不可用，跳过本轮拓扑发现",
+			zap.String("datasource", seed.Name))
+		return
+	}
+
+	timeoutSeconds := seed.QueryTimeout
+	if timeoutSeconds <= 0 {
+		timeoutSeconds = config.DefaultDataSourceConfig.QueryTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(timeoutSeconds)*time.Second)
+	defer cancel()
+
+	members, err := m.queryClusterMembers(ctx, pool.DB, seed)
+	if err != nil {
+		m.logger.Warn("拓扑发现查询失败，保留现有成员",
+			zap.String("datasource", seed.Name),
+			zap.Error(err))
+		return
+	}
+
+	desired := make(map[string]*config.DataSourceConfig, len(members))
+	for _, member := range members {
+		child := buildDiscoveredConfig(seed, member)
+		desired[child.Name] = child
+	}
+
+	m.syncDiscoveredMembers(seed, desired)
+}
+
+// queryClusterMembers 查询归档目标与守护进程视图，并通过 MAL 配置解析成员地址
+func (m *DBPoolManager) queryClusterMembers(ctx context.Context, dbConn *sql.DB, seed *config.DataSourceConfig) ([]clusterMember, error) {
+	// 步骤1：获取当前实例名称，用于排除种子自身
+	var selfName string
+	if err := dbConn.QueryRowContext(ctx, config.QueryDiscoveryInstanceNameSql).Scan(&selfName); err != nil {
+		return nil, fmt.Errorf("查询实例名称失败: %w", err)
+	}
+
+	// 步骤2：汇总各视图中的成员实例名，单个视图不可用时不影响其他视图
+	names := make(map[string]bool)
+	succeeded := 0
+	for _, query := range []string{
+		config.QueryDiscoveryArchDestSql,
+		config.QueryDiscoveryArchSendDestSql,
+		config.QueryDiscoveryWatcherInstSql,
+	} {
+		values, err := queryStringColumn(ctx, dbConn, query)
+		if err != nil {
+			m.logger.Debug("拓扑发现视图查询失败",
+				zap.String("datasource", seed.Name),
+				zap.String("sql", query),
+				zap.Error(err))
+			continue
+		}
+		succeeded++
+		for _, v := range values {
+			v = strings.TrimSpace(v)
+			if v != "" && !strings.EqualFold(v, selfName) {
+				names[strings.ToUpper(v)] = true
+			}
+		}
+	}
+	if succeeded == 0 {
+		return nil, fmt.Errorf("所有拓扑视图均查询失败")
+	}
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	// 步骤3：读取 MAL 配置，将实例名解析为地址
+	malAddrs, err := queryMalInstAddrs(ctx, dbConn)
+	if err != nil {
+		return nil, fmt.Errorf("查询MAL配置失败: %w", err)
+	}
+
+	members := make([]clusterMember, 0, len(names))
+	for name := range names {
+		addr, ok := malAddrs[name]
+		if !ok {
+			m.logger.Debug("未能在MAL配置中解析成员地址，已跳过",
+				zap.String("datasource", seed.Name),
+				zap.String("instance", name))
+			continue
+		}
+		if seed.DiscoveryPort > 0 {
+			addr.Port = strconv.Itoa(seed.DiscoveryPort)
+		}
+		members = append(members, addr)
+	}
+
+	sort.Slice(members, func(i, j int) bool { return members[i].InstName < members[j].InstName })
+	return members, nil
+}
+
+// queryStringColumn 执行单列查询并返回所有非空字符串值
+func queryStringColumn(ctx context.Context, dbConn *sql.DB, query string) ([]string, error) {
+	rows, err := dbConn.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var values []string
+	for rows.Next() {
+		var value sql.NullString
+		if err := rows.Scan(&value); err != nil {
+			return nil, err
+		}
+		if value.Valid {
+			values = append(values, value.String)
+		}
+	}
+	return values, rows.Err()
+}
+
+// queryMalInstAddrs 读取 V$DM_MAL_INI，返回实例名（大写）到地址的映射
+func queryMalInstAddrs(ctx context.Context, dbConn *sql.DB) (map[string]clusterMember, error) {
+	rows, err := dbConn.QueryContext(ctx, config.QueryDiscoveryMalInstSql)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	addrs := make(map[string]clusterMember)
+	for rows.Next() {
+		var instName, instHost, instPort sql.NullString
+		if err := rows.Scan(&instName, &instHost, &instPort); err != nil {
+			return nil, err
+		}
+		if !instName.Valid || !instHost.Valid || instHost.String == "" {
+			continue
+		}
+		name := strings.ToUpper(strings.TrimSpace(instName.String))
+		addrs[name] = clusterMember{
+			InstName: name,
+			Host:     strings.TrimSpace(instHost.String),
+			Port:     strings.TrimSpace(instPort.String),
+		}
+	}
+	return addrs, rows.Err()
+}
+
+// buildDiscoveredConfig 基于种子数据源生成成员数据源配置，继承标签与采集开关
+func buildDiscoveredConfig(seed *config.DataSourceConfig, member clusterMember) *config.DataSourceConfig {
+	child := *seed
+	child.Name = fmt.Sprintf("%s_%s", seed.Name, strings.ToLower(member.InstName))
+	child.Description = fmt.Sprintf("Discovered from %s (instance %s)", seed.Name, member.InstName)
+
+	// 保留种子数据源的连接参数（问号之后的部分）
+	queryParams := ""
+	if idx := strings.Index(seed.DbHost, "?"); idx != -1 {
+		queryParams = seed.DbHost[idx:]
+	}
+	port := member.Port
+	if port == "" {
+		_, _, port = normalizeDBHost(seed.DbHost)
+	}
+	child.DbHost = formatHostPortLabel(member.Host, port) + queryParams
+
+	if seed.DiscoveryUser != "" {
+		child.DbUser = seed.DiscoveryUser
+	}
+	if seed.DiscoveryPwd != "" {
+		child.DbPwd = seed.DiscoveryPwd
+	}
+
+	// 成员本身不再继续发现，避免递归扩散
+	child.Discovery = false
+	child.DiscoveredFrom = seed.Name
+	return &child
+}
+
+// syncDiscoveredMembers 对比期望成员与当前成员，注册新成员并移除消失的成员
+func (m *DBPoolManager) syncDiscoveredMembers(seed *config.DataSourceConfig, desired map[string]*config.DataSourceConfig) {
+	// 配置文件中已显式配置的地址与名称不重复纳管
+	configuredHosts := make(map[string]string)
+	configuredNames := make(map[string]bool)
+	dataSources := m.config.DataSourceList()
+	for i := range dataSources {
+		ds := &dataSources[i]
+		configuredNames[ds.Name] = true
+		if ds.Enabled {
+			cleanHost, _, _ := normalizeDBHost(ds.DbHost)
+			configuredHosts[cleanHost] = ds.Name
+		}
+	}
+
+	var toAdd []*config.DataSourceConfig
+	var toClose []*sql.DB
+
+	m.mu.Lock()
+	// 步骤1：移除已消失或地址发生变化的成员
+	for name, existing := range m.discovered {
+		if existing.DiscoveredFrom != seed.Name {
+			continue
+		}
+		want, ok := desired[name]
+		if ok && want.DbHost == existing.DbHost {
+			continue
+		}
+		if dbConn := m.removeDiscoveredLocked(name); dbConn != nil {
+			toClose = append(toClose, dbConn)
+		}
+		m.logger.Info("自动发现的成员已消失，移除数据源",
+			zap.String("datasource", name),
+			zap.String("seed", seed.Name))
+	}
+
+	// 步骤2：登记新成员
+	for name, want := range desired {
+		if _, exists := m.discovered[name]; exists {
+			continue
+		}
+		if configuredNames[name] {
+			continue
+		}
+		cleanHost, _, _ := normalizeDBHost(want.DbHost)
+		if owner, exists := configuredHosts[cleanHost]; exists {
+			m.logger.Debug("发现的成员已在配置文件中配置，跳过",
+				zap.String("host", cleanHost),
+				zap.String("configured_datasource", owner))
+			continue
+		}
+		m.discovered[name] = want
+		toAdd = append(toAdd, want)
+	}
+	m.mu.Unlock()
+
+	for _, dbConn := range toClose {
+		if err := dbConn.Close(); err != nil {
+			m.logger.Error("关闭已移除成员的连接失败", zap.Error(err))
+		}
+	}
+
+	// 步骤3：在锁外建立连接，失败的成员进入失败列表等待后台重试
+	for _, cfg := range toAdd {
+		m.logger.Info("发现新的集群成员，注册为数据源",
+			zap.String("datasource", cfg.Name),
+			zap.String("host", cfg.DbHost),
+			zap.String("seed", seed.Name))
+
+		pool, err := m.createPool(cfg)
+		if err != nil {
+			m.logger.Warn("连接自动发现的成员失败，等待后台重试",
+				zap.String("datasource", cfg.Name),
+				zap.Error(err))
+			m.noteDiscoveredFailure(cfg, err)
+			continue
+		}
+		if !m.promoteToHealthy(cfg, pool) && pool.DB != nil {
+			pool.DB.Close()
+		}
+	}
+}
+
+// noteDiscoveredFailure 仅在成员仍处于纳管状态时登记失败，避免与移除操作竞争
+func (m *DBPoolManager) noteDiscoveredFailure(cfg *config.DataSourceConfig, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.discovered[cfg.Name] != cfg {
+		return
+	}
+	m.noteFailedDataSourceLocked(cfg, err, time.Now())
+}
+
+// removeDiscoveredLocked 在持有锁的情况下移除成员，返回需要在锁外关闭的连接
+func (m *DBPoolManager) removeDiscoveredLocked(name string) *sql.DB {
+	var dbToClose *sql.DB
+	if pool, ok := m.pools[name]; ok && pool != nil {
+		pool.markUnhealthy(time.Now())
+		dbToClose = pool.DB
+	}
+	delete(m.pools, name)
+	delete(m.failedSources, name)
+	delete(m.discovered, name)
+	cache.Default.InvalidateDataSource(name)
+	m.runRemovalHooksLocked(name)
+	return dbToClose
+}
+
+// DiscoveredDataSources 返回当前自动发现的成员数据源配置快照（按名称排序）
+func (m *DBPoolManager) DiscoveredDataSources() []*config.DataSourceConfig {
+	if m == nil {
+		return nil
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	result := make([]*config.DataSourceConfig, 0, len(m.discovered))
+	for _, cfg := range m.discovered {
+		result = append(result, cfg)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result
+}
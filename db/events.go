@@ -0,0 +1,118 @@
+package db
+
+import (
+	"dameng_exporter/config"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// DatasourceEventType 数据源状态变化类型
+type DatasourceEventType string
+
+const (
+	// EventDatasourceDown 数据源由可用（或未知）变为不可用
+	EventDatasourceDown DatasourceEventType = "down"
+	// EventDatasourceUp 数据源由不可用恢复为可用
+	EventDatasourceUp DatasourceEventType = "up"
+)
+
+// eventQueueSize 事件分发队列长度，队列满时丢弃事件并记录日志
+const eventQueueSize = 256
+
+// DatasourceEvent 数据源状态变化事件
+type DatasourceEvent struct {
+	Type       DatasourceEventType `json:"type"`
+	DataSource string              `json:"datasource"`
+	Host       string              `json:"host"`
+	Error      string              `json:"error,omitempty"`
+	Time       time.Time           `json:"time"`
+	Downtime   time.Duration       `json:"-"` // 恢复事件对应的不可用时长
+}
+
+// DowntimeSeconds 返回不可用时长（秒）
+func (e DatasourceEvent) DowntimeSeconds() float64 {
+	return e.Downtime.Seconds()
+}
+
+// EventListener 数据源事件监听函数，在独立的分发协程中串行调用
+type EventListener func(DatasourceEvent)
+
+// AddEventListener 注册数据源事件监听器，需在 InitPools 之前调用才能收到初始化阶段的事件
+func (m *DBPoolManager) AddEventListener(listener EventListener) {
+	if m == nil || listener == nil {
+		return
+	}
+
+	m.mu.Lock()
+	m.listeners = append(m.listeners, listener)
+	m.mu.Unlock()
+
+	m.dispatchOnce.Do(func() {
+		m.wg.Add(1)
+		go m.dispatchEvents()
+	})
+}
+
+// dispatchEvents 将事件依次分发给监听器，收到停止信号后处理完队列中剩余事件再退出
+func (m *DBPoolManager) dispatchEvents() {
+	defer m.wg.Done()
+
+	for {
+		select {
+		case event := <-m.events:
+			m.notifyListeners(event)
+		case <-m.stopChan:
+			for {
+				select {
+				case event := <-m.events:
+					m.notifyListeners(event)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+// notifyListeners 调用所有监听器，单个监听器异常不影响其他监听器
+func (m *DBPoolManager) notifyListeners(event DatasourceEvent) {
+	m.mu.RLock()
+	listeners := append([]EventListener(nil), m.listeners...)
+	m.mu.RUnlock()
+
+	for _, listener := range listeners {
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					m.logger.Error("数据源事件监听器异常",
+						zap.String("datasource", event.DataSource),
+						zap.Any("panic", r))
+				}
+			}()
+			listener(event)
+		}()
+	}
+}
+
+// emitEvent 非阻塞地投递事件，可在持有锁时调用
+func (m *DBPoolManager) emitEvent(event DatasourceEvent) {
+	select {
+	case m.events <- event:
+	default:
+		m.logger.Warn("数据源事件队列已满，丢弃事件",
+			zap.String("datasource", event.DataSource),
+			zap.String("type", string(event.Type)))
+	}
+}
+
+// newDatasourceEvent 根据数据源配置构建事件
+func newDatasourceEvent(eventType DatasourceEventType, dsConfig *config.DataSourceConfig, ts time.Time) DatasourceEvent {
+	cleanHost, _, _ := normalizeDBHost(dsConfig.DbHost)
+	return DatasourceEvent{
+		Type:       eventType,
+		DataSource: dsConfig.Name,
+		Host:       cleanHost,
+		Time:       ts,
+	}
+}
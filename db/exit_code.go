@@ -0,0 +1,8 @@
+package db
+
+// 一次性运行（collect、report 子命令与 Pushgateway 推送）的进程退出码，按各数据源的结果计算
+const (
+	ExitAllSucceeded = 0 // 所有数据源均成功
+	ExitPartial      = 1 // 部分数据源失败或存在问题
+	ExitAllFailed    = 2 // 全部数据源失败，或无法完成采集
+)
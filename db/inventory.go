@@ -0,0 +1,120 @@
+package db
+
+import (
+	"dameng_exporter/config"
+	"database/sql"
+	"time"
+)
+
+// 数据源状态
+const (
+	DatasourceStateHealthy  = "healthy"  // 位于健康列表
+	DatasourceStateFailed   = "failed"   // 位于失败列表，等待后台重试
+	DatasourceStateDisabled = "disabled" // 配置中禁用
+	DatasourceStateUnknown  = "unknown"  // 未注册（如因名称或地址重复被跳过）
+)
+
+// DatasourceLabel 数据源标签名，注入到每个数据源的指标中，用于区分各数据源
+const DatasourceLabel = "datasource"
+
+// DatasourceSnapshot 数据源的配置与运行状态快照
+type DatasourceSnapshot struct {
+	Config          *config.DataSourceConfig // 数据源配置
+	Host            string                   // 去除查询参数后的地址
+	Labels          map[string]string        // 附加标签
+	State           string                   // 数据源状态
+	Healthy         bool                     // 是否健康
+	LastHealthCheck time.Time                // 最近一次健康检查或重试时间
+	LastError       string                   // 最近一次错误信息
+	FailedAt        time.Time                // 首次失败时间，健康时为零值
+	PoolStats       *sql.DBStats             // 连接池统计，仅健康数据源提供
+}
+
+// InjectedLabels 返回注入到该数据源指标中的标签：附加标签，以及未设置时取数据源名称的 datasource 标签
+func (s DatasourceSnapshot) InjectedLabels() map[string]string {
+	labels := make(map[string]string, len(s.Labels)+1)
+	for k, v := range s.Labels {
+		labels[k] = v
+	}
+	if labels[DatasourceLabel] == "" && s.Config != nil {
+		labels[DatasourceLabel] = s.Config.Name
+	}
+	return labels
+}
+
+// DatasourceSnapshots 返回配置文件中的数据源与自动发现成员的状态快照，按配置顺序排列，自动发现的成员按名称排在最后
+func (m *DBPoolManager) DatasourceSnapshots() []DatasourceSnapshot {
+	if m == nil || m.config == nil {
+		return nil
+	}
+
+	dataSources := m.config.DataSourceList()
+	configs := make([]*config.DataSourceConfig, 0, len(dataSources))
+	for i := range dataSources {
+		configs = append(configs, &dataSources[i])
+	}
+	configs = append(configs, m.DiscoveredDataSources()...)
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	result := make([]DatasourceSnapshot, 0, len(configs))
+	for _, cfg := range configs {
+		result = append(result, m.snapshotLocked(cfg))
+	}
+	return result
+}
+
+// DatasourceSnapshot 返回指定数据源的状态快照，数据源不存在时返回 false
+func (m *DBPoolManager) DatasourceSnapshot(name string) (DatasourceSnapshot, bool) {
+	if m == nil || m.config == nil || name == "" {
+		return DatasourceSnapshot{}, false
+	}
+
+	cfg := m.lookupDataSourceConfig(name)
+	if cfg == nil {
+		return DatasourceSnapshot{}, false
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.snapshotLocked(cfg), true
+}
+
+// snapshotLocked 在持有读锁的情况下构建单个数据源的快照
+func (m *DBPoolManager) snapshotLocked(cfg *config.DataSourceConfig) DatasourceSnapshot {
+	cleanHost, _, _ := normalizeDBHost(cfg.DbHost)
+	snapshot := DatasourceSnapshot{
+		Config: cfg,
+		Host:   cleanHost,
+		Labels: cfg.ParseLabels(),
+		State:  DatasourceStateUnknown,
+	}
+
+	if !cfg.Enabled {
+		snapshot.State = DatasourceStateDisabled
+		return snapshot
+	}
+
+	if pool, ok := m.pools[cfg.Name]; ok && pool != nil {
+		snapshot.State = DatasourceStateHealthy
+		snapshot.Healthy = pool.IsHealthy()
+		snapshot.LastHealthCheck = pool.LastHealthCheck()
+		if pool.Labels != nil {
+			snapshot.Labels = pool.Labels
+		}
+		if pool.DB != nil {
+			stats := pool.DB.Stats()
+			snapshot.PoolStats = &stats
+		}
+		return snapshot
+	}
+
+	if failed, ok := m.failedSources[cfg.Name]; ok && failed != nil {
+		snapshot.State = DatasourceStateFailed
+		snapshot.LastHealthCheck = failed.LastAttempt
+		snapshot.LastError = failed.LastError
+		snapshot.FailedAt = failed.FailedAt
+	}
+	return snapshot
+}
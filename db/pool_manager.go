@@ -2,6 +2,7 @@ package db
 
 import (
 	"context"
+	"dameng_exporter/cache"
 	"dameng_exporter/config"
 	"dameng_exporter/logger"
 	"database/sql"
@@ -90,28 +91,37 @@ type DatasourceHealthStatus struct {
 
 // DBPoolManager 连接池管理器
 type DBPoolManager struct {
-	pools         map[string]*DataSourcePool   // 成功列表：当前健康的连接池
-	failedSources map[string]*FailedDataSource // 失败列表：待恢复的数据源
-	config        *config.MultiSourceConfig    // 多数据源配置
-	mu            sync.RWMutex                 // 读写锁
-	logger        *zap.SugaredLogger           // 日志记录器
-	stopChan      chan struct{}                // 停止信号
-	monitorOnce   sync.Once                    // 确保后台监控只启动一次
-	stopOnce      sync.Once                    // 确保停止信号只发送一次
-	wg            sync.WaitGroup               // 等待组
+	pools          map[string]*DataSourcePool          // 成功列表：当前健康的连接池
+	failedSources  map[string]*FailedDataSource        // 失败列表：待恢复的数据源
+	discovered     map[string]*config.DataSourceConfig // 自动发现的成员数据源配置
+	config         *config.MultiSourceConfig           // 多数据源配置
+	mu             sync.RWMutex                        // 读写锁
+	logger         *zap.SugaredLogger                  // 日志记录器
+	stopChan       chan struct{}                       // 停止信号
+	monitorOnce    sync.Once                           // 确保后台监控只启动一次
+	discoveryLoops map[string]bool                     // 正在运行拓扑发现协程的种子数据源
+	dispatchOnce   sync.Once                           // 确保事件分发只启动一次
+	events         chan DatasourceEvent                // 待分发的数据源状态变化事件
+	listeners      []EventListener                     // 数据源事件监听器
+	removalHooks   []func(name string)                 // 数据源删除后清理相关状态的回调
+	stopOnce       sync.Once                           // 确保停止信号只发送一次
+	wg             sync.WaitGroup                      // 等待组
 }
 
 // 全局DBPoolManager实例
 var GlobalPoolManager *DBPoolManager
 
 // NewDBPoolManager 创建连接池管理器
-func NewDBPoolManager(config *config.MultiSourceConfig) *DBPoolManager {
+func NewDBPoolManager(multiConfig *config.MultiSourceConfig) *DBPoolManager {
 	return &DBPoolManager{
-		pools:         make(map[string]*DataSourcePool),
-		failedSources: make(map[string]*FailedDataSource),
-		config:        config,
-		logger:        logger.Logger,
-		stopChan:      make(chan struct{}),
+		pools:          make(map[string]*DataSourcePool),
+		failedSources:  make(map[string]*FailedDataSource),
+		discovered:     make(map[string]*config.DataSourceConfig),
+		discoveryLoops: make(map[string]bool),
+		config:         multiConfig,
+		logger:         logger.Logger,
+		stopChan:       make(chan struct{}),
+		events:         make(chan DatasourceEvent, eventQueueSize),
 	}
 }
 
@@ -125,6 +135,8 @@ func (m *DBPoolManager) InitPools() error {
 		if shouldStartMonitor {
 			//启动定时任务扫描失败列表的后台线程
 			m.startBackgroundMonitor()
+			//启动种子数据源的拓扑发现线程
+			m.startDiscovery()
 		}
 	}()
 
@@ -136,14 +148,16 @@ func (m *DBPoolManager) InitPools() error {
 	}
 	m.pools = make(map[string]*DataSourcePool)
 	m.failedSources = make(map[string]*FailedDataSource)
+	m.discovered = make(map[string]*config.DataSourceConfig)
 
 	// 步骤2：构建名称与地址去重索引，确保配置合法
 	nameMap := make(map[string]bool)
 	hostMap := make(map[string]string) // host -> name mapping
 
 	// 步骤3：遍历配置，为每个启用的数据源创建连接池或登记失败
-	for i := range m.config.DataSources {
-		dsConfig := &m.config.DataSources[i]
+	dataSources := m.config.DataSourceList()
+	for i := range dataSources {
+		dsConfig := &dataSources[i]
 
 		if !dsConfig.Enabled {
 			m.logger.Info("数据源被禁用，跳过初始化",
@@ -203,6 +217,8 @@ func (m *DBPoolManager) InitPools() error {
 
 	// 步骤5：更新全局实例并在解锁后启动后台监控
 	GlobalPoolManager = m
+	cache.Default.SetTTLResolver(m.cacheTTL)
+	logger.SetDatasourceLevelResolver(m.logLevel)
 	shouldStartMonitor = true
 
 	return nil
@@ -258,7 +274,7 @@ func (m *DBPoolManager) createPool(dsConfig *config.DataSourceConfig) (*DataSour
 	if hostForDatasource != "" {
 		datasourceLabel = fmt.Sprintf("%s@%s", dsConfig.Name, hostForDatasource)
 	}
-	pool.Labels["datasource"] = datasourceLabel
+	pool.Labels[DatasourceLabel] = datasourceLabel
 
 	return pool, nil
 }
@@ -364,6 +380,11 @@ func (m *DBPoolManager) noteFailedDataSourceLocked(dsConfig *config.DataSourceCo
 		return
 	}
 
+	// 已被禁用或删除的数据源不再登记失败，避免后台重试将其重新纳管
+	if !m.isManagedLocked(dsConfig) {
+		return
+	}
+
 	entry, exists := m.failedSources[dsConfig.Name]
 	message := ""
 	if err != nil {
@@ -378,6 +399,11 @@ func (m *DBPoolManager) noteFailedDataSourceLocked(dsConfig *config.DataSourceCo
 			LastAttempt: ts,
 			LastError:   message,
 		}
+
+		// 首次进入失败列表即视为一次不可用事件
+		event := newDatasourceEvent(EventDatasourceDown, dsConfig, ts)
+		event.Error = message
+		m.emitEvent(event)
 		return
 	}
 
@@ -386,6 +412,19 @@ func (m *DBPoolManager) noteFailedDataSourceLocked(dsConfig *config.DataSourceCo
 	entry.LastError = message
 }
 
+// isManagedLocked 在持有锁的情况下判断数据源配置是否仍处于纳管状态：
+// 自动发现的成员需仍在发现列表中，配置文件中的数据源需仍存在、处于启用状态且地址未变
+func (m *DBPoolManager) isManagedLocked(cfg *config.DataSourceConfig) bool {
+	if cfg.DiscoveredFrom != "" {
+		return m.discovered[cfg.Name] == cfg
+	}
+	if m.config == nil {
+		return true
+	}
+	current := m.config.GetDataSourceByName(cfg.Name)
+	return current != nil && current.Enabled && current.DbHost == cfg.DbHost
+}
+
 // noteFailedDataSource 无锁登记失败数据源，供后台任务或其他调用方使用
 func (m *DBPoolManager) noteFailedDataSource(dsConfig *config.DataSourceConfig, err error) {
 	if dsConfig == nil {
@@ -534,6 +573,11 @@ func (m *DBPoolManager) promoteToHealthy(cfg *config.DataSourceConfig, pool *Dat
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
+	// 数据源在重试期间可能已被禁用、删除或（自动发现的成员）移除，此时不再恢复
+	if !m.isManagedLocked(cfg) {
+		return false
+	}
+
 	// 清理旧的连接实例，避免句柄泄漏
 	if existing := m.pools[cfg.Name]; existing != nil {
 		if existing.DB != nil {
@@ -545,9 +589,20 @@ func (m *DBPoolManager) promoteToHealthy(cfg *config.DataSourceConfig, pool *Dat
 		}
 	}
 
+	// 连接池重建后丢弃旧的查询结果缓存，告警状态保留以便感知切换
+	cache.Default.InvalidateDataSource(cfg.Name, cache.KindBigKey)
+
 	// 将新连接加入健康列表并移除失败记录
-	pool.markHealthy(time.Now())
+	now := time.Now()
+	pool.markHealthy(now)
 	m.pools[cfg.Name] = pool
+	if failed, ok := m.failedSources[cfg.Name]; ok && failed != nil {
+		// 从失败列表恢复时发出恢复事件，附带不可用时长与最近一次错误
+		event := newDatasourceEvent(EventDatasourceUp, cfg, now)
+		event.Error = failed.LastError
+		event.Downtime = now.Sub(failed.FailedAt)
+		m.emitEvent(event)
+	}
 	delete(m.failedSources, cfg.Name)
 	return true
 }
@@ -600,6 +655,11 @@ func (m *DBPoolManager) demoteToFailed(pool *DataSourcePool, reason error) {
 
 	m.mu.Lock()
 	pool.markUnhealthy(now)
+	// 并发降级时只有第一次生效，避免重复登记失败
+	if current := m.pools[pool.Name]; current != pool {
+		m.mu.Unlock()
+		return
+	}
 	delete(m.pools, pool.Name)
 
 	if pool.DB != nil {
@@ -643,7 +703,7 @@ func (m *DBPoolManager) MarkDatasourceFailed(name string, reason error) {
 
 	// 如果健康列表中不存在，说明已降级或尚未初始化，补充失败记录以便后台重试
 	if m.config != nil {
-		if cfg := m.config.GetDataSourceByName(name); cfg != nil {
+		if cfg := m.lookupDataSourceConfig(name); cfg != nil {
 			if reason != nil {
 				m.logger.Warn("采集器检测到连接异常，记录失败等待自动恢复",
 					zap.String("datasource", name),
@@ -657,6 +717,44 @@ func (m *DBPoolManager) MarkDatasourceFailed(name string, reason error) {
 	}
 }
 
+// lookupDataSourceConfig 按名称查找数据源配置，包含配置文件中的数据源与自动发现的成员
+func (m *DBPoolManager) lookupDataSourceConfig(name string) *config.DataSourceConfig {
+	if cfg := m.config.GetDataSourceByName(name); cfg != nil {
+		return cfg
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.discovered[name]
+}
+
+// cacheTTL 按数据源配置解析缓存过期时间，供全局缓存使用
+func (m *DBPoolManager) cacheTTL(dataSource string, kind cache.Kind) time.Duration {
+	if m == nil || m.config == nil {
+		return 0
+	}
+	cfg := m.lookupDataSourceConfig(dataSource)
+	if cfg == nil {
+		return 0
+	}
+	if kind == cache.KindAlarm {
+		return time.Duration(cfg.AlarmKeyCacheTime) * time.Minute
+	}
+	return time.Duration(cfg.BigKeyDataCacheTime) * time.Minute
+}
+
+// logLevel 返回数据源配置的日志级别，供数据源日志使用；为空表示使用全局级别
+func (m *DBPoolManager) logLevel(dataSource string) string {
+	if m == nil || m.config == nil {
+		return ""
+	}
+	cfg := m.lookupDataSourceConfig(dataSource)
+	if cfg == nil {
+		return ""
+	}
+	return cfg.LogLevel
+}
+
 // GetDatasourceHealthStatus 返回指定数据源的健康状态快照
 func (m *DBPoolManager) GetDatasourceHealthStatus(name string) DatasourceHealthStatus {
 	status := DatasourceHealthStatus{}
@@ -755,4 +853,5 @@ func (m *DBPoolManager) Close() {
 
 	m.pools = make(map[string]*DataSourcePool)
 	m.failedSources = make(map[string]*FailedDataSource)
+	m.discovered = make(map[string]*config.DataSourceConfig)
 }
@@ -0,0 +1,192 @@
+package logger
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// DatasourceLogger 数据源相关的日志记录器：支持按数据源覆盖日志级别，
+// 警告与错误按 (数据源, 消息模板) 去重，避免数据源不可用时每个采集器在每次抓取中重复输出相同日志
+type DatasourceLogger struct {
+	name     string
+	dedupKey string // 附加到去重键中的内容，用于区分共用同一模板的不同日志
+}
+
+// ForDatasource 返回指定数据源的日志记录器
+func ForDatasource(name string) DatasourceLogger {
+	return DatasourceLogger{name: name}
+}
+
+// DedupBy 返回按 (数据源, 消息模板, key) 去重的日志记录器，用于多种不同错误共用同一模板的场景，
+// 避免一种持续出现的错误掩盖其他错误
+func (l DatasourceLogger) DedupBy(key string) DatasourceLogger {
+	l.dedupKey = key
+	return l
+}
+
+// Debugf 输出调试日志
+func (l DatasourceLogger) Debugf(template string, args ...interface{}) {
+	l.logf(zapcore.DebugLevel, template, args)
+}
+
+// Infof 输出信息日志
+func (l DatasourceLogger) Infof(template string, args ...interface{}) {
+	l.logf(zapcore.InfoLevel, template, args)
+}
+
+// Warnf 输出警告日志，同一模板在去重周期内只输出首条
+func (l DatasourceLogger) Warnf(template string, args ...interface{}) {
+	l.logf(zapcore.WarnLevel, template, args)
+}
+
+// Errorf 输出错误日志，同一模板在去重周期内只输出首条
+func (l DatasourceLogger) Errorf(template string, args ...interface{}) {
+	l.logf(zapcore.ErrorLevel, template, args)
+}
+
+func (l DatasourceLogger) logf(level zapcore.Level, template string, args []interface{}) {
+	base := datasourceBase
+	if base == nil || level < datasourceLevel(l.name) {
+		return
+	}
+	if level < zapcore.WarnLevel {
+		base.Logf(level, template, args...)
+		return
+	}
+	message := fmt.Sprintf(template, args...)
+	if !dedup.allow(dedupKey{dataSource: l.name, template: template, extra: l.dedupKey}, message, level) {
+		return
+	}
+	base.Log(level, message)
+}
+
+var (
+	// datasourceBase 数据源日志使用的记录器，不受全局日志级别限制，由 datasourceLevel 决定是否输出
+	datasourceBase *zap.SugaredLogger
+	// dedupLogger 输出去重汇总的记录器，不输出调用者信息
+	dedupLogger *zap.SugaredLogger
+
+	levelResolverMu sync.RWMutex
+	// levelResolver 按数据源名称返回配置的日志级别，为空表示使用全局级别
+	levelResolver func(dataSource string) string
+)
+
+// SetDatasourceLevelResolver 设置数据源日志级别的查询函数，使运行时新增与自动发现的数据源同样生效
+func SetDatasourceLevelResolver(resolver func(dataSource string) string) {
+	levelResolverMu.Lock()
+	levelResolver = resolver
+	levelResolverMu.Unlock()
+}
+
+// datasourceLevel 返回数据源生效的日志级别：运行时设置优先，其次为配置文件中的数据源级别，最后为全局级别
+func datasourceLevel(dataSource string) zapcore.Level {
+	if level, ok := overrideLevel(dataSource); ok {
+		return level
+	}
+	levelResolverMu.RLock()
+	resolver := levelResolver
+	levelResolverMu.RUnlock()
+	if resolver != nil && dataSource != "" {
+		if level := resolver(dataSource); level != "" {
+			return getLogLevel(level)
+		}
+	}
+	return atomicLevel.Level()
+}
+
+// dedupKey 去重键
+type dedupKey struct {
+	dataSource string
+	template   string
+	extra      string
+}
+
+// dedupEntry 去重周期内的日志记录
+type dedupEntry struct {
+	level       zapcore.Level
+	windowStart time.Time
+	suppressed  int
+	last        string // 最近一条被抑制的日志内容，汇总时输出
+}
+
+// deduper 按 (数据源, 消息模板) 去重：周期内首条正常输出，其余只计数，周期结束时输出被抑制的条数
+type deduper struct {
+	mu       sync.Mutex
+	interval time.Duration
+	entries  map[dedupKey]*dedupEntry
+}
+
+// dedup 数据源日志去重器，周期为0时不去重
+var dedup = &deduper{entries: make(map[dedupKey]*dedupEntry)}
+
+// start 设置去重周期并启动定期汇总
+func (d *deduper) start(interval time.Duration) {
+	d.mu.Lock()
+	d.interval = interval
+	d.mu.Unlock()
+	if interval <= 0 {
+		return
+	}
+
+	tick := max(interval/4, time.Second)
+	go func() {
+		ticker := time.NewTicker(tick)
+		defer ticker.Stop()
+		for now := range ticker.C {
+			d.flush(now, false)
+		}
+	}()
+}
+
+// allow 判断日志是否应输出，被抑制时计数并记录最近一条日志内容
+func (d *deduper) allow(key dedupKey, message string, level zapcore.Level) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.interval <= 0 {
+		return true
+	}
+	if e, ok := d.entries[key]; ok {
+		e.suppressed++
+		e.last = message
+		return false
+	}
+	d.entries[key] = &dedupEntry{level: level, windowStart: time.Now()}
+	return true
+}
+
+// flush 对周期已结束的记录输出被抑制的条数；周期内没有重复的记录被移除，下次出现时重新输出首条。
+// all 为 true 时忽略周期，用于退出前输出全部汇总
+func (d *deduper) flush(now time.Time, all bool) {
+	type summary struct {
+		key   dedupKey
+		entry dedupEntry
+	}
+	var summaries []summary
+
+	d.mu.Lock()
+	for key, e := range d.entries {
+		if !all && now.Sub(e.windowStart) < d.interval {
+			continue
+		}
+		if e.suppressed == 0 {
+			delete(d.entries, key)
+			continue
+		}
+		summaries = append(summaries, summary{key: key, entry: *e})
+		e.suppressed = 0
+		e.windowStart = now
+	}
+	d.mu.Unlock()
+
+	if dedupLogger == nil {
+		return
+	}
+	for _, s := range summaries {
+		dedupLogger.Logf(s.entry.level, "[%s] Suppressed %d similar messages in the last %s, last: %s",
+			s.key.dataSource, s.entry.suppressed, now.Sub(s.entry.windowStart).Round(time.Second), s.entry.last)
+	}
+}
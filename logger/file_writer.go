@@ -0,0 +1,109 @@
+package logger
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+const (
+	// logFilePrefix 日志文件名前缀，完整文件名为 dameng_exporter_<日期>.log
+	logFilePrefix = "dameng_exporter_"
+	// logDateLayout 日志文件名中的日期格式
+	logDateLayout = "2006-01-02"
+)
+
+// dailyFileWriter 按日期切换的日志文件：每天写入 dameng_exporter_<日期>.log，
+// 单个文件超过大小上限时由 lumberjack 切割并压缩，超过保留天数的历史日志在切换日期时删除
+type dailyFileWriter struct {
+	dir        string
+	maxSize    int // 单个文件大小上限（MB）
+	maxBackups int // 同一天保留的切割文件数量
+	maxAge     int // 保留天数
+
+	mu      sync.Mutex
+	date    string
+	current *lumberjack.Logger
+}
+
+// newDailyFileWriter 创建按日期切换的日志文件，目录不存在时自动创建
+func newDailyFileWriter(dir string, maxSize, maxBackups, maxAge int) (*dailyFileWriter, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("create log directory %s: %w", dir, err)
+	}
+	return &dailyFileWriter{dir: dir, maxSize: maxSize, maxBackups: maxBackups, maxAge: maxAge}, nil
+}
+
+// Write 写入当天的日志文件，日期变化时先切换文件
+func (w *dailyFileWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	now := time.Now()
+	if today := now.Format(logDateLayout); w.current == nil || today != w.date {
+		w.switchLocked(today, now)
+	}
+	return w.current.Write(p)
+}
+
+// Sync 日志直接写入文件，无需刷新
+func (w *dailyFileWriter) Sync() error {
+	return nil
+}
+
+// Close 关闭当前日志文件
+func (w *dailyFileWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.current == nil {
+		return nil
+	}
+	return w.current.Close()
+}
+
+// switchLocked 关闭前一天的日志文件并打开当天的文件，调用方需持有锁
+func (w *dailyFileWriter) switchLocked(today string, now time.Time) {
+	if w.current != nil {
+		_ = w.current.Close()
+	}
+	w.date = today
+	w.current = &lumberjack.Logger{
+		Filename:   filepath.Join(w.dir, logFilePrefix+today+".log"),
+		MaxSize:    w.maxSize,
+		MaxBackups: w.maxBackups,
+		MaxAge:     w.maxAge,
+		LocalTime:  true,
+		Compress:   true,
+	}
+	w.removeExpired(now)
+}
+
+// removeExpired 删除日期早于保留天数的日志文件（含切割后的压缩文件）
+func (w *dailyFileWriter) removeExpired(now time.Time) {
+	if w.maxAge <= 0 {
+		return
+	}
+	cutoff := now.AddDate(0, 0, -w.maxAge).Format(logDateLayout)
+	files, err := filepath.Glob(filepath.Join(w.dir, logFilePrefix+"*"))
+	if err != nil {
+		return
+	}
+	for _, file := range files {
+		name := strings.TrimPrefix(filepath.Base(file), logFilePrefix)
+		if len(name) < len(logDateLayout) {
+			continue
+		}
+		date := name[:len(logDateLayout)]
+		if _, err := time.Parse(logDateLayout, date); err != nil {
+			continue
+		}
+		// 日期格式可按字符串比较先后
+		if date < cutoff {
+			_ = os.Remove(file)
+		}
+	}
+}
@@ -0,0 +1,223 @@
+package logger
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+var (
+	// atomicLevel 全局日志级别，可在运行时修改
+	atomicLevel = zap.NewAtomicLevelAt(zapcore.InfoLevel)
+	// configuredLevel 配置文件中的全局日志级别，临时修改到期后恢复为该级别
+	configuredLevel = zapcore.InfoLevel
+
+	levelMu sync.Mutex
+	// levelExpires 全局日志级别临时修改的到期时间，零值表示不会自动恢复
+	levelExpires time.Time
+	// levelTimer 全局日志级别的恢复定时器
+	levelTimer *time.Timer
+	// overrides 运行时设置的数据源日志级别，优先于配置文件中的数据源 logLevel
+	overrides = make(map[string]*levelOverride)
+	// levelLogger 记录日志级别变更，不受全局日志级别限制
+	levelLogger = zap.NewNop().Sugar()
+)
+
+// levelOverride 运行时设置的数据源日志级别
+type levelOverride struct {
+	level   zapcore.Level
+	expires time.Time // 零值表示不会自动恢复
+	timer   *time.Timer
+}
+
+// LevelInfo 日志级别设置
+type LevelInfo struct {
+	Datasource string     `json:"datasource,omitempty"`
+	Level      string     `json:"level"`
+	ExpiresAt  *time.Time `json:"expiresAt,omitempty"` // 临时设置的到期时间，到期后恢复
+}
+
+// LevelStatus 当前日志级别
+type LevelStatus struct {
+	Level           string      `json:"level"`
+	ConfiguredLevel string      `json:"configuredLevel"`
+	ExpiresAt       *time.Time  `json:"expiresAt,omitempty"`
+	Datasources     []LevelInfo `json:"datasources"` // 运行时设置的数据源日志级别
+}
+
+// ParseLevel 解析日志级别名称
+func ParseLevel(level string) (zapcore.Level, error) {
+	switch strings.ToLower(strings.TrimSpace(level)) {
+	case "debug":
+		return zapcore.DebugLevel, nil
+	case "info":
+		return zapcore.InfoLevel, nil
+	case "warn":
+		return zapcore.WarnLevel, nil
+	case "error":
+		return zapcore.ErrorLevel, nil
+	default:
+		return zapcore.InfoLevel, fmt.Errorf("invalid log level %q, must be debug, info, warn or error", level)
+	}
+}
+
+// initLevel 按配置设置全局日志级别
+func initLevel(level zapcore.Level) {
+	levelMu.Lock()
+	defer levelMu.Unlock()
+	configuredLevel = level
+	atomicLevel.SetLevel(level)
+}
+
+// SetLevel 修改全局日志级别，duration 大于0时到期后恢复为配置的级别
+func SetLevel(level zapcore.Level, duration time.Duration) {
+	levelMu.Lock()
+	defer levelMu.Unlock()
+	setLevelLocked(level, duration)
+}
+
+// setLevelLocked 修改全局日志级别，调用方需持有锁
+func setLevelLocked(level zapcore.Level, duration time.Duration) {
+	if levelTimer != nil {
+		levelTimer.Stop()
+		levelTimer = nil
+	}
+	levelExpires = time.Time{}
+	atomicLevel.SetLevel(level)
+	if duration <= 0 {
+		levelLogger.Infof("Log level set to %s", level)
+		return
+	}
+	levelLogger.Infof("Log level set to %s for %s", level, duration)
+
+	levelExpires = time.Now().Add(duration)
+	var timer *time.Timer
+	timer = time.AfterFunc(duration, func() {
+		levelMu.Lock()
+		defer levelMu.Unlock()
+		// 定时器已被新的设置替换
+		if levelTimer != timer {
+			return
+		}
+		levelTimer = nil
+		levelExpires = time.Time{}
+		atomicLevel.SetLevel(configuredLevel)
+		levelLogger.Infof("Log level reverted to %s", configuredLevel)
+	})
+	levelTimer = timer
+}
+
+// ToggleDebug 在 debug 与配置的级别之间切换全局日志级别，返回切换后的级别
+func ToggleDebug() zapcore.Level {
+	levelMu.Lock()
+	defer levelMu.Unlock()
+	level := zapcore.DebugLevel
+	if atomicLevel.Level() == zapcore.DebugLevel {
+		level = configuredLevel
+	}
+	setLevelLocked(level, 0)
+	return level
+}
+
+// SetDatasourceLevel 修改指定数据源相关日志的级别，duration 大于0时到期后恢复
+func SetDatasourceLevel(dataSource string, level zapcore.Level, duration time.Duration) {
+	levelMu.Lock()
+	defer levelMu.Unlock()
+	removeOverrideLocked(dataSource)
+	o := &levelOverride{level: level}
+	if duration > 0 {
+		o.expires = time.Now().Add(duration)
+		o.timer = time.AfterFunc(duration, func() {
+			levelMu.Lock()
+			defer levelMu.Unlock()
+			// 设置已被替换或清除
+			if overrides[dataSource] != o {
+				return
+			}
+			delete(overrides, dataSource)
+			levelLogger.Infof("[%s] Datasource log level override expired", dataSource)
+		})
+		levelLogger.Infof("[%s] Datasource log level set to %s for %s", dataSource, level, duration)
+	} else {
+		levelLogger.Infof("[%s] Datasource log level set to %s", dataSource, level)
+	}
+	overrides[dataSource] = o
+}
+
+// ResetDatasourceLevel 清除数据源的运行时日志级别，恢复为配置的级别，返回是否存在该设置
+func ResetDatasourceLevel(dataSource string) bool {
+	levelMu.Lock()
+	defer levelMu.Unlock()
+	if !removeOverrideLocked(dataSource) {
+		return false
+	}
+	levelLogger.Infof("[%s] Datasource log level override removed", dataSource)
+	return true
+}
+
+// removeOverrideLocked 清除数据源的运行时日志级别，调用方需持有锁
+func removeOverrideLocked(dataSource string) bool {
+	o, ok := overrides[dataSource]
+	if !ok {
+		return false
+	}
+	if o.timer != nil {
+		o.timer.Stop()
+	}
+	delete(overrides, dataSource)
+	return true
+}
+
+// overrideLevel 返回数据源的运行时日志级别
+func overrideLevel(dataSource string) (zapcore.Level, bool) {
+	levelMu.Lock()
+	defer levelMu.Unlock()
+	o, ok := overrides[dataSource]
+	if !ok {
+		return zapcore.InfoLevel, false
+	}
+	return o.level, true
+}
+
+// GetLevelStatus 返回当前的全局日志级别与运行时设置的数据源日志级别
+func GetLevelStatus() LevelStatus {
+	levelMu.Lock()
+	defer levelMu.Unlock()
+	status := LevelStatus{
+		Level:           atomicLevel.Level().String(),
+		ConfiguredLevel: configuredLevel.String(),
+		ExpiresAt:       expiresAt(levelExpires),
+		Datasources:     make([]LevelInfo, 0, len(overrides)),
+	}
+	for name, o := range overrides {
+		status.Datasources = append(status.Datasources, LevelInfo{
+			Datasource: name,
+			Level:      o.level.String(),
+			ExpiresAt:  expiresAt(o.expires),
+		})
+	}
+	sort.Slice(status.Datasources, func(i, j int) bool {
+		return status.Datasources[i].Datasource < status.Datasources[j].Datasource
+	})
+	return status
+}
+
+// expiresAt 零值时间转换为 nil
+func expiresAt(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
+
+// GetConfiguredLevel 返回配置文件中的全局日志级别
+func GetConfiguredLevel() zapcore.Level {
+	levelMu.Lock()
+	defer levelMu.Unlock()
+	return configuredLevel
+}
@@ -0,0 +1,21 @@
+//go:build !windows
+
+package logger
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// WatchDebugSignal 收到 SIGUSR1 时在 debug 与配置的日志级别之间切换全局日志级别
+func WatchDebugSignal() {
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, syscall.SIGUSR1)
+	go func() {
+		for range ch {
+			levelLogger.Infof("Received SIGUSR1, toggling debug logging")
+			ToggleDebug()
+		}
+	}()
+}
@@ -0,0 +1,4 @@
+package logger
+
+// WatchDebugSignal Windows 不支持 SIGUSR1，只能通过 /-/loglevel 接口修改日志级别
+func WatchDebugSignal() {}
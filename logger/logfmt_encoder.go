@@ -0,0 +1,118 @@
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+)
+
+// logfmtPool logfmt 编码器使用的缓冲池
+var logfmtPool = buffer.NewPool()
+
+// logfmtEncoder 以 key=value 格式输出日志，固定字段在前，其余字段按名称排序
+type logfmtEncoder struct {
+	*zapcore.MapObjectEncoder
+	cfg zapcore.EncoderConfig
+}
+
+// newLogfmtEncoder 创建 logfmt 编码器
+func newLogfmtEncoder(cfg zapcore.EncoderConfig) zapcore.Encoder {
+	return &logfmtEncoder{MapObjectEncoder: zapcore.NewMapObjectEncoder(), cfg: cfg}
+}
+
+// Clone 实现 zapcore.Encoder 接口，复制已添加的上下文字段
+func (e *logfmtEncoder) Clone() zapcore.Encoder {
+	clone := zapcore.NewMapObjectEncoder()
+	for k, v := range e.Fields {
+		clone.Fields[k] = v
+	}
+	return &logfmtEncoder{MapObjectEncoder: clone, cfg: e.cfg}
+}
+
+// EncodeEntry 实现 zapcore.Encoder 接口
+func (e *logfmtEncoder) EncodeEntry(ent zapcore.Entry, fields []zapcore.Field) (*buffer.Buffer, error) {
+	enc := e.Clone().(*logfmtEncoder)
+	for _, f := range fields {
+		f.AddTo(enc)
+	}
+
+	buf := logfmtPool.Get()
+	writeLogfmtPair(buf, e.cfg.TimeKey, ent.Time.Format("2006-01-02T15:04:05.000Z07:00"))
+	writeLogfmtPair(buf, e.cfg.LevelKey, ent.Level.String())
+	if ent.Caller.Defined && e.cfg.CallerKey != "" {
+		writeLogfmtPair(buf, e.cfg.CallerKey, ent.Caller.TrimmedPath())
+	}
+	writeLogfmtPair(buf, e.cfg.MessageKey, ent.Message)
+
+	keys := make([]string, 0, len(enc.Fields))
+	for k := range enc.Fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		writeLogfmtPair(buf, k, formatLogfmtValue(enc.Fields[k]))
+	}
+	if ent.Stack != "" && e.cfg.StacktraceKey != "" {
+		writeLogfmtPair(buf, e.cfg.StacktraceKey, ent.Stack)
+	}
+	buf.AppendString(e.cfg.LineEnding)
+	return buf, nil
+}
+
+// writeLogfmtPair 追加一个 key=value，值包含空格、等号、引号或不可打印字符时加引号
+func writeLogfmtPair(buf *buffer.Buffer, key, value string) {
+	if key == "" {
+		return
+	}
+	if buf.Len() > 0 {
+		buf.AppendByte(' ')
+	}
+	buf.AppendString(key)
+	buf.AppendByte('=')
+	if needsLogfmtQuote(value) {
+		buf.AppendString(strconv.Quote(value))
+	} else {
+		buf.AppendString(value)
+	}
+}
+
+// needsLogfmtQuote 判断值是否需要加引号
+func needsLogfmtQuote(s string) bool {
+	if s == "" {
+		return true
+	}
+	return strings.IndexFunc(s, func(r rune) bool {
+		return r <= ' ' || r == '=' || r == '"' || !unicode.IsPrint(r)
+	}) >= 0
+}
+
+// formatLogfmtValue 将字段值格式化为字符串，对象与数组使用 JSON
+func formatLogfmtValue(v any) string {
+	switch val := v.(type) {
+	case string:
+		return val
+	case time.Time:
+		return val.Format(time.RFC3339Nano)
+	case time.Duration:
+		return val.String()
+	case error:
+		return val.Error()
+	case fmt.Stringer:
+		return val.String()
+	case map[string]any, []any:
+		data, err := json.Marshal(val)
+		if err != nil {
+			return fmt.Sprint(val)
+		}
+		return string(data)
+	default:
+		return fmt.Sprint(val)
+	}
+}
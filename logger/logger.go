@@ -9,7 +9,6 @@ import (
 
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
-	"gopkg.in/natefinch/lumberjack.v2"
 )
 
 var Logger *zap.SugaredLogger // 全局日志记录器实例
@@ -30,25 +29,59 @@ func getLogLevel(level string) zapcore.Level {
 	}
 }
 
-// InitLogger 初始化并配置全局日志记录器
+// InitLogger 初始化并配置全局日志记录器，按 logOutput 输出到文件和/或标准输出
 func InitLogger() {
-	currentTime := time.Now().Format("2006-01-02")                           // 格式化为 "YYYYMMDD_HHmmss"
-	logFileName := fmt.Sprintf("./logs/dameng_exporter_%s.log", currentTime) // 日志文件路径
+	output := config.Global.GetLogOutput()
+	format := config.Global.GetLogFormat()
+	logLevel := getLogLevel(config.Global.GetLogLevel())
+	// 核心按最低级别创建，全局记录器再按可在运行时修改的级别过滤，使数据源可以单独调低日志级别
+	coreLevel := zapcore.DebugLevel
 
-	// 配置日志切割器
-	lumberjackLogger := &lumberjack.Logger{
-		Filename:   logFileName,                      // 日志文件路径
-		MaxSize:    config.Global.GetLogMaxSize(),    // 使用配置的日志文件大小
-		MaxBackups: config.Global.GetLogMaxBackups(), // 使用配置的备份数量
-		MaxAge:     config.Global.GetLogMaxAge(),     // 使用配置的保留天数
-		Compress:   true,                             // 启用日志文件压缩
+	var cores []zapcore.Core
+	if output != config.LogOutputStdout {
+		fileWriter, err := newDailyFileWriter(
+			config.Global.GetLogDir(),
+			config.Global.GetLogMaxSize(),    // 使用配置的日志文件大小
+			config.Global.GetLogMaxBackups(), // 使用配置的备份数量
+			config.Global.GetLogMaxAge(),     // 使用配置的保留天数
+		)
+		if err != nil {
+			// 日志目录不可用时仍输出到标准输出，避免丢失日志
+			fmt.Fprintf(os.Stderr, "Failed to open log directory, logging to stdout only: %v\n", err)
+			output = config.LogOutputStdout
+		} else {
+			fileFormat := format
+			if fileFormat == config.LogFormatAuto {
+				fileFormat = config.LogFormatJSON
+			}
+			// 文件中不输出颜色控制符
+			cores = append(cores, zapcore.NewCore(newEncoder(fileFormat, false), fileWriter, coreLevel))
+		}
+	}
+	if output != config.LogOutputFile {
+		stdoutFormat := format
+		if stdoutFormat == config.LogFormatAuto {
+			stdoutFormat = config.LogFormatConsole
+		}
+		// 仅在终端中以 console 格式输出时使用颜色
+		color := stdoutFormat == config.LogFormatConsole && isTerminal(os.Stdout)
+		cores = append(cores, zapcore.NewCore(newEncoder(stdoutFormat, color), zapcore.Lock(os.Stdout), coreLevel))
 	}
 
-	// 创建日志写入目标
-	fileWriter := zapcore.AddSync(lumberjackLogger)
-	consoleWriter := zapcore.AddSync(os.Stdout)
+	// 创建日志记录器实例，并添加调用信息和堆栈跟踪
+	logger := zap.New(zapcore.NewTee(cores...), zap.AddCaller(), zap.AddStacktrace(zapcore.ErrorLevel))
+	initLevel(logLevel)
+	Logger = logger.WithOptions(zap.IncreaseLevel(atomicLevel)).Sugar()
 
-	// 创建紧凑的编码器配置
+	// 数据源日志经过 DatasourceLogger 的两层调用，调用者信息需跳过这两层
+	datasourceBase = logger.WithOptions(zap.AddCallerSkip(2)).Sugar()
+	dedupLogger = logger.WithOptions(zap.WithCaller(false)).Sugar()
+	levelLogger = logger.Sugar()
+	dedup.start(time.Duration(config.Global.GetLogDedupIntervalSeconds()) * time.Second)
+}
+
+// newEncoder 创建指定格式的编码器，color 为 true 时日志级别带颜色输出
+func newEncoder(format string, color bool) zapcore.Encoder {
 	encoderConfig := zapcore.EncoderConfig{
 		TimeKey:        "ts",
 		LevelKey:       "level",
@@ -57,28 +90,29 @@ func InitLogger() {
 		MessageKey:     "msg",
 		StacktraceKey:  "stacktrace",
 		LineEnding:     zapcore.DefaultLineEnding,
-		EncodeLevel:    zapcore.CapitalColorLevelEncoder, // 日志级别带颜色输出
-		EncodeTime:     customTimeEncoder,                // ISO8601 时间格式
+		EncodeLevel:    zapcore.CapitalLevelEncoder,
+		EncodeTime:     customTimeEncoder,
 		EncodeDuration: zapcore.StringDurationEncoder,
 		EncodeCaller:   zapcore.ShortCallerEncoder, // 简短调用者信息
 	}
+	if color {
+		encoderConfig.EncodeLevel = zapcore.CapitalColorLevelEncoder
+	}
 
-	// 创建文件和控制台编码器
-	fileEncoder := zapcore.NewJSONEncoder(encoderConfig)
-	consoleEncoder := zapcore.NewConsoleEncoder(encoderConfig)
-
-	// 获取配置的日志级别
-	logLevel := getLogLevel(config.Global.GetLogLevel())
-
-	// 创建 zap 核心
-	core := zapcore.NewTee(
-		zapcore.NewCore(fileEncoder, zapcore.NewMultiWriteSyncer(fileWriter), logLevel),
-		zapcore.NewCore(consoleEncoder, zapcore.NewMultiWriteSyncer(consoleWriter), logLevel),
-	)
+	switch format {
+	case config.LogFormatConsole:
+		return zapcore.NewConsoleEncoder(encoderConfig)
+	case config.LogFormatLogfmt:
+		return newLogfmtEncoder(encoderConfig)
+	default:
+		return zapcore.NewJSONEncoder(encoderConfig)
+	}
+}
 
-	// 创建日志记录器实例，并添加调用信息和堆栈跟踪
-	logger := zap.New(core, zap.AddCaller(), zap.AddStacktrace(zapcore.ErrorLevel))
-	Logger = logger.Sugar()
+// isTerminal 判断文件是否为终端
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
 }
 
 func customTimeEncoder(t time.Time, enc zapcore.PrimitiveArrayEncoder) {
@@ -88,6 +122,8 @@ func customTimeEncoder(t time.Time, enc zapcore.PrimitiveArrayEncoder) {
 // Sync 确保所有缓冲日志条目在程序退出前被刷新
 func Sync() {
 	if Logger != nil {
+		// 输出尚未汇总的被抑制日志条数
+		dedup.flush(time.Now(), true)
 		_ = Logger.Sync() // 刷新日志，确保日志被完整输出
 	}
 }
@@ -0,0 +1,70 @@
+package notify
+
+import (
+	"dameng_exporter/db"
+	"sync"
+)
+
+// Record 事件历史中的一条记录
+type Record struct {
+	db.DatasourceEvent
+	DowntimeSeconds float64 `json:"downtimeSeconds,omitempty"` // 恢复事件对应的不可用时长（秒）
+	Suppressed      bool    `json:"suppressed"`                // 是否因去重未发送通知
+}
+
+// History 有容量上限的事件历史，超出后覆盖最旧的记录
+type History struct {
+	mu      sync.RWMutex
+	records []Record
+	next    int  // 下一条记录写入的位置
+	full    bool // 缓冲区是否已写满
+}
+
+// NewHistory 创建事件历史，size<=0 时使用默认容量
+func NewHistory(size int) *History {
+	if size <= 0 {
+		size = defaultHistorySize
+	}
+	return &History{records: make([]Record, size)}
+}
+
+// Add 追加一条记录
+func (h *History) Add(record Record) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.records[h.next] = record
+	h.next = (h.next + 1) % len(h.records)
+	if h.next == 0 {
+		h.full = true
+	}
+}
+
+// List 按时间倒序返回记录；dataSource 为空表示全部数据源，limit<=0 表示不限制数量
+func (h *History) List(dataSource string, limit int) []Record {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	count := h.next
+	if h.full {
+		count = len(h.records)
+	}
+
+	result := make([]Record, 0)
+	for i := 0; i < count; i++ {
+		idx := (h.next - 1 - i + len(h.records)) % len(h.records)
+		record := h.records[idx]
+		if dataSource != "" && record.DataSource != dataSource {
+			continue
+		}
+		result = append(result, record)
+		if limit > 0 && len(result) >= limit {
+			break
+		}
+	}
+	return result
+}
+
+// Capacity 返回历史容量
+func (h *History) Capacity() int {
+	return len(h.records)
+}
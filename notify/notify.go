@@ -0,0 +1,110 @@
+package notify
+
+import (
+	"dameng_exporter/config"
+	"dameng_exporter/db"
+	"dameng_exporter/logger"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	defaultHistorySize = 200
+	// closeTimeout 停机时等待队列中通知发送完成的最长时间
+	closeTimeout = 5 * time.Second
+)
+
+// Manager 数据源状态变化通知管理器：记录事件历史、去重并分发到各 Webhook
+type Manager struct {
+	history     *History
+	senders     []*sender
+	dedupWindow time.Duration
+
+	mu       sync.Mutex
+	lastSent map[string]sentEvent // 数据源 -> 最近一次发送的事件
+}
+
+// sentEvent 最近一次发送的事件，用于判断数据源状态是否变化
+type sentEvent struct {
+	eventType db.DatasourceEventType
+	err       string
+	at        time.Time
+}
+
+// NewManager 根据 Webhook 配置创建通知管理器
+func NewManager(webhooks []config.WebhookConfig, dedupWindow time.Duration, historySize int) *Manager {
+	m := &Manager{
+		history:     NewHistory(historySize),
+		dedupWindow: dedupWindow,
+		lastSent:    make(map[string]sentEvent),
+	}
+	for _, webhook := range webhooks {
+		m.senders = append(m.senders, newSender(webhook))
+	}
+	return m
+}
+
+// History 返回事件历史
+func (m *Manager) History() *History {
+	return m.history
+}
+
+// Handle 处理数据源事件，可直接注册为 db.EventListener
+func (m *Manager) Handle(event db.DatasourceEvent) {
+	suppressed := m.isDuplicate(event)
+	m.history.Add(Record{
+		DatasourceEvent: event,
+		DowntimeSeconds: event.DowntimeSeconds(),
+		Suppressed:      suppressed,
+	})
+
+	if suppressed {
+		logger.Logger.Debug("相同的数据源事件在去重窗口内已通知，跳过发送",
+			zap.String("datasource", event.DataSource),
+			zap.String("type", string(event.Type)))
+		return
+	}
+
+	for _, s := range m.senders {
+		s.enqueue(event)
+	}
+}
+
+// isDuplicate 判断事件是否与该数据源最近一次发送的事件类型和错误信息相同且仍在去重窗口内。
+// 只与最近一次发送的事件比较，状态变化（如 down → up → down）总会发送，确保最后收到的通知与当前状态一致
+func (m *Manager) isDuplicate(event db.DatasourceEvent) bool {
+	if m.dedupWindow <= 0 {
+		return false
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// 顺带清理已超出窗口的记录，避免长期运行后无限增长
+	for ds, sent := range m.lastSent {
+		if event.Time.Sub(sent.at) >= m.dedupWindow {
+			delete(m.lastSent, ds)
+		}
+	}
+
+	if last, ok := m.lastSent[event.DataSource]; ok && last.eventType == event.Type && last.err == event.Error {
+		return true
+	}
+	m.lastSent[event.DataSource] = sentEvent{eventType: event.Type, err: event.Error, at: event.Time}
+	return false
+}
+
+// Close 停止接收新通知，等待队列中的通知发送完成，超时后放弃剩余通知
+func (m *Manager) Close() {
+	var wg sync.WaitGroup
+	for _, s := range m.senders {
+		wg.Add(1)
+		go func(s *sender) {
+			defer wg.Done()
+			s.close(closeTimeout)
+		}(s)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,317 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"dameng_exporter/config"
+	"dameng_exporter/db"
+	"dameng_exporter/logger"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// senderQueueSize 单个 Webhook 的待发送队列长度，队列满时丢弃新通知
+	senderQueueSize = 100
+	// 重试退避时间：首次1秒，每次翻倍，最长30秒
+	initialBackoff = time.Second
+	maxBackoff     = 30 * time.Second
+	// maxResponseBody 读取响应体的最大字节数，用于解析机器人返回的错误码
+	maxResponseBody = 64 * 1024
+)
+
+// sender 单个 Webhook 的异步发送器
+type sender struct {
+	cfg    config.WebhookConfig
+	client *http.Client
+	queue  chan db.DatasourceEvent
+
+	ctx    context.Context // 停机超时后取消，中断正在进行的重试
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	mu     sync.Mutex
+	closed bool
+}
+
+// newSender 创建并启动发送器
+func newSender(cfg config.WebhookConfig) *sender {
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &sender{
+		cfg:    cfg,
+		client: &http.Client{Timeout: time.Duration(cfg.TimeoutSeconds) * time.Second},
+		queue:  make(chan db.DatasourceEvent, senderQueueSize),
+		ctx:    ctx,
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+	go s.run()
+	return s
+}
+
+// subscribed 判断是否订阅了该类型的事件
+func (s *sender) subscribed(eventType db.DatasourceEventType) bool {
+	if len(s.cfg.Events) == 0 {
+		return true
+	}
+	for _, e := range s.cfg.Events {
+		if e == string(eventType) {
+			return true
+		}
+	}
+	return false
+}
+
+// enqueue 非阻塞地加入待发送队列
+func (s *sender) enqueue(event db.DatasourceEvent) {
+	if !s.subscribed(event.Type) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+	select {
+	case s.queue <- event:
+	default:
+		logger.Logger.Warn("Webhook 待发送队列已满，丢弃通知",
+			zap.String("webhook", s.cfg.Name),
+			zap.String("datasource", event.DataSource))
+	}
+}
+
+// close 停止接收新通知并等待队列清空，超时后中断剩余发送
+func (s *sender) close(timeout time.Duration) {
+	s.mu.Lock()
+	if !s.closed {
+		s.closed = true
+		close(s.queue)
+	}
+	s.mu.Unlock()
+
+	select {
+	case <-s.done:
+	case <-time.After(timeout):
+		logger.Logger.Warn("等待 Webhook 通知发送超时，放弃剩余通知", zap.String("webhook", s.cfg.Name))
+		s.cancel()
+		<-s.done
+	}
+	s.cancel()
+}
+
+// run 依次发送队列中的通知
+func (s *sender) run() {
+	defer close(s.done)
+	for event := range s.queue {
+		if s.ctx.Err() != nil {
+			continue
+		}
+		if err := s.sendWithRetry(event); err != nil {
+			logger.Logger.Error("Webhook 通知发送失败",
+				zap.String("webhook", s.cfg.Name),
+				zap.String("datasource", event.DataSource),
+				zap.String("type", string(event.Type)),
+				zap.Error(err))
+		}
+	}
+}
+
+// sendWithRetry 发送通知，失败时按指数退避重试
+func (s *sender) sendWithRetry(event db.DatasourceEvent) error {
+	backoff := initialBackoff
+	var lastErr error
+	for attempt := 0; attempt <= s.cfg.MaxRetries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-time.After(backoff):
+			case <-s.ctx.Done():
+				return fmt.Errorf("aborted after %d attempt(s): %w", attempt, lastErr)
+			}
+			backoff *= 2
+			if backoff > maxBackoff {
+				backoff = maxBackoff
+			}
+		}
+
+		lastErr = s.send(event)
+		if lastErr == nil {
+			return nil
+		}
+		logger.Logger.Warn("Webhook 通知发送失败，准备重试",
+			zap.String("webhook", s.cfg.Name),
+			zap.Int("attempt", attempt+1),
+			zap.Error(lastErr))
+	}
+	return fmt.Errorf("giving up after %d attempt(s): %w", s.cfg.MaxRetries+1, lastErr)
+}
+
+// send 发送一次通知
+func (s *sender) send(event db.DatasourceEvent) error {
+	targetURL, body, err := s.buildRequest(event, time.Now())
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(s.ctx, http.MethodPost, targetURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseBody))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %d: %s", resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+	if s.cfg.Type != config.WebhookTypeGeneric {
+		return checkRobotResponse(respBody)
+	}
+	return nil
+}
+
+// buildRequest 按 Webhook 类型构建请求地址与请求体
+func (s *sender) buildRequest(event db.DatasourceEvent, now time.Time) (string, []byte, error) {
+	var payload any
+	targetURL := s.cfg.URL
+
+	switch s.cfg.Type {
+	case config.WebhookTypeDingTalk:
+		payload = map[string]any{
+			"msgtype": "markdown",
+			"markdown": map[string]string{
+				"title": eventTitle(event),
+				"text":  formatMarkdown(event),
+			},
+		}
+		if s.cfg.Secret != "" {
+			// 钉钉加签：签名放在地址参数中
+			timestamp := strconv.FormatInt(now.UnixMilli(), 10)
+			mac := hmac.New(sha256.New, []byte(s.cfg.Secret))
+			mac.Write([]byte(timestamp + "\n" + s.cfg.Secret))
+			sign := url.QueryEscape(base64.StdEncoding.EncodeToString(mac.Sum(nil)))
+			separator := "?"
+			if strings.Contains(targetURL, "?") {
+				separator = "&"
+			}
+			targetURL = fmt.Sprintf("%s%stimestamp=%s&sign=%s", targetURL, separator, timestamp, sign)
+		}
+	case config.WebhookTypeWeCom:
+		payload = map[string]any{
+			"msgtype": "markdown",
+			"markdown": map[string]string{
+				"content": formatMarkdown(event),
+			},
+		}
+	case config.WebhookTypeFeishu:
+		body := map[string]any{
+			"msg_type": "text",
+			"content": map[string]string{
+				"text": formatText(event),
+			},
+		}
+		if s.cfg.Secret != "" {
+			// 飞书加签：以 timestamp+"\n"+secret 为密钥对空串签名，签名放在请求体中
+			timestamp := strconv.FormatInt(now.Unix(), 10)
+			mac := hmac.New(sha256.New, []byte(timestamp+"\n"+s.cfg.Secret))
+			body["timestamp"] = timestamp
+			body["sign"] = base64.StdEncoding.EncodeToString(mac.Sum(nil))
+		}
+		payload = body
+	default:
+		payload = Record{DatasourceEvent: event, DowntimeSeconds: event.DowntimeSeconds()}
+	}
+
+	content, err := json.Marshal(payload)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to encode payload: %w", err)
+	}
+	return targetURL, content, nil
+}
+
+// checkRobotResponse 检查机器人接口返回的业务错误码（钉钉/企业微信为 errcode，飞书为 code）
+func checkRobotResponse(body []byte) error {
+	if len(body) == 0 {
+		return nil
+	}
+	var result struct {
+		ErrCode int    `json:"errcode"`
+		ErrMsg  string `json:"errmsg"`
+		Code    int    `json:"code"`
+		Msg     string `json:"msg"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil
+	}
+	if result.ErrCode != 0 {
+		return fmt.Errorf("robot returned errcode %d: %s", result.ErrCode, result.ErrMsg)
+	}
+	if result.Code != 0 {
+		return fmt.Errorf("robot returned code %d: %s", result.Code, result.Msg)
+	}
+	return nil
+}
+
+// eventTitle 返回通知标题
+func eventTitle(event db.DatasourceEvent) string {
+	if event.Type == db.EventDatasourceUp {
+		return "【达梦数据源恢复】"
+	}
+	return "【达梦数据源告警】"
+}
+
+// eventLines 返回通知正文的各行内容
+func eventLines(event db.DatasourceEvent) []string {
+	const timeLayout = "2006-01-02 15:04:05"
+	lines := []string{
+		"数据源: " + event.DataSource,
+		"地址: " + event.Host,
+	}
+	if event.Type == db.EventDatasourceUp {
+		lines = append(lines,
+			"恢复时间: "+event.Time.Format(timeLayout),
+			"不可用时长: "+event.Downtime.Round(time.Second).String())
+		if event.Error != "" {
+			lines = append(lines, "最近错误: "+event.Error)
+		}
+		return lines
+	}
+	lines = append(lines, "发生时间: "+event.Time.Format(timeLayout))
+	if event.Error != "" {
+		lines = append(lines, "错误: "+event.Error)
+	}
+	return lines
+}
+
+// formatText 生成纯文本通知内容
+func formatText(event db.DatasourceEvent) string {
+	return eventTitle(event) + "\n" + strings.Join(eventLines(event), "\n")
+}
+
+// formatMarkdown 生成 Markdown 通知内容
+func formatMarkdown(event db.DatasourceEvent) string {
+	var sb strings.Builder
+	sb.WriteString("### " + eventTitle(event) + "\n")
+	for _, line := range eventLines(event) {
+		sb.WriteString("- " + line + "\n")
+	}
+	return sb.String()
+}
@@ -0,0 +1,530 @@
+package push
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"dameng_exporter/config"
+	"dameng_exporter/db"
+	"dameng_exporter/logger"
+	"dameng_exporter/utils"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	commonv1 "go.opentelemetry.io/proto/otlp/common/v1"
+	metricsv1 "go.opentelemetry.io/proto/otlp/metrics/v1"
+	resourcev1 "go.opentelemetry.io/proto/otlp/resource/v1"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	// otlpScopeName OTLP InstrumentationScope 名称
+	otlpScopeName = "dameng_exporter"
+	// otlpServiceName Resource 的 service.name 属性
+	otlpServiceName = "dameng_exporter"
+)
+
+// 导出结果分类
+const (
+	exportResultSuccess = "success"
+	exportResultFailure = "failure"
+)
+
+// OTLPExporter 按周期采集注册器中的指标，转换为 OTLP 指标后通过 OTLP/HTTP（protobuf）导出到各目标。
+// 每个数据源对应一个 Resource，Resource 属性由 datasource 标签与数据源的附加标签组成
+type OTLPExporter struct {
+	gather      GatherFunc
+	interval    time.Duration
+	poolManager *db.DBPoolManager
+	targets     []*otlpTarget
+	startTime   time.Time // 无创建时间的累计指标使用的起始时间
+	hostname    string
+
+	stop chan struct{}
+	done chan struct{}
+	once sync.Once
+
+	exports *prometheus.CounterVec
+}
+
+// otlpTarget 单个 OTLP 导出目标
+type otlpTarget struct {
+	cfg        config.OTLPConfig
+	client     *http.Client
+	attributes map[string]string // 附加的 Resource 属性
+}
+
+// NewOTLPExporter 根据导出目标配置创建导出器，需调用 Start 启动
+func NewOTLPExporter(targets []config.OTLPConfig, interval time.Duration, poolManager *db.DBPoolManager, gather GatherFunc) (*OTLPExporter, error) {
+	e := &OTLPExporter{
+		gather:      gather,
+		interval:    interval,
+		poolManager: poolManager,
+		startTime:   time.Now(),
+		stop:        make(chan struct{}),
+		done:        make(chan struct{}),
+		exports: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "dameng_exporter_otlp_exports_total",
+			Help: "Total number of OTLP metric exports by target and result (success, failure)",
+		}, []string{"target", "result"}),
+	}
+	e.hostname, _ = os.Hostname()
+
+	for _, cfg := range targets {
+		client, err := newHTTPClient(time.Duration(cfg.TimeoutSeconds)*time.Second, cfg.TLS)
+		if err != nil {
+			return nil, fmt.Errorf("otlp %s: %w", cfg.Name, err)
+		}
+		e.targets = append(e.targets, &otlpTarget{
+			cfg:        cfg,
+			client:     client,
+			attributes: cfg.ParseResourceAttributes(),
+		})
+		// 预先初始化各分类，保证从0开始输出
+		for _, result := range []string{exportResultSuccess, exportResultFailure} {
+			e.exports.WithLabelValues(cfg.Name, result)
+		}
+	}
+	return e, nil
+}
+
+// Describe 实现 prometheus.Collector 接口
+func (e *OTLPExporter) Describe(ch chan<- *prometheus.Desc) {
+	e.exports.Describe(ch)
+}
+
+// Collect 实现 prometheus.Collector 接口
+func (e *OTLPExporter) Collect(ch chan<- prometheus.Metric) {
+	e.exports.Collect(ch)
+}
+
+// Start 启动周期导出
+func (e *OTLPExporter) Start() {
+	go e.run()
+	logger.Logger.Infof("OTLP exporter started with %d target(s), interval %v", len(e.targets), e.interval)
+}
+
+// Close 停止导出，等待进行中的导出结束
+func (e *OTLPExporter) Close() {
+	e.once.Do(func() {
+		close(e.stop)
+		<-e.done
+	})
+}
+
+// run 启动后立即导出一次，之后按周期导出
+func (e *OTLPExporter) run() {
+	defer close(e.done)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-e.stop
+		cancel()
+	}()
+
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+	for {
+		e.exportOnce(ctx)
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// exportOnce 采集一次并导出到各目标
+func (e *OTLPExporter) exportOnce(ctx context.Context) {
+	families, err := e.gather()
+	if err != nil {
+		if len(families) == 0 {
+			logger.Logger.Warnf("OTLP gather failed, skipping this export: %v", err)
+			return
+		}
+		logger.Logger.Warnf("OTLP gather partially failed, exporting collected metrics: %v", err)
+	}
+	if len(families) == 0 {
+		return
+	}
+
+	resources := e.groupByResource(families, time.Now())
+	var wg sync.WaitGroup
+	for _, t := range e.targets {
+		wg.Add(1)
+		go func(t *otlpTarget) {
+			defer wg.Done()
+			body, err := e.encodeExportRequest(resources, t.attributes)
+			if err == nil {
+				err = t.exportWithRetry(ctx, body)
+			}
+			if err != nil {
+				logger.Logger.Errorf("OTLP export to target %s failed: %v", t.cfg.Name, err)
+				e.exports.WithLabelValues(t.cfg.Name, exportResultFailure).Inc()
+				return
+			}
+			e.exports.WithLabelValues(t.cfg.Name, exportResultSuccess).Inc()
+		}(t)
+	}
+	wg.Wait()
+}
+
+// exportWithRetry 导出一次，网络错误与 429/502/503/504 按指数退避重试
+func (t *otlpTarget) exportWithRetry(ctx context.Context, body []byte) error {
+	backoff := initialBackoff
+	var lastErr error
+	for attempt := 0; attempt <= t.cfg.MaxRetries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-time.After(backoff):
+			case <-ctx.Done():
+				return fmt.Errorf("aborted after %d attempt(s): %w", attempt, lastErr)
+			}
+			backoff *= 2
+			if backoff > maxBackoff {
+				backoff = maxBackoff
+			}
+		}
+
+		retryable, err := t.post(ctx, body)
+		if err == nil {
+			return nil
+		}
+		lastErr = err
+		if !retryable || ctx.Err() != nil {
+			return err
+		}
+		logger.Logger.Warnf("OTLP export to target %s failed (attempt %d), retrying: %v", t.cfg.Name, attempt+1, err)
+	}
+	return fmt.Errorf("giving up after %d attempt(s): %w", t.cfg.MaxRetries+1, lastErr)
+}
+
+// post 发送一次导出请求，返回失败是否可重试
+func (t *otlpTarget) post(ctx context.Context, body []byte) (bool, error) {
+	var reader io.Reader = bytes.NewReader(body)
+	if t.cfg.Compression == config.OTLPCompressionGzip {
+		var buf bytes.Buffer
+		gz := gzip.NewWriter(&buf)
+		if _, err := gz.Write(body); err != nil {
+			return false, fmt.Errorf("failed to compress request: %w", err)
+		}
+		if err := gz.Close(); err != nil {
+			return false, fmt.Errorf("failed to compress request: %w", err)
+		}
+		reader = &buf
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.cfg.Endpoint, reader)
+	if err != nil {
+		return false, fmt.Errorf("failed to create request: %w", err)
+	}
+	for name, value := range t.cfg.Headers {
+		req.Header.Set(name, value)
+	}
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	req.Header.Set("User-Agent", "dameng_exporter/"+config.GetVersion())
+	if t.cfg.Compression == config.OTLPCompressionGzip {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		io.Copy(io.Discard, resp.Body)
+		return false, nil
+	}
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseBody))
+	err = fmt.Errorf("unexpected status %d: %s", resp.StatusCode, strings.TrimSpace(string(respBody)))
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true, err
+	}
+	return false, err
+}
+
+// otlpResource 一个 Resource 及其下的指标
+type otlpResource struct {
+	attributes []label // Resource 属性，按名称排序
+	metrics    []*otlpMetric
+}
+
+// otlpMetric 一个指标族在某个 Resource 下的数据点
+type otlpMetric struct {
+	family *dto.MetricFamily
+	points []otlpPoint
+}
+
+// otlpPoint 一个数据点
+type otlpPoint struct {
+	metric     *dto.Metric
+	attributes []label // 去除 Resource 属性后的标签
+	timeNano   uint64
+	startNano  uint64
+}
+
+// groupByResource 按 datasource 标签将指标分配到各数据源的 Resource，不带数据源标签的指标归入公共 Resource；
+// 数据源的附加标签作为 Resource 属性，不再重复出现在数据点属性中
+func (e *OTLPExporter) groupByResource(families []*dto.MetricFamily, now time.Time) []*otlpResource {
+	// datasource 标签值 -> Resource 属性
+	dsAttributes := make(map[string]map[string]string)
+	if e.poolManager != nil {
+		for _, snapshot := range e.poolManager.DatasourceSnapshots() {
+			if snapshot.Config == nil {
+				continue
+			}
+			attrs := snapshot.InjectedLabels()
+			dsAttributes[attrs[db.DatasourceLabel]] = attrs
+		}
+	}
+
+	resources := make(map[string]*otlpResource)
+	var order []string
+	resourceFor := func(dataSource string) *otlpResource {
+		if r, ok := resources[dataSource]; ok {
+			return r
+		}
+		attrs := map[string]string{}
+		if dataSource != "" {
+			attrs = dsAttributes[dataSource]
+			if attrs == nil {
+				attrs = map[string]string{db.DatasourceLabel: dataSource}
+			}
+		}
+		r := &otlpResource{attributes: sortedLabels(attrs)}
+		resources[dataSource] = r
+		order = append(order, dataSource)
+		return r
+	}
+
+	nowNano := uint64(now.UnixNano())
+	startNano := uint64(e.startTime.UnixNano())
+	for _, mf := range families {
+		byResource := make(map[*otlpResource]*otlpMetric)
+		for _, m := range mf.GetMetric() {
+			dataSource, _ := utils.MetricLabelValue(m, db.DatasourceLabel)
+			r := resourceFor(dataSource)
+
+			point := otlpPoint{metric: m, timeNano: nowNano, startNano: startNano}
+			if m.TimestampMs != nil {
+				point.timeNano = uint64(m.GetTimestampMs()) * uint64(time.Millisecond)
+			}
+			if created := createdTimestamp(mf.GetType(), m); created != nil {
+				point.startNano = uint64(created.AsTime().UnixNano())
+			}
+			for _, lp := range m.GetLabel() {
+				if !hasLabel(r.attributes, lp.GetName(), lp.GetValue()) {
+					point.attributes = append(point.attributes, label{lp.GetName(), lp.GetValue()})
+				}
+			}
+
+			om := byResource[r]
+			if om == nil {
+				om = &otlpMetric{family: mf}
+				r.metrics = append(r.metrics, om)
+				byResource[r] = om
+			}
+			om.points = append(om.points, point)
+		}
+	}
+
+	result := make([]*otlpResource, 0, len(order))
+	for _, key := range order {
+		result = append(result, resources[key])
+	}
+	return result
+}
+
+// hasLabel 判断标签集合中是否存在同名同值的标签
+func hasLabel(labels []label, name, value string) bool {
+	for _, l := range labels {
+		if l.name == name {
+			return l.value == value
+		}
+	}
+	return false
+}
+
+// createdTimestamp 返回累计类指标的创建时间
+func createdTimestamp(typ dto.MetricType, m *dto.Metric) *timestamppb.Timestamp {
+	switch typ {
+	case dto.MetricType_COUNTER:
+		return m.GetCounter().GetCreatedTimestamp()
+	case dto.MetricType_HISTOGRAM:
+		return m.GetHistogram().GetCreatedTimestamp()
+	case dto.MetricType_SUMMARY:
+		return m.GetSummary().GetCreatedTimestamp()
+	}
+	return nil
+}
+
+// encodeExportRequest 编码导出请求，extra 为目标附加的 Resource 属性。
+// OTLP/HTTP 的 ExportMetricsServiceRequest 与 MetricsData 的 protobuf 编码相同（resource_metrics=1），
+// 使用 MetricsData 构造请求，避免引入 collector 包依赖的 gRPC
+func (e *OTLPExporter) encodeExportRequest(resources []*otlpResource, extra map[string]string) ([]byte, error) {
+	request := &metricsv1.MetricsData{}
+	for _, r := range resources {
+		// 公共属性优先级最低，数据源属性覆盖附加属性
+		attrs := map[string]string{
+			"service.name":    otlpServiceName,
+			"service.version": config.GetVersion(),
+		}
+		if e.hostname != "" {
+			attrs["service.instance.id"] = e.hostname
+		}
+		for k, v := range extra {
+			attrs[k] = v
+		}
+		for _, l := range r.attributes {
+			attrs[l.name] = l.value
+		}
+
+		var metrics []*metricsv1.Metric
+		for _, m := range r.metrics {
+			if metric := toOTLPMetric(m); metric != nil {
+				metrics = append(metrics, metric)
+			}
+		}
+		request.ResourceMetrics = append(request.ResourceMetrics, &metricsv1.ResourceMetrics{
+			Resource: &resourcev1.Resource{Attributes: keyValues(sortedLabels(attrs))},
+			ScopeMetrics: []*metricsv1.ScopeMetrics{{
+				Scope:   &commonv1.InstrumentationScope{Name: otlpScopeName, Version: config.GetVersion()},
+				Metrics: metrics,
+			}},
+		})
+	}
+	return proto.Marshal(request)
+}
+
+// toOTLPMetric 转换为 OTLP 指标：计数器转换为单调累计的 Sum，不支持的类型返回 nil
+func toOTLPMetric(m *otlpMetric) *metricsv1.Metric {
+	metric := &metricsv1.Metric{
+		Name:        m.family.GetName(),
+		Description: m.family.GetHelp(),
+		Unit:        m.family.GetUnit(),
+	}
+	switch m.family.GetType() {
+	case dto.MetricType_GAUGE, dto.MetricType_UNTYPED:
+		gauge := &metricsv1.Gauge{}
+		for _, p := range m.points {
+			value := p.metric.GetGauge().GetValue()
+			if m.family.GetType() == dto.MetricType_UNTYPED {
+				value = p.metric.GetUntyped().GetValue()
+			}
+			gauge.DataPoints = append(gauge.DataPoints, numberPoint(p, value, false))
+		}
+		metric.Data = &metricsv1.Metric_Gauge{Gauge: gauge}
+	case dto.MetricType_COUNTER:
+		sum := &metricsv1.Sum{
+			AggregationTemporality: metricsv1.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE,
+			IsMonotonic:            true,
+		}
+		for _, p := range m.points {
+			sum.DataPoints = append(sum.DataPoints, numberPoint(p, p.metric.GetCounter().GetValue(), true))
+		}
+		metric.Data = &metricsv1.Metric_Sum{Sum: sum}
+	case dto.MetricType_HISTOGRAM:
+		histogram := &metricsv1.Histogram{
+			AggregationTemporality: metricsv1.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE,
+		}
+		for _, p := range m.points {
+			histogram.DataPoints = append(histogram.DataPoints, histogramPoint(p))
+		}
+		metric.Data = &metricsv1.Metric_Histogram{Histogram: histogram}
+	case dto.MetricType_SUMMARY:
+		summary := &metricsv1.Summary{}
+		for _, p := range m.points {
+			summary.DataPoints = append(summary.DataPoints, summaryPoint(p))
+		}
+		metric.Data = &metricsv1.Metric_Summary{Summary: summary}
+	default:
+		return nil
+	}
+	return metric
+}
+
+// numberPoint 转换 Gauge/Sum 的数据点，Gauge 不输出起始时间
+func numberPoint(p otlpPoint, value float64, cumulative bool) *metricsv1.NumberDataPoint {
+	point := &metricsv1.NumberDataPoint{
+		TimeUnixNano: p.timeNano,
+		Value:        &metricsv1.NumberDataPoint_AsDouble{AsDouble: value},
+		Attributes:   keyValues(p.attributes),
+	}
+	if cumulative {
+		point.StartTimeUnixNano = p.startNano
+	}
+	return point
+}
+
+// histogramPoint 转换直方图数据点，Prometheus 的累计桶计数转换为 OTLP 的逐桶计数
+func histogramPoint(p otlpPoint) *metricsv1.HistogramDataPoint {
+	h := p.metric.GetHistogram()
+	var bounds []float64
+	var counts []uint64
+	var previous uint64
+	for _, bucket := range h.GetBucket() {
+		if math.IsInf(bucket.GetUpperBound(), 1) {
+			continue
+		}
+		bounds = append(bounds, bucket.GetUpperBound())
+		counts = append(counts, bucket.GetCumulativeCount()-previous)
+		previous = bucket.GetCumulativeCount()
+	}
+	counts = append(counts, h.GetSampleCount()-previous)
+
+	return &metricsv1.HistogramDataPoint{
+		StartTimeUnixNano: p.startNano,
+		TimeUnixNano:      p.timeNano,
+		Count:             h.GetSampleCount(),
+		Sum:               proto.Float64(h.GetSampleSum()),
+		BucketCounts:      counts,
+		ExplicitBounds:    bounds,
+		Attributes:        keyValues(p.attributes),
+	}
+}
+
+// summaryPoint 转换摘要数据点
+func summaryPoint(p otlpPoint) *metricsv1.SummaryDataPoint {
+	s := p.metric.GetSummary()
+	point := &metricsv1.SummaryDataPoint{
+		StartTimeUnixNano: p.startNano,
+		TimeUnixNano:      p.timeNano,
+		Count:             s.GetSampleCount(),
+		Sum:               s.GetSampleSum(),
+		Attributes:        keyValues(p.attributes),
+	}
+	for _, q := range s.GetQuantile() {
+		point.QuantileValues = append(point.QuantileValues, &metricsv1.SummaryDataPoint_ValueAtQuantile{
+			Quantile: q.GetQuantile(),
+			Value:    q.GetValue(),
+		})
+	}
+	return point
+}
+
+// keyValues 将标签转换为字符串类型的 OTLP 属性
+func keyValues(labels []label) []*commonv1.KeyValue {
+	if len(labels) == 0 {
+		return nil
+	}
+	attrs := make([]*commonv1.KeyValue, 0, len(labels))
+	for _, l := range labels {
+		attrs = append(attrs, &commonv1.KeyValue{
+			Key:   l.name,
+			Value: &commonv1.AnyValue{Value: &commonv1.AnyValue_StringValue{StringValue: l.value}},
+		})
+	}
+	return attrs
+}
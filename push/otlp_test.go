@@ -0,0 +1,160 @@
+package push
+
+import (
+	"dameng_exporter/config"
+	"reflect"
+	"testing"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+	commonv1 "go.opentelemetry.io/proto/otlp/common/v1"
+	metricsv1 "go.opentelemetry.io/proto/otlp/metrics/v1"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// TestEncodeExportRequestRoundTrip 解码导出请求，校验 Resource 属性与各类型指标的转换
+func TestEncodeExportRequestRoundTrip(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	now := start.Add(time.Minute)
+	created := start.Add(10 * time.Second)
+	dsLabel := func(value string) []*dto.LabelPair {
+		return []*dto.LabelPair{{Name: proto.String("datasource"), Value: proto.String(value)}}
+	}
+	families := []*dto.MetricFamily{
+		{
+			Name: proto.String("dmdbms_session_count"),
+			Help: proto.String("Number of sessions"),
+			Type: dto.MetricType_GAUGE.Enum(),
+			Metric: []*dto.Metric{{
+				Label: append(dsLabel("dm_primary"), &dto.LabelPair{Name: proto.String("state"), Value: proto.String("active")}),
+				Gauge: &dto.Gauge{Value: proto.Float64(7)},
+			}},
+		},
+		{
+			Name: proto.String("dmdbms_exec_total"),
+			Type: dto.MetricType_COUNTER.Enum(),
+			Metric: []*dto.Metric{{
+				Label:   dsLabel("dm_primary"),
+				Counter: &dto.Counter{Value: proto.Float64(42), CreatedTimestamp: timestamppb.New(created)},
+			}},
+		},
+		{
+			Name: proto.String("dmdbms_query_seconds"),
+			Type: dto.MetricType_HISTOGRAM.Enum(),
+			Metric: []*dto.Metric{{
+				Label: dsLabel("dm_standby"),
+				Histogram: &dto.Histogram{
+					SampleCount: proto.Uint64(5),
+					SampleSum:   proto.Float64(2.5),
+					Bucket: []*dto.Bucket{
+						{UpperBound: proto.Float64(0.1), CumulativeCount: proto.Uint64(1)},
+						{UpperBound: proto.Float64(1), CumulativeCount: proto.Uint64(4)},
+					},
+				},
+			}},
+		},
+		{
+			Name: proto.String("dameng_exporter_scrape_seconds"),
+			Type: dto.MetricType_SUMMARY.Enum(),
+			Metric: []*dto.Metric{{
+				Summary: &dto.Summary{
+					SampleCount: proto.Uint64(2),
+					SampleSum:   proto.Float64(0.3),
+					Quantile:    []*dto.Quantile{{Quantile: proto.Float64(0.5), Value: proto.Float64(0.1)}},
+				},
+			}},
+		},
+	}
+
+	e := &OTLPExporter{startTime: start, hostname: "host1"}
+	data, err := e.encodeExportRequest(e.groupByResource(families, now), map[string]string{"deployment.environment": "test"})
+	if err != nil {
+		t.Fatalf("encodeExportRequest() error = %v", err)
+	}
+
+	var decoded metricsv1.MetricsData
+	if err := proto.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("proto.Unmarshal() error = %v", err)
+	}
+	resources := decoded.GetResourceMetrics()
+	if len(resources) != 3 {
+		t.Fatalf("decoded %d resources, want 3", len(resources))
+	}
+
+	// Resource 按指标中首次出现的数据源排序，不带数据源标签的指标归入公共 Resource
+	for i, ds := range []string{"dm_primary", "dm_standby", ""} {
+		want := map[string]string{
+			"service.name":           otlpServiceName,
+			"service.version":        config.GetVersion(),
+			"service.instance.id":    "host1",
+			"deployment.environment": "test",
+		}
+		if ds != "" {
+			want["datasource"] = ds
+		}
+		if got := stringAttributes(resources[i].GetResource().GetAttributes()); !reflect.DeepEqual(got, want) {
+			t.Errorf("resource %d attributes = %v, want %v", i, got, want)
+		}
+		scopes := resources[i].GetScopeMetrics()
+		if len(scopes) != 1 || scopes[0].GetScope().GetName() != otlpScopeName {
+			t.Fatalf("resource %d scope metrics = %v, want a single %s scope", i, scopes, otlpScopeName)
+		}
+	}
+
+	primary := resources[0].GetScopeMetrics()[0].GetMetrics()
+	if len(primary) != 2 {
+		t.Fatalf("dm_primary has %d metrics, want 2", len(primary))
+	}
+
+	gauge := primary[0]
+	if gauge.GetName() != "dmdbms_session_count" || gauge.GetDescription() != "Number of sessions" {
+		t.Errorf("gauge name/description = %q/%q", gauge.GetName(), gauge.GetDescription())
+	}
+	gp := gauge.GetGauge().GetDataPoints()
+	if len(gp) != 1 || gp[0].GetAsDouble() != 7 || gp[0].GetStartTimeUnixNano() != 0 ||
+		gp[0].GetTimeUnixNano() != uint64(now.UnixNano()) {
+		t.Errorf("gauge data points = %v", gp)
+	}
+	if got := stringAttributes(gp[0].GetAttributes()); !reflect.DeepEqual(got, map[string]string{"state": "active"}) {
+		t.Errorf("gauge point attributes = %v, want only state", got)
+	}
+
+	sum := primary[1].GetSum()
+	if sum == nil || !sum.GetIsMonotonic() || sum.GetAggregationTemporality() != metricsv1.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE {
+		t.Fatalf("counter = %v, want a monotonic cumulative sum", primary[1])
+	}
+	sp := sum.GetDataPoints()
+	if len(sp) != 1 || sp[0].GetAsDouble() != 42 || sp[0].GetStartTimeUnixNano() != uint64(created.UnixNano()) {
+		t.Errorf("sum data points = %v, want value 42 starting at the created timestamp", sp)
+	}
+
+	histogram := resources[1].GetScopeMetrics()[0].GetMetrics()[0].GetHistogram()
+	hp := histogram.GetDataPoints()
+	if len(hp) != 1 {
+		t.Fatalf("histogram data points = %v", hp)
+	}
+	// 累计桶计数转换为逐桶计数，+Inf 桶由总数补齐
+	if !reflect.DeepEqual(hp[0].GetBucketCounts(), []uint64{1, 3, 1}) ||
+		!reflect.DeepEqual(hp[0].GetExplicitBounds(), []float64{0.1, 1}) ||
+		hp[0].GetCount() != 5 || hp[0].GetSum() != 2.5 || hp[0].GetStartTimeUnixNano() != uint64(start.UnixNano()) {
+		t.Errorf("histogram data point = %v", hp[0])
+	}
+
+	summary := resources[2].GetScopeMetrics()[0].GetMetrics()[0].GetSummary()
+	qp := summary.GetDataPoints()
+	if len(qp) != 1 || qp[0].GetCount() != 2 || qp[0].GetSum() != 0.3 ||
+		len(qp[0].GetQuantileValues()) != 1 || qp[0].GetQuantileValues()[0].GetQuantile() != 0.5 ||
+		qp[0].GetQuantileValues()[0].GetValue() != 0.1 {
+		t.Errorf("summary data points = %v", qp)
+	}
+}
+
+// stringAttributes 将字符串类型的属性转换为 map，便于比较
+func stringAttributes(attrs []*commonv1.KeyValue) map[string]string {
+	result := make(map[string]string, len(attrs))
+	for _, kv := range attrs {
+		result[kv.GetKey()] = kv.GetValue().GetStringValue()
+	}
+	return result
+}
@@ -0,0 +1,135 @@
+package push
+
+import (
+	"context"
+	"dameng_exporter/db"
+	"dameng_exporter/logger"
+	"dameng_exporter/utils"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/push"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// pushGatewayTimeout 单个分组推送的超时时间
+const pushGatewayTimeout = 30 * time.Second
+
+// GatewayResult 单个数据源的采集与推送结果
+type GatewayResult struct {
+	DataSource string
+	Grouping   map[string]string // 推送使用的分组标签
+	Series     int               // 推送的指标数量
+	Err        error             // 数据源不可用或推送失败的原因，成功时为 nil
+}
+
+// PushToGateway 对注册器执行一次完整采集，按数据源拆分后分别推送到 Pushgateway：
+// 每个数据源以 datasource 及其附加标签作为分组，不带数据源标签的指标（如 dmdbms_build_info）推送到每个分组。
+// 已禁用的数据源不推送
+func PushToGateway(url, job string, gatherer prometheus.Gatherer, poolManager *db.DBPoolManager) []GatewayResult {
+	families, gatherErr := gatherer.Gather()
+	if gatherErr != nil {
+		logger.Logger.Warnf("Collection finished with errors: %v", gatherErr)
+	}
+
+	client := &http.Client{Timeout: pushGatewayTimeout}
+	var results []GatewayResult
+	for _, snapshot := range poolManager.DatasourceSnapshots() {
+		if snapshot.Config == nil || !snapshot.Config.Enabled {
+			continue
+		}
+
+		grouping := snapshot.InjectedLabels()
+
+		result := GatewayResult{DataSource: snapshot.Config.Name, Grouping: grouping}
+		selected := familiesForDataSource(families, grouping)
+		for _, mf := range selected {
+			result.Series += len(mf.GetMetric())
+		}
+
+		pusher := push.New(url, job).Client(client).Gatherer(prometheus.GathererFunc(func() ([]*dto.MetricFamily, error) {
+			return selected, nil
+		}))
+		for _, name := range utils.SortedKeys(grouping) {
+			pusher = pusher.Grouping(name, grouping[name])
+		}
+
+		// 数据源不可用时仍推送其状态指标（如 dmdb_up=0），便于在 Pushgateway 中看到失败
+		ctx, cancel := context.WithTimeout(context.Background(), pushGatewayTimeout)
+		err := pusher.PushContext(ctx)
+		cancel()
+		switch {
+		case err != nil:
+			result.Err = fmt.Errorf("push failed: %w", err)
+		case !snapshot.Healthy:
+			result.Err = fmt.Errorf("datasource unavailable: %s", snapshot.LastError)
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// GatewayExitCode 根据各数据源的结果计算进程退出码
+func GatewayExitCode(results []GatewayResult) int {
+	failed := 0
+	for _, r := range results {
+		if r.Err != nil {
+			failed++
+		}
+	}
+	switch {
+	case len(results) == 0 || failed == len(results):
+		return db.ExitAllFailed
+	case failed > 0:
+		return db.ExitPartial
+	default:
+		return db.ExitAllSucceeded
+	}
+}
+
+// familiesForDataSource 筛选属于分组中数据源的指标，以及不带数据源标签的公共指标；
+// Pushgateway 会为分组内的指标补充分组标签，因此推送前去除指标中与分组同名的标签
+func familiesForDataSource(families []*dto.MetricFamily, grouping map[string]string) []*dto.MetricFamily {
+	dataSource := grouping[db.DatasourceLabel]
+	var result []*dto.MetricFamily
+	for _, mf := range families {
+		var metrics []*dto.Metric
+		for _, m := range mf.GetMetric() {
+			if value, ok := utils.MetricLabelValue(m, db.DatasourceLabel); ok && value != dataSource {
+				continue
+			}
+			metrics = append(metrics, withoutLabels(m, grouping))
+		}
+		if len(metrics) == 0 {
+			continue
+		}
+		result = append(result, &dto.MetricFamily{
+			Name:   mf.Name,
+			Help:   mf.Help,
+			Type:   mf.Type,
+			Unit:   mf.Unit,
+			Metric: metrics,
+		})
+	}
+	return result
+}
+
+// withoutLabels 返回去除指定标签后的指标副本
+func withoutLabels(m *dto.Metric, names map[string]string) *dto.Metric {
+	stripped := &dto.Metric{
+		Gauge:       m.Gauge,
+		Counter:     m.Counter,
+		Summary:     m.Summary,
+		Untyped:     m.Untyped,
+		Histogram:   m.Histogram,
+		TimestampMs: m.TimestampMs,
+	}
+	for _, lp := range m.GetLabel() {
+		if _, ok := names[lp.GetName()]; !ok {
+			stripped.Label = append(stripped.Label, lp)
+		}
+	}
+	return stripped
+}
@@ -0,0 +1,182 @@
+package push
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// batchFileSuffix 磁盘队列中批次文件的后缀
+const batchFileSuffix = ".batch"
+
+// batch 一次推送的数据（已编码、压缩）
+type batch struct {
+	data    []byte
+	samples int    // 样本数量，用于自监控指标
+	file    string // 磁盘队列中的文件路径，仅内存队列时为空
+}
+
+// batchQueue 有容量上限的待发送批次队列，超出后丢弃最旧的批次；
+// 配置目录时批次同时写入磁盘，重启后继续发送
+type batchQueue struct {
+	mu     sync.Mutex
+	items  []*batch
+	max    int
+	dir    string
+	notify chan struct{} // 有新批次加入时通知发送协程
+}
+
+// newBatchQueue 创建队列，dir 不为空时加载目录中上次未发送的批次
+func newBatchQueue(max int, dir string) (*batchQueue, error) {
+	q := &batchQueue{
+		max:    max,
+		dir:    dir,
+		notify: make(chan struct{}, 1),
+	}
+	if dir == "" {
+		return q, nil
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create queue dir %s: %w", dir, err)
+	}
+	if err := q.load(); err != nil {
+		return nil, err
+	}
+	return q, nil
+}
+
+// load 按文件名（即入队时间）顺序加载磁盘中的批次，超出容量的最旧批次直接删除
+func (q *batchQueue) load() error {
+	entries, err := os.ReadDir(q.dir)
+	if err != nil {
+		return fmt.Errorf("failed to read queue dir %s: %w", q.dir, err)
+	}
+
+	var names []string
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), batchFileSuffix) {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		path := filepath.Join(q.dir, name)
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read queued batch %s: %w", path, err)
+		}
+		q.items = append(q.items, &batch{data: data, samples: samplesFromFileName(name), file: path})
+	}
+	q.trimLocked()
+	if len(q.items) > 0 {
+		q.signal()
+	}
+	return nil
+}
+
+// push 加入一个批次，返回因超出容量被丢弃的旧批次数量
+func (q *batchQueue) push(b *batch) (int, error) {
+	if q.dir != "" {
+		path, err := q.writeFile(b)
+		if err != nil {
+			return 0, err
+		}
+		b.file = path
+	}
+
+	q.mu.Lock()
+	q.items = append(q.items, b)
+	dropped := q.trimLocked()
+	q.mu.Unlock()
+
+	q.signal()
+	return dropped, nil
+}
+
+// writeFile 先写临时文件再重命名，避免进程中断留下不完整的批次
+func (q *batchQueue) writeFile(b *batch) (string, error) {
+	name := fmt.Sprintf("%020d-%d%s", time.Now().UnixNano(), b.samples, batchFileSuffix)
+	path := filepath.Join(q.dir, name)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, b.data, 0o600); err != nil {
+		return "", fmt.Errorf("failed to write queued batch %s: %w", tmp, err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return "", fmt.Errorf("failed to rename queued batch %s: %w", tmp, err)
+	}
+	return path, nil
+}
+
+// trimLocked 丢弃超出容量的最旧批次，调用方需持有 q.mu
+func (q *batchQueue) trimLocked() int {
+	dropped := 0
+	for len(q.items) > q.max {
+		removeBatchFile(q.items[0])
+		q.items[0] = nil
+		q.items = q.items[1:]
+		dropped++
+	}
+	return dropped
+}
+
+// signal 非阻塞地通知发送协程
+func (q *batchQueue) signal() {
+	select {
+	case q.notify <- struct{}{}:
+	default:
+	}
+}
+
+// peek 返回最旧的批次，队列为空时返回 nil
+func (q *batchQueue) peek() *batch {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.items) == 0 {
+		return nil
+	}
+	return q.items[0]
+}
+
+// remove 移除已发送或放弃的批次；该批次可能已因超出容量被丢弃，此时不做处理
+func (q *batchQueue) remove(b *batch) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.items) == 0 || q.items[0] != b {
+		return
+	}
+	removeBatchFile(b)
+	q.items[0] = nil
+	q.items = q.items[1:]
+}
+
+// len 返回队列中的批次数量
+func (q *batchQueue) len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.items)
+}
+
+// removeBatchFile 删除批次对应的磁盘文件
+func removeBatchFile(b *batch) {
+	if b.file != "" {
+		os.Remove(b.file)
+	}
+}
+
+// samplesFromFileName 从 "<时间戳>-<样本数>.batch" 格式的文件名中解析样本数量
+func samplesFromFileName(name string) int {
+	name = strings.TrimSuffix(name, batchFileSuffix)
+	idx := strings.LastIndex(name, "-")
+	if idx < 0 {
+		return 0
+	}
+	samples, _ := strconv.Atoi(name[idx+1:])
+	return samples
+}
@@ -0,0 +1,349 @@
+package push
+
+import (
+	"bytes"
+	"context"
+	"dameng_exporter/config"
+	"dameng_exporter/logger"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang/snappy"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/prometheus/prompb"
+)
+
+const (
+	// 重试退避时间：首次1秒，每次翻倍，最长60秒
+	initialBackoff = time.Second
+	maxBackoff     = 60 * time.Second
+	// maxResponseBody 读取错误响应体的最大字节数
+	maxResponseBody = 4 * 1024
+	// remoteWriteVersion remote_write 协议版本
+	remoteWriteVersion = "0.1.0"
+	// defaultJob 未配置 job 附加标签时使用的值
+	defaultJob = "dameng_exporter"
+)
+
+// 批次处理结果分类
+const (
+	batchResultSent    = "sent"    // 发送成功
+	batchResultFailed  = "failed"  // 发送失败（将重试）
+	batchResultDropped = "dropped" // 队列已满或服务端拒绝而丢弃
+)
+
+// GatherFunc 执行一次采集
+type GatherFunc func() ([]*dto.MetricFamily, error)
+
+// permanentError 服务端拒绝且重试无意义的错误（除429外的4xx）
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// RemoteWriter 按周期采集注册器中的指标，通过 Prometheus remote_write 协议推送到各目标
+type RemoteWriter struct {
+	gather   GatherFunc
+	interval time.Duration
+	targets  []*remoteWriteTarget
+
+	stop chan struct{}
+	done chan struct{}
+	once sync.Once
+
+	batches     *prometheus.CounterVec
+	samples     *prometheus.CounterVec
+	queueDesc   *prometheus.Desc
+	lastSuccess *prometheus.GaugeVec
+}
+
+// remoteWriteTarget 单个推送目标的发送器
+type remoteWriteTarget struct {
+	cfg            config.RemoteWriteConfig
+	client         *http.Client
+	externalLabels []label
+	queue          *batchQueue
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewRemoteWriter 根据推送目标配置创建推送器，需调用 Start 启动
+func NewRemoteWriter(targets []config.RemoteWriteConfig, interval time.Duration, gather GatherFunc) (*RemoteWriter, error) {
+	w := &RemoteWriter{
+		gather:   gather,
+		interval: interval,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+		batches: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "dameng_exporter_remote_write_batches_total",
+			Help: "Total number of remote_write batches by target and result (sent, failed, dropped)",
+		}, []string{"target", "result"}),
+		samples: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "dameng_exporter_remote_write_samples_total",
+			Help: "Total number of samples successfully sent by remote_write",
+		}, []string{"target"}),
+		queueDesc: prometheus.NewDesc("dameng_exporter_remote_write_queue_batches",
+			"Number of remote_write batches waiting to be sent", []string{"target"}, nil),
+		lastSuccess: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "dameng_exporter_remote_write_last_success_timestamp_seconds",
+			Help: "Unix timestamp of the last successful remote_write push",
+		}, []string{"target"}),
+	}
+
+	hostname, _ := os.Hostname()
+	for _, cfg := range targets {
+		client, err := newHTTPClient(time.Duration(cfg.TimeoutSeconds)*time.Second, cfg.TLS)
+		if err != nil {
+			return nil, fmt.Errorf("remoteWrite %s: %w", cfg.Name, err)
+		}
+
+		queueDir := ""
+		if cfg.QueueDir != "" {
+			queueDir = filepath.Join(cfg.QueueDir, cfg.Name)
+		}
+		queue, err := newBatchQueue(cfg.QueueSize, queueDir)
+		if err != nil {
+			return nil, fmt.Errorf("remoteWrite %s: %w", cfg.Name, err)
+		}
+		if n := queue.len(); n > 0 {
+			logger.Logger.Infof("Loaded %d unsent remote_write batch(es) for target %s from %s", n, cfg.Name, queueDir)
+		}
+
+		// 推送数据不经过 Prometheus 抓取，补充 job/instance 标签以便与抓取模式的数据保持一致
+		external := cfg.ParseExternalLabels()
+		if _, ok := external["job"]; !ok {
+			external["job"] = defaultJob
+		}
+		if _, ok := external["instance"]; !ok && hostname != "" {
+			external["instance"] = hostname
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		w.targets = append(w.targets, &remoteWriteTarget{
+			cfg:            cfg,
+			client:         client,
+			externalLabels: sortedLabels(external),
+			queue:          queue,
+			ctx:            ctx,
+			cancel:         cancel,
+			done:           make(chan struct{}),
+		})
+
+		// 预先初始化各分类，保证从0开始输出
+		for _, result := range []string{batchResultSent, batchResultFailed, batchResultDropped} {
+			w.batches.WithLabelValues(cfg.Name, result)
+		}
+		w.samples.WithLabelValues(cfg.Name)
+	}
+	return w, nil
+}
+
+// Describe 实现 prometheus.Collector 接口
+func (w *RemoteWriter) Describe(ch chan<- *prometheus.Desc) {
+	w.batches.Describe(ch)
+	w.samples.Describe(ch)
+	w.lastSuccess.Describe(ch)
+	ch <- w.queueDesc
+}
+
+// Collect 实现 prometheus.Collector 接口
+func (w *RemoteWriter) Collect(ch chan<- prometheus.Metric) {
+	w.batches.Collect(ch)
+	w.samples.Collect(ch)
+	w.lastSuccess.Collect(ch)
+	for _, t := range w.targets {
+		ch <- prometheus.MustNewConstMetric(w.queueDesc, prometheus.GaugeValue, float64(t.queue.len()), t.cfg.Name)
+	}
+}
+
+// Start 启动周期采集与各目标的发送协程
+func (w *RemoteWriter) Start() {
+	for _, t := range w.targets {
+		go w.send(t)
+	}
+	go w.run()
+	logger.Logger.Infof("Remote write started with %d target(s), interval %v", len(w.targets), w.interval)
+}
+
+// Close 停止采集与发送；未发送的批次在配置了磁盘队列时保留到下次启动
+func (w *RemoteWriter) Close() {
+	w.once.Do(func() {
+		close(w.stop)
+		<-w.done
+		for _, t := range w.targets {
+			t.cancel()
+			<-t.done
+			if n := t.queue.len(); n > 0 {
+				if t.cfg.QueueDir != "" {
+					logger.Logger.Infof("Kept %d unsent remote_write batch(es) for target %s in disk queue", n, t.cfg.Name)
+				} else {
+					logger.Logger.Warnf("Discarded %d unsent remote_write batch(es) for target %s on shutdown", n, t.cfg.Name)
+				}
+			}
+		}
+	})
+}
+
+// run 启动后立即采集一次，之后按周期采集并加入各目标的发送队列
+func (w *RemoteWriter) run() {
+	defer close(w.done)
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		w.collectOnce()
+		select {
+		case <-ticker.C:
+		case <-w.stop:
+			return
+		}
+	}
+}
+
+// collectOnce 采集一次并按各目标的附加标签编码入队
+func (w *RemoteWriter) collectOnce() {
+	families, err := w.gather()
+	if err != nil {
+		if len(families) == 0 {
+			logger.Logger.Warnf("Remote write gather failed, skipping this push: %v", err)
+			return
+		}
+		logger.Logger.Warnf("Remote write gather partially failed, pushing collected metrics: %v", err)
+	}
+
+	allSeries := familiesToSeries(families, time.Now().UnixMilli())
+	if len(allSeries) == 0 {
+		return
+	}
+	for _, t := range w.targets {
+		encoded, err := encodeWriteRequest(allSeries, t.externalLabels)
+		if err != nil {
+			logger.Logger.Errorf("Failed to encode remote_write batch for target %s: %v", t.cfg.Name, err)
+			w.batches.WithLabelValues(t.cfg.Name, batchResultDropped).Inc()
+			continue
+		}
+		dropped, err := t.queue.push(&batch{data: snappy.Encode(nil, encoded), samples: len(allSeries)})
+		if err != nil {
+			logger.Logger.Errorf("Failed to queue remote_write batch for target %s: %v", t.cfg.Name, err)
+			w.batches.WithLabelValues(t.cfg.Name, batchResultDropped).Inc()
+			continue
+		}
+		if dropped > 0 {
+			logger.Logger.Warnf("Remote write queue for target %s is full, dropped %d oldest batch(es)", t.cfg.Name, dropped)
+			w.batches.WithLabelValues(t.cfg.Name, batchResultDropped).Add(float64(dropped))
+		}
+	}
+}
+
+// send 按入队顺序发送批次，可重试的失败按指数退避重试，直到成功或停止
+func (w *RemoteWriter) send(t *remoteWriteTarget) {
+	defer close(t.done)
+	backoff := initialBackoff
+	for {
+		b := t.queue.peek()
+		if b == nil {
+			select {
+			case <-t.queue.notify:
+				continue
+			case <-t.ctx.Done():
+				return
+			}
+		}
+
+		err := t.post(b.data)
+		if err == nil {
+			t.queue.remove(b)
+			w.batches.WithLabelValues(t.cfg.Name, batchResultSent).Inc()
+			w.samples.WithLabelValues(t.cfg.Name).Add(float64(b.samples))
+			w.lastSuccess.WithLabelValues(t.cfg.Name).SetToCurrentTime()
+			backoff = initialBackoff
+			continue
+		}
+		if t.ctx.Err() != nil {
+			return
+		}
+
+		var perr *permanentError
+		if errors.As(err, &perr) {
+			logger.Logger.Errorf("Remote write batch rejected by target %s, dropping it: %v", t.cfg.Name, err)
+			t.queue.remove(b)
+			w.batches.WithLabelValues(t.cfg.Name, batchResultDropped).Inc()
+			continue
+		}
+
+		logger.Logger.Warnf("Remote write to target %s failed, retrying in %v (%d batch(es) queued): %v",
+			t.cfg.Name, backoff, t.queue.len(), err)
+		w.batches.WithLabelValues(t.cfg.Name, batchResultFailed).Inc()
+		select {
+		case <-time.After(backoff):
+		case <-t.ctx.Done():
+			return
+		}
+		backoff *= 2
+		if backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+}
+
+// post 发送一个批次
+func (t *remoteWriteTarget) post(data []byte) error {
+	req, err := http.NewRequestWithContext(t.ctx, http.MethodPost, t.cfg.URL, bytes.NewReader(data))
+	if err != nil {
+		return &permanentError{fmt.Errorf("failed to create request: %w", err)}
+	}
+	req.Header.Set("Content-Encoding", "snappy")
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	req.Header.Set("User-Agent", "dameng_exporter/"+config.GetVersion())
+	req.Header.Set("X-Prometheus-Remote-Write-Version", remoteWriteVersion)
+	if t.cfg.BearerToken != "" {
+		req.Header.Set("Authorization", "Bearer "+t.cfg.BearerToken)
+	} else if t.cfg.Username != "" {
+		req.SetBasicAuth(t.cfg.Username, t.cfg.Password)
+	}
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseBody))
+	err = fmt.Errorf("unexpected status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	if resp.StatusCode >= 400 && resp.StatusCode < 500 && resp.StatusCode != http.StatusTooManyRequests {
+		return &permanentError{err}
+	}
+	return err
+}
+
+// encodeWriteRequest 将序列编码为 remote_write 的 prompb.WriteRequest，每条序列合并目标的附加标签
+func encodeWriteRequest(allSeries []series, external []label) ([]byte, error) {
+	req := prompb.WriteRequest{Timeseries: make([]prompb.TimeSeries, 0, len(allSeries))}
+	for _, s := range allSeries {
+		merged := withExternalLabels(s.labels, external)
+		labels := make([]prompb.Label, 0, len(merged))
+		for _, l := range merged {
+			labels = append(labels, prompb.Label{Name: l.name, Value: l.value})
+		}
+		req.Timeseries = append(req.Timeseries, prompb.TimeSeries{
+			Labels:  labels,
+			Samples: []prompb.Sample{{Value: s.value, Timestamp: s.timestamp}},
+		})
+	}
+	return req.Marshal()
+}
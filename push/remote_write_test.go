@@ -0,0 +1,113 @@
+package push
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/golang/snappy"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/prometheus/prompb"
+	"google.golang.org/protobuf/proto"
+)
+
+// TestEncodeWriteRequestRoundTrip 解码 remote_write 请求，校验标签、附加标签与样本
+func TestEncodeWriteRequestRoundTrip(t *testing.T) {
+	families := []*dto.MetricFamily{
+		{
+			Name: proto.String("dmdbms_start_time_info"),
+			Type: dto.MetricType_GAUGE.Enum(),
+			Metric: []*dto.Metric{{
+				Label: []*dto.LabelPair{
+					{Name: proto.String("datasource"), Value: proto.String("dm_primary")},
+					{Name: proto.String("cluster"), Value: proto.String("own")},
+				},
+				Gauge:       &dto.Gauge{Value: proto.Float64(1.5)},
+				TimestampMs: proto.Int64(1700000000123),
+			}},
+		},
+		{
+			Name: proto.String("dmdbms_slow_sql_total"),
+			Type: dto.MetricType_HISTOGRAM.Enum(),
+			Metric: []*dto.Metric{{
+				Histogram: &dto.Histogram{
+					SampleCount: proto.Uint64(3),
+					SampleSum:   proto.Float64(4.5),
+					Bucket: []*dto.Bucket{
+						{UpperBound: proto.Float64(1), CumulativeCount: proto.Uint64(2)},
+					},
+				},
+			}},
+		},
+	}
+	external := sortedLabels(map[string]string{"cluster": "external", "region": "cn"})
+	const nowMs = 1700000000000
+
+	encoded, err := encodeWriteRequest(familiesToSeries(families, nowMs), external)
+	if err != nil {
+		t.Fatalf("encodeWriteRequest() error = %v", err)
+	}
+	raw, err := snappy.Decode(nil, snappy.Encode(nil, encoded))
+	if err != nil {
+		t.Fatalf("snappy.Decode() error = %v", err)
+	}
+	var req prompb.WriteRequest
+	if err := req.Unmarshal(raw); err != nil {
+		t.Fatalf("prompb.WriteRequest.Unmarshal() error = %v", err)
+	}
+
+	// 序列自身的 cluster 标签优先于附加标签，直方图补齐 +Inf 桶
+	want := []prompb.TimeSeries{
+		{
+			Labels: []prompb.Label{
+				{Name: "__name__", Value: "dmdbms_start_time_info"},
+				{Name: "cluster", Value: "own"},
+				{Name: "datasource", Value: "dm_primary"},
+				{Name: "region", Value: "cn"},
+			},
+			Samples: []prompb.Sample{{Value: 1.5, Timestamp: 1700000000123}},
+		},
+		{
+			Labels: []prompb.Label{
+				{Name: "__name__", Value: "dmdbms_slow_sql_total_bucket"},
+				{Name: "cluster", Value: "external"},
+				{Name: "le", Value: "1"},
+				{Name: "region", Value: "cn"},
+			},
+			Samples: []prompb.Sample{{Value: 2, Timestamp: nowMs}},
+		},
+		{
+			Labels: []prompb.Label{
+				{Name: "__name__", Value: "dmdbms_slow_sql_total_bucket"},
+				{Name: "cluster", Value: "external"},
+				{Name: "le", Value: "+Inf"},
+				{Name: "region", Value: "cn"},
+			},
+			Samples: []prompb.Sample{{Value: 3, Timestamp: nowMs}},
+		},
+		{
+			Labels: []prompb.Label{
+				{Name: "__name__", Value: "dmdbms_slow_sql_total_sum"},
+				{Name: "cluster", Value: "external"},
+				{Name: "region", Value: "cn"},
+			},
+			Samples: []prompb.Sample{{Value: 4.5, Timestamp: nowMs}},
+		},
+		{
+			Labels: []prompb.Label{
+				{Name: "__name__", Value: "dmdbms_slow_sql_total_count"},
+				{Name: "cluster", Value: "external"},
+				{Name: "region", Value: "cn"},
+			},
+			Samples: []prompb.Sample{{Value: 3, Timestamp: nowMs}},
+		},
+	}
+	if len(req.Timeseries) != len(want) {
+		t.Fatalf("decoded %d series, want %d: %+v", len(req.Timeseries), len(want), req.Timeseries)
+	}
+	for i := range want {
+		got := req.Timeseries[i]
+		if !reflect.DeepEqual(got.Labels, want[i].Labels) || !reflect.DeepEqual(got.Samples, want[i].Samples) {
+			t.Errorf("series %d = %+v %+v, want %+v %+v", i, got.Labels, got.Samples, want[i].Labels, want[i].Samples)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package push
+
+import (
+	"math"
+	"sort"
+	"strconv"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/model"
+)
+
+// label 序列标签
+type label struct {
+	name  string
+	value string
+}
+
+// series 一条带单个样本的时间序列
+type series struct {
+	labels    []label // 包含 __name__，按名称排序
+	value     float64
+	timestamp int64 // 毫秒
+}
+
+// familiesToSeries 将采集结果展开为时间序列：直方图展开为 _bucket/_sum/_count，
+// 摘要展开为分位数/_sum/_count，未携带时间戳的样本使用 nowMs
+func familiesToSeries(families []*dto.MetricFamily, nowMs int64) []series {
+	var result []series
+	for _, mf := range families {
+		name := mf.GetName()
+		for _, m := range mf.GetMetric() {
+			ts := nowMs
+			if m.TimestampMs != nil {
+				ts = m.GetTimestampMs()
+			}
+			add := func(suffix string, value float64, extra ...label) {
+				result = append(result, newSeries(name+suffix, m.GetLabel(), extra, value, ts))
+			}
+
+			switch mf.GetType() {
+			case dto.MetricType_COUNTER:
+				add("", m.GetCounter().GetValue())
+			case dto.MetricType_GAUGE:
+				add("", m.GetGauge().GetValue())
+			case dto.MetricType_UNTYPED:
+				add("", m.GetUntyped().GetValue())
+			case dto.MetricType_SUMMARY:
+				s := m.GetSummary()
+				for _, q := range s.GetQuantile() {
+					add("", q.GetValue(), label{model.QuantileLabel, formatFloat(q.GetQuantile())})
+				}
+				add("_sum", s.GetSampleSum())
+				add("_count", float64(s.GetSampleCount()))
+			case dto.MetricType_HISTOGRAM, dto.MetricType_GAUGE_HISTOGRAM:
+				h := m.GetHistogram()
+				hasInf := false
+				for _, b := range h.GetBucket() {
+					if math.IsInf(b.GetUpperBound(), 1) {
+						hasInf = true
+					}
+					add("_bucket", float64(b.GetCumulativeCount()), label{model.BucketLabel, formatFloat(b.GetUpperBound())})
+				}
+				if !hasInf {
+					add("_bucket", float64(h.GetSampleCount()), label{model.BucketLabel, "+Inf"})
+				}
+				add("_sum", h.GetSampleSum())
+				add("_count", float64(h.GetSampleCount()))
+			}
+		}
+	}
+	return result
+}
+
+// newSeries 组装序列标签并按名称排序
+func newSeries(name string, pairs []*dto.LabelPair, extra []label, value float64, ts int64) series {
+	labels := make([]label, 0, len(pairs)+len(extra)+1)
+	labels = append(labels, label{model.MetricNameLabel, name})
+	for _, p := range pairs {
+		labels = append(labels, label{p.GetName(), p.GetValue()})
+	}
+	labels = append(labels, extra...)
+	sort.Slice(labels, func(i, j int) bool { return labels[i].name < labels[j].name })
+	return series{labels: labels, value: value, timestamp: ts}
+}
+
+// withExternalLabels 合并附加标签，序列自身已有的同名标签优先
+func withExternalLabels(labels []label, external []label) []label {
+	if len(external) == 0 {
+		return labels
+	}
+	merged := make([]label, 0, len(labels)+len(external))
+	i, j := 0, 0
+	for i < len(labels) || j < len(external) {
+		switch {
+		case j >= len(external):
+			merged = append(merged, labels[i])
+			i++
+		case i >= len(labels):
+			merged = append(merged, external[j])
+			j++
+		case labels[i].name == external[j].name:
+			merged = append(merged, labels[i])
+			i++
+			j++
+		case labels[i].name < external[j].name:
+			merged = append(merged, labels[i])
+			i++
+		default:
+			merged = append(merged, external[j])
+			j++
+		}
+	}
+	return merged
+}
+
+// sortedLabels 将标签集合转换为按名称排序的切片
+func sortedLabels(m map[string]string) []label {
+	labels := make([]label, 0, len(m))
+	for name, value := range m {
+		labels = append(labels, label{name, value})
+	}
+	sort.Slice(labels, func(i, j int) bool { return labels[i].name < labels[j].name })
+	return labels
+}
+
+// formatFloat 按 Prometheus 文本格式输出 le/quantile 标签值
+func formatFloat(f float64) string {
+	switch {
+	case math.IsInf(f, 1):
+		return "+Inf"
+	case math.IsInf(f, -1):
+		return "-Inf"
+	case math.IsNaN(f):
+		return "NaN"
+	default:
+		return strconv.FormatFloat(f, 'g', -1, 64)
+	}
+}
@@ -0,0 +1,440 @@
+package push
+
+import (
+	"bytes"
+	"context"
+	"dameng_exporter/config"
+	"dameng_exporter/db"
+	"dameng_exporter/logger"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/model"
+)
+
+const (
+	// zabbixHeader Zabbix 协议头，版本号 1 表示未压缩
+	zabbixHeader = "ZBXD\x01"
+	// zabbixBatchSize 单次连接发送的监控项数量
+	zabbixBatchSize = 250
+	// zabbixMaxResponse 读取响应的最大字节数
+	zabbixMaxResponse = 64 * 1024
+	// zabbixDiscoveryKey 通过 trapper 推送的低级别发现监控项 key，参数为实体名称
+	zabbixDiscoveryKey = "dameng.discovery"
+)
+
+// 发送结果分类
+const (
+	sendResultSuccess = "success" // Zabbix 返回 success
+	sendResultFailure = "failure" // 连接失败或 Zabbix 拒绝
+	valueProcessed    = "processed"
+	valueFailed       = "failed"
+)
+
+// zabbixInfoPattern 解析响应中的处理结果，如 "processed: 2; failed: 1; total: 3; seconds spent: 0.000055"
+var zabbixInfoPattern = regexp.MustCompile(`processed: (\d+); failed: (\d+)`)
+
+// zabbixValue 发送给 Zabbix 的单个监控项值
+type zabbixValue struct {
+	Host  string `json:"host"`
+	Key   string `json:"key"`
+	Value string `json:"value"`
+	Clock int64  `json:"clock"`
+	NS    int64  `json:"ns"`
+}
+
+// zabbixRequest sender 协议请求
+type zabbixRequest struct {
+	Request string        `json:"request"`
+	Data    []zabbixValue `json:"data"`
+	Clock   int64         `json:"clock"`
+	NS      int64         `json:"ns"`
+}
+
+// zabbixResponse sender 协议响应
+type zabbixResponse struct {
+	Response string `json:"response"`
+	Info     string `json:"info"`
+}
+
+// ZabbixSender 按周期采集注册器中的指标，通过 Zabbix sender 协议推送到 Zabbix server/proxy 的 trapper 监控项；
+// 主机名取自 hostLabel 标签，监控项 key 为指标名加标签参数，低级别发现数据按单独的周期推送
+type ZabbixSender struct {
+	server            string
+	interval          time.Duration
+	discoveryInterval time.Duration // 为0时不推送发现数据
+	timeout           time.Duration
+	hostLabel         string
+	poolManager       *db.DBPoolManager
+	gather            GatherFunc
+	itemParams        map[string][]string // 实体指标名 -> 作为 key 参数的标签
+
+	stop chan struct{}
+	done chan struct{}
+	once sync.Once
+
+	sends  *prometheus.CounterVec
+	values *prometheus.CounterVec
+}
+
+// NewZabbixSender 创建 Zabbix 推送器，需调用 Start 启动
+func NewZabbixSender(server string, interval, discoveryInterval, timeout time.Duration, hostLabel string, poolManager *db.DBPoolManager, gather GatherFunc) *ZabbixSender {
+	// 非正的周期会使 time.NewTicker panic，超时为0会使每次发送立即失败，均回退为默认值
+	if interval <= 0 {
+		interval = time.Duration(config.DefaultMultiSourceConfig.ZabbixIntervalSeconds) * time.Second
+	}
+	if timeout <= 0 {
+		timeout = time.Duration(config.DefaultMultiSourceConfig.ZabbixTimeoutSeconds) * time.Second
+	}
+	s := &ZabbixSender{
+		server:            server,
+		interval:          interval,
+		discoveryInterval: discoveryInterval,
+		timeout:           timeout,
+		hostLabel:         hostLabel,
+		poolManager:       poolManager,
+		gather:            gather,
+		itemParams:        make(map[string][]string),
+		stop:              make(chan struct{}),
+		done:              make(chan struct{}),
+		sends: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "dameng_exporter_zabbix_sends_total",
+			Help: "Total number of Zabbix sender requests by result (success, failure)",
+		}, []string{"result"}),
+		values: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "dameng_exporter_zabbix_values_total",
+			Help: "Total number of values reported by Zabbix by result (processed, failed)",
+		}, []string{"result"}),
+	}
+	for _, e := range discoveryEntities {
+		params := make([]string, 0, len(e.Macros))
+		for _, m := range e.Macros {
+			params = append(params, m.Label)
+		}
+		for _, item := range e.Items {
+			s.itemParams[item] = params
+		}
+	}
+	// 预先初始化各分类，保证从0开始输出
+	for _, result := range []string{sendResultSuccess, sendResultFailure} {
+		s.sends.WithLabelValues(result)
+	}
+	for _, result := range []string{valueProcessed, valueFailed} {
+		s.values.WithLabelValues(result)
+	}
+	return s
+}
+
+// Describe 实现 prometheus.Collector 接口
+func (s *ZabbixSender) Describe(ch chan<- *prometheus.Desc) {
+	s.sends.Describe(ch)
+	s.values.Describe(ch)
+}
+
+// Collect 实现 prometheus.Collector 接口
+func (s *ZabbixSender) Collect(ch chan<- prometheus.Metric) {
+	s.sends.Collect(ch)
+	s.values.Collect(ch)
+}
+
+// Start 启动周期推送
+func (s *ZabbixSender) Start() {
+	go s.run()
+	logger.Logger.Infof("Zabbix sender started, server %s, interval %v, discovery interval %v", s.server, s.interval, s.discoveryInterval)
+}
+
+// Close 停止推送，等待进行中的推送结束
+func (s *ZabbixSender) Close() {
+	s.once.Do(func() {
+		close(s.stop)
+		<-s.done
+	})
+}
+
+// run 启动后立即推送一次，之后按周期推送；发现数据随首次推送发送，之后按发现周期发送
+func (s *ZabbixSender) run() {
+	defer close(s.done)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-s.stop
+		cancel()
+	}()
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	var lastDiscovery time.Time
+	for {
+		now := time.Now()
+		withDiscovery := s.discoveryInterval > 0 && now.Sub(lastDiscovery) >= s.discoveryInterval
+		if s.sendOnce(ctx, now, withDiscovery) && withDiscovery {
+			lastDiscovery = now
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// sendOnce 采集一次并推送监控项值，返回是否全部发送成功
+func (s *ZabbixSender) sendOnce(ctx context.Context, now time.Time, withDiscovery bool) bool {
+	families, err := s.gather()
+	if err != nil {
+		if len(families) == 0 {
+			logger.Logger.Warnf("Zabbix gather failed, skipping this push: %v", err)
+			return false
+		}
+		logger.Logger.Warnf("Zabbix gather partially failed, pushing collected metrics: %v", err)
+	}
+
+	snapshots := s.poolManager.DatasourceSnapshots()
+	values := s.itemValues(families, snapshots, now)
+	if withDiscovery {
+		values = append(s.discoveryValues(families, snapshots, now), values...)
+	}
+	if len(values) == 0 {
+		return true
+	}
+
+	ok := true
+	var processed, failed int
+	for start := 0; start < len(values); start += zabbixBatchSize {
+		end := min(start+zabbixBatchSize, len(values))
+		p, f, err := s.send(ctx, values[start:end], now)
+		if err != nil {
+			logger.Logger.Errorf("Zabbix push to %s failed: %v", s.server, err)
+			s.sends.WithLabelValues(sendResultFailure).Inc()
+			ok = false
+			if ctx.Err() != nil {
+				break
+			}
+			continue
+		}
+		s.sends.WithLabelValues(sendResultSuccess).Inc()
+		s.values.WithLabelValues(valueProcessed).Add(float64(p))
+		s.values.WithLabelValues(valueFailed).Add(float64(f))
+		processed += p
+		failed += f
+	}
+	// 监控项不存在、类型不是 trapper 或主机不匹配时 Zabbix 计为 failed，且不返回具体原因
+	if failed > 0 {
+		logger.Logger.Warnf("Zabbix processed %d value(s) and rejected %d, check that hosts and trapper items exist", processed, failed)
+	}
+	return ok
+}
+
+// send 通过一次 TCP 连接发送一批监控项值，返回 Zabbix 报告的处理与失败数量
+func (s *ZabbixSender) send(ctx context.Context, values []zabbixValue, now time.Time) (int, int, error) {
+	body, err := json.Marshal(zabbixRequest{
+		Request: "sender data",
+		Data:    values,
+		Clock:   now.Unix(),
+		NS:      int64(now.Nanosecond()),
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+
+	dialer := net.Dialer{Timeout: s.timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", s.server)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer conn.Close()
+	if err := conn.SetDeadline(time.Now().Add(s.timeout)); err != nil {
+		return 0, 0, err
+	}
+
+	if _, err := conn.Write(encodeZabbixPacket(body)); err != nil {
+		return 0, 0, fmt.Errorf("write request: %w", err)
+	}
+	data, err := readZabbixPacket(conn)
+	if err != nil {
+		return 0, 0, fmt.Errorf("read response: %w", err)
+	}
+
+	var resp zabbixResponse
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return 0, 0, fmt.Errorf("decode response: %w", err)
+	}
+	if resp.Response != "success" {
+		return 0, 0, fmt.Errorf("server responded %q: %s", resp.Response, resp.Info)
+	}
+	match := zabbixInfoPattern.FindStringSubmatch(resp.Info)
+	if match == nil {
+		return len(values), 0, nil
+	}
+	processed, _ := strconv.Atoi(match[1])
+	failed, _ := strconv.Atoi(match[2])
+	return processed, failed, nil
+}
+
+// encodeZabbixPacket 按 Zabbix 协议封装数据：协议头 + 8字节小端长度 + 数据
+func encodeZabbixPacket(data []byte) []byte {
+	packet := make([]byte, 0, len(zabbixHeader)+8+len(data))
+	packet = append(packet, zabbixHeader...)
+	packet = binary.LittleEndian.AppendUint64(packet, uint64(len(data)))
+	return append(packet, data...)
+}
+
+// readZabbixPacket 读取并校验 Zabbix 协议响应
+func readZabbixPacket(r io.Reader) ([]byte, error) {
+	header := make([]byte, len(zabbixHeader)+8)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(header[:len(zabbixHeader)], []byte(zabbixHeader)) {
+		return nil, fmt.Errorf("unexpected protocol header %q", header[:len(zabbixHeader)])
+	}
+	size := binary.LittleEndian.Uint64(header[len(zabbixHeader):])
+	if size > zabbixMaxResponse {
+		return nil, fmt.Errorf("response too large (%d bytes)", size)
+	}
+	data := make([]byte, size)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// itemValues 将采集结果转换为监控项值，不带主机标签的指标与 NaN/Inf 值不推送
+func (s *ZabbixSender) itemValues(families []*dto.MetricFamily, snapshots []db.DatasourceSnapshot, now time.Time) []zabbixValue {
+	injected := injectedLabels(snapshots)
+	var values []zabbixValue
+	for _, sr := range familiesToSeries(families, now.UnixMilli()) {
+		if math.IsNaN(sr.value) || math.IsInf(sr.value, 0) {
+			continue
+		}
+		var name, host, ds string
+		for _, l := range sr.labels {
+			switch l.name {
+			case model.MetricNameLabel:
+				name = l.value
+			case s.hostLabel:
+				host = l.value
+			}
+			if l.name == db.DatasourceLabel {
+				ds = l.value
+			}
+		}
+		if host == "" {
+			continue
+		}
+		values = append(values, zabbixValue{
+			Host:  host,
+			Key:   s.itemKey(name, sr.labels, injected[ds]),
+			Value: strconv.FormatFloat(sr.value, 'f', -1, 64),
+			Clock: sr.timestamp / 1000,
+			NS:    (sr.timestamp % 1000) * int64(time.Millisecond),
+		})
+	}
+	return values
+}
+
+// itemKey 生成监控项 key：发现实体的指标以实体标签作为参数（与发现宏顺序一致），
+// 其他指标以除数据源注入标签外的全部标签按名称排序作为参数
+func (s *ZabbixSender) itemKey(name string, labels []label, injected map[string]bool) string {
+	var params []string
+	if names, ok := s.itemParams[name]; ok {
+		for _, n := range names {
+			value := ""
+			for _, l := range labels {
+				if l.name == n {
+					value = l.value
+				}
+			}
+			params = append(params, value)
+		}
+	} else {
+		for _, l := range labels {
+			if l.name == model.MetricNameLabel || l.name == db.DatasourceLabel || l.name == s.hostLabel || injected[l.name] {
+				continue
+			}
+			params = append(params, l.value)
+		}
+	}
+	return zabbixKey(name, params)
+}
+
+// discoveryValues 为每个主机生成各实体的低级别发现数据
+func (s *ZabbixSender) discoveryValues(families []*dto.MetricFamily, snapshots []db.DatasourceSnapshot, now time.Time) []zabbixValue {
+	var values []zabbixValue
+	for _, entity := range discoveryEntities {
+		byHost := make(map[string][]map[string]string)
+		for _, row := range BuildDiscovery(entity, families, snapshots, s.hostLabel, "").Data {
+			if host := row[macroHost]; host != "" {
+				byHost[host] = append(byHost[host], row)
+			}
+		}
+		for _, host := range sortedHosts(byHost) {
+			data, err := json.Marshal(Discovery{Data: byHost[host]})
+			if err != nil {
+				continue
+			}
+			values = append(values, zabbixValue{
+				Host:  host,
+				Key:   zabbixKey(zabbixDiscoveryKey, []string{entity.Name}),
+				Value: string(data),
+				Clock: now.Unix(),
+				NS:    int64(now.Nanosecond()),
+			})
+		}
+	}
+	return values
+}
+
+// injectedLabels 返回各数据源注入到指标中的标签名，按 datasource 标签值索引
+func injectedLabels(snapshots []db.DatasourceSnapshot) map[string]map[string]bool {
+	result := make(map[string]map[string]bool, len(snapshots))
+	for _, snapshot := range snapshots {
+		if snapshot.Config == nil {
+			continue
+		}
+		labels := snapshot.InjectedLabels()
+		names := make(map[string]bool, len(labels))
+		for name := range labels {
+			names[name] = true
+		}
+		result[labels[db.DatasourceLabel]] = names
+	}
+	return result
+}
+
+// sortedHosts 按名称排序返回主机名
+func sortedHosts(m map[string][]map[string]string) []string {
+	hosts := make([]string, 0, len(m))
+	for host := range m {
+		hosts = append(hosts, host)
+	}
+	sort.Strings(hosts)
+	return hosts
+}
+
+// zabbixKey 拼接监控项 key，参数包含逗号、方括号、双引号或以空格开头时加双引号
+func zabbixKey(name string, params []string) string {
+	if len(params) == 0 {
+		return name
+	}
+	quoted := make([]string, len(params))
+	for i, p := range params {
+		if strings.ContainsAny(p, `,[]"`) || strings.HasPrefix(p, " ") {
+			p = `"` + strings.ReplaceAll(p, `"`, `\"`) + `"`
+		}
+		quoted[i] = p
+	}
+	return name + "[" + strings.Join(quoted, ",") + "]"
+}
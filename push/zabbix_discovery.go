@@ -0,0 +1,147 @@
+package push
+
+import (
+	"dameng_exporter/db"
+	"dameng_exporter/utils"
+	"sort"
+	"strings"
+
+	dto "github.com/prometheus/client_model/go"
+)
+
+// DiscoveryMacro 低级别发现宏与来源标签的对应关系
+type DiscoveryMacro struct {
+	Label string // 指标中的标签名
+	Macro string // 低级别发现宏，如 {#TABLESPACE}
+}
+
+// DiscoveryEntity 可通过低级别发现获取的实体
+type DiscoveryEntity struct {
+	Name      string           // 实体名称，用于发现接口路径与 dameng.discovery[<name>] 监控项
+	Collector string           // 提供该实体的采集器，为空表示数据源本身
+	Metric    string           // 用于生成发现数据的指标
+	Macros    []DiscoveryMacro // 标识实体的标签，按监控项 key 参数的顺序排列
+	Items     []string         // 以实体标签作为 key 参数的指标
+}
+
+// Zabbix 低级别发现宏
+const (
+	macroDataSource = "{#DATASOURCE}" // 数据源名称（datasource 标签）
+	macroHost       = "{#HOST}"       // 推送时使用的 Zabbix 主机名
+	macroDBHost     = "{#DBHOST}"     // 数据库地址
+)
+
+// discoveryEntities 支持的发现实体，顺序即接口返回的可选实体顺序
+var discoveryEntities = []DiscoveryEntity{
+	{Name: "datasources"},
+	{
+		Name:      "tablespaces",
+		Collector: "tablespace",
+		Metric:    "dmdbms_tablespace_size_total_info",
+		Macros:    []DiscoveryMacro{{"tablespace_name", "{#TABLESPACE}"}},
+		Items:     []string{"dmdbms_tablespace_size_total_info", "dmdbms_tablespace_size_free_info"},
+	},
+	{
+		// 数据文件指标的 tablespace_name 标签实际为数据文件路径
+		Name:      "datafiles",
+		Collector: "tablespace_datafile",
+		Metric:    "dmdbms_tablespace_file_total_info",
+		Macros:    []DiscoveryMacro{{"tablespace_name", "{#DATAFILE}"}},
+		Items:     []string{"dmdbms_tablespace_file_total_info", "dmdbms_tablespace_file_free_info"},
+	},
+	{
+		Name:      "archives",
+		Collector: "arch_status",
+		Metric:    "dmdbms_arch_status_info",
+		Macros:    []DiscoveryMacro{{"arch_type", "{#ARCH_TYPE}"}, {"arch_dest", "{#ARCH_DEST}"}},
+		Items:     []string{"dmdbms_arch_status_info", "dmdbms_arch_send_detail_info", "dmdbms_arch_send_diff_value"},
+	},
+	{
+		Name:      "users",
+		Collector: "users",
+		Metric:    "dmdbms_user_list_info",
+		Macros:    []DiscoveryMacro{{"username", "{#USERNAME}"}},
+		Items:     []string{"dmdbms_user_list_info"},
+	},
+}
+
+// DiscoveryEntities 返回支持的发现实体
+func DiscoveryEntities() []DiscoveryEntity {
+	return discoveryEntities
+}
+
+// DiscoveryEntityNames 返回支持的发现实体名称
+func DiscoveryEntityNames() []string {
+	names := make([]string, 0, len(discoveryEntities))
+	for _, e := range discoveryEntities {
+		names = append(names, e.Name)
+	}
+	return names
+}
+
+// FindDiscoveryEntity 按名称查找发现实体
+func FindDiscoveryEntity(name string) (DiscoveryEntity, bool) {
+	for _, e := range discoveryEntities {
+		if e.Name == name {
+			return e, true
+		}
+	}
+	return DiscoveryEntity{}, false
+}
+
+// Discovery 低级别发现结果，序列化为 {"data":[...]}
+type Discovery struct {
+	Data []map[string]string `json:"data"`
+}
+
+// BuildDiscovery 根据数据源快照与采集结果生成实体的发现数据，
+// hostLabel 为取值作为 Zabbix 主机名的标签，dataSource 非空时只返回该数据源的实体
+func BuildDiscovery(entity DiscoveryEntity, families []*dto.MetricFamily, snapshots []db.DatasourceSnapshot, hostLabel, dataSource string) Discovery {
+	rows := make([]map[string]string, 0)
+	if entity.Metric == "" {
+		for _, snapshot := range snapshots {
+			if snapshot.Config == nil || !snapshot.Config.Enabled {
+				continue
+			}
+			labels := snapshot.InjectedLabels()
+			if dataSource != "" && labels[db.DatasourceLabel] != dataSource {
+				continue
+			}
+			rows = append(rows, map[string]string{
+				macroDataSource: labels[db.DatasourceLabel],
+				macroHost:       labels[hostLabel],
+				macroDBHost:     snapshot.Host,
+			})
+		}
+		return Discovery{Data: rows}
+	}
+
+	seen := make(map[string]bool)
+	for _, mf := range families {
+		if mf.GetName() != entity.Metric {
+			continue
+		}
+		for _, m := range mf.GetMetric() {
+			ds, _ := utils.MetricLabelValue(m, db.DatasourceLabel)
+			if dataSource != "" && ds != dataSource {
+				continue
+			}
+			host, _ := utils.MetricLabelValue(m, hostLabel)
+			row := map[string]string{macroDataSource: ds, macroHost: host}
+			id := []string{ds}
+			for _, macro := range entity.Macros {
+				value, _ := utils.MetricLabelValue(m, macro.Label)
+				row[macro.Macro] = value
+				id = append(id, value)
+			}
+			key := strings.Join(id, "\x00")
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			rows = append(rows, row)
+		}
+	}
+	sort.SliceStable(rows, func(i, j int) bool { return rows[i][macroDataSource] < rows[j][macroDataSource] })
+	return Discovery{Data: rows}
+}
@@ -0,0 +1,73 @@
+package push
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+// TestEncodeZabbixPacket 按 Zabbix 协议解析 ZBXD 帧：
+// "ZBXD" + 标志位 0x01 + 4字节小端数据长度 + 4字节保留位 + 数据
+func TestEncodeZabbixPacket(t *testing.T) {
+	request := zabbixRequest{
+		Request: "sender data",
+		Data:    []zabbixValue{{Host: "dm-host", Key: "dmdbms.session.count[dm_primary]", Value: "7", Clock: 1700000000}},
+		Clock:   1700000000,
+	}
+	body, err := json.Marshal(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	packet := encodeZabbixPacket(body)
+
+	if len(packet) != 13+len(body) {
+		t.Fatalf("packet length = %d, want %d", len(packet), 13+len(body))
+	}
+	if string(packet[:4]) != "ZBXD" || packet[4] != 0x01 {
+		t.Errorf("packet header = %q, flags = %#x, want ZBXD with flags 0x01", packet[:4], packet[4])
+	}
+	if size := binary.LittleEndian.Uint32(packet[5:9]); size != uint32(len(body)) {
+		t.Errorf("data length = %d, want %d", size, len(body))
+	}
+	if reserved := binary.LittleEndian.Uint32(packet[9:13]); reserved != 0 {
+		t.Errorf("reserved = %d, want 0", reserved)
+	}
+
+	var decoded zabbixRequest
+	if err := json.Unmarshal(packet[13:], &decoded); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	if !reflect.DeepEqual(decoded, request) {
+		t.Errorf("decoded request = %+v, want %+v", decoded, request)
+	}
+
+	data, err := readZabbixPacket(bytes.NewReader(packet))
+	if err != nil {
+		t.Fatalf("readZabbixPacket() error = %v", err)
+	}
+	if !bytes.Equal(data, body) {
+		t.Errorf("readZabbixPacket() = %q, want %q", data, body)
+	}
+}
+
+// TestReadZabbixPacketInvalid 协议头错误、长度超限与数据不完整时返回错误
+func TestReadZabbixPacketInvalid(t *testing.T) {
+	oversized := []byte(zabbixHeader)
+	oversized = binary.LittleEndian.AppendUint64(oversized, zabbixMaxResponse+1)
+	tests := map[string][]byte{
+		"compressed flag": append([]byte("ZBXD\x03"), make([]byte, 8)...),
+		"wrong header":    append([]byte("HTTP/"), make([]byte, 8)...),
+		"too large":       oversized,
+		"truncated data":  encodeZabbixPacket([]byte(`{"response":"success"}`))[:20],
+		"truncated frame": []byte("ZBXD\x01"),
+	}
+	for name, packet := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := readZabbixPacket(bytes.NewReader(packet)); err == nil {
+				t.Error("readZabbixPacket() error = nil, want error")
+			}
+		})
+	}
+}
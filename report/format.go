@@ -0,0 +1,117 @@
+package report
+
+import (
+	"dameng_exporter/collector"
+	"dameng_exporter/utils"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/prometheus/common/expfmt"
+)
+
+// 报告输出格式
+const (
+	FormatJSON = "json"
+	FormatCSV  = "csv"
+	FormatProm = "prom"
+)
+
+// Formats 支持的输出格式
+var Formats = []string{FormatJSON, FormatCSV, FormatProm}
+
+// Write 按指定格式输出报告
+func Write(w io.Writer, r *Report, format string) error {
+	switch format {
+	case FormatJSON:
+		return WriteJSON(w, r)
+	case FormatCSV:
+		return WriteCSV(w, r)
+	case FormatProm:
+		return WriteProm(w, r)
+	default:
+		return fmt.Errorf("unsupported report format %q, must be one of %s", format, strings.Join(Formats, ", "))
+	}
+}
+
+// WriteJSON 以缩进的 JSON 输出报告
+func WriteJSON(w io.Writer, r *Report) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// WriteCSV 以 CSV 输出报告，每个样本一行；数据源不可用与采集器出错时输出一行只含错误的记录
+func WriteCSV(w io.Writer, r *Report) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"datasource", "collector", "metric", "type", "labels", "value", "error"})
+	for _, s := range r.Exporter {
+		cw.Write(sampleRecord("", collector.ExporterCollectorName, s))
+	}
+	for _, e := range r.Errors {
+		cw.Write([]string{"", "", "", "", "", "", e})
+	}
+	for _, ds := range r.Datasources {
+		if ds.Error != "" {
+			cw.Write([]string{ds.Name, "", "", "", "", "", ds.Error})
+		}
+		for _, c := range ds.Collectors {
+			if c.Error != "" {
+				cw.Write([]string{ds.Name, c.Name, "", "", "", "", c.Error})
+			}
+			for _, s := range c.Samples {
+				cw.Write(sampleRecord(ds.Name, c.Name, s))
+			}
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// sampleRecord 生成样本的 CSV 记录，标签按名称排序输出为 name="value" 形式
+func sampleRecord(dataSource, collectorName string, s Sample) []string {
+	pairs := make([]string, 0, len(s.Labels))
+	for _, name := range utils.SortedKeys(s.Labels) {
+		pairs = append(pairs, fmt.Sprintf("%s=%q", name, s.Labels[name]))
+	}
+	return []string{dataSource, collectorName, s.Metric, s.Type, strings.Join(pairs, ","), s.Value.String(), ""}
+}
+
+// WriteProm 以 Prometheus 文本格式输出报告，按采集器分组，数据源与采集器的错误以注释行输出
+func WriteProm(w io.Writer, r *Report) error {
+	for _, ds := range r.Datasources {
+		if ds.Error != "" {
+			writeErrorComment(w, fmt.Sprintf("datasource %s unavailable: %s", ds.Name, ds.Error))
+		}
+	}
+	for _, group := range r.families {
+		fmt.Fprintf(w, "# collector %s\n", group.Collector)
+		if group.Err != nil {
+			writeErrorComment(w, group.Err.Error())
+		}
+		for _, ds := range r.Datasources {
+			for _, c := range ds.Collectors {
+				if c.Name == group.Collector && c.Error != "" {
+					writeErrorComment(w, fmt.Sprintf("datasource %s: %s", ds.Name, c.Error))
+				}
+			}
+		}
+		for _, mf := range group.Families {
+			if _, err := expfmt.MetricFamilyToText(w, mf); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// writeErrorComment 以注释行输出错误，多行错误（如 prometheus.MultiError 或数据库返回的错误）逐行加上 # ERROR: 前缀，
+// 保证输出仍可按 Prometheus 文本格式解析，错误内容也不会被当作 HELP/TYPE 行
+func writeErrorComment(w io.Writer, message string) {
+	message = strings.ReplaceAll(strings.TrimRight(message, "\r\n"), "\r\n", "\n")
+	for _, line := range strings.Split(message, "\n") {
+		fmt.Fprintf(w, "# ERROR: %s\n", line)
+	}
+}
@@ -0,0 +1,516 @@
+package report
+
+import (
+	"dameng_exporter/collector"
+	"dameng_exporter/config"
+	"dameng_exporter/db"
+	"dameng_exporter/utils"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+)
+
+// InspectionCollectors 巡检使用的采集器，与 collect[] 参数中的名称一致
+var InspectionCollectors = []string{"tablespace", "license", "users", "arch_status", "log_history", "instance_log_error", "buffer_pool"}
+
+// 检查结论
+const (
+	VerdictPass   = "pass"   // 通过
+	VerdictNoData = "nodata" // 未采集到数据，无法判定
+	VerdictWarn   = "warn"   // 警告
+	VerdictFail   = "fail"   // 失败
+)
+
+// verdictSeverity 检查结论的严重程度，数据源的结论取各检查项中最严重的一项
+var verdictSeverity = map[string]int{VerdictPass: 0, VerdictNoData: 1, VerdictWarn: 2, VerdictFail: 3}
+
+// maxErrorLogDetails 实例错误日志在报告中最多列出的条数
+const maxErrorLogDetails = 10
+
+// Check 单个检查项的结果
+type Check struct {
+	Name      string   // 检查项标识
+	Title     string   // 检查项名称
+	Verdict   string   // 检查结论
+	Value     string   // 实际值
+	Threshold string   // 判定阈值
+	Details   []string // 明细，如超过阈值的表空间
+}
+
+// DatasourceInspection 单个数据源的巡检结果
+type DatasourceInspection struct {
+	Name    string
+	Host    string
+	Labels  map[string]string
+	Healthy bool
+	Error   string // 数据源不可用的原因
+	Verdict string // 各检查项中最严重的结论
+	Checks  []Check
+}
+
+// Inspection 巡检报告，包含各数据源的检查结果与汇总
+type Inspection struct {
+	GeneratedAt time.Time
+	Version     string
+	Verdict     string         // 所有数据源中最严重的结论
+	Counts      map[string]int // 各结论的数据源数量
+	Columns     []Column       // 汇总表的检查项，按报告中的顺序排列
+	Datasources []DatasourceInspection
+}
+
+// Column 汇总表中的检查项
+type Column struct {
+	Name  string
+	Title string
+}
+
+// Check 返回指定的检查项，数据源不可用时除连接检查外均不存在
+func (d DatasourceInspection) Check(name string) *Check {
+	for i := range d.Checks {
+		if d.Checks[i].Name == name {
+			return &d.Checks[i]
+		}
+	}
+	return nil
+}
+
+// ExitCode 根据巡检结论计算进程退出码：全部通过为0，全部数据源失败为2，其余为1
+func (i *Inspection) ExitCode() int {
+	switch {
+	case len(i.Datasources) == 0 || i.Counts[VerdictFail] == len(i.Datasources):
+		return db.ExitAllFailed
+	case i.Counts[VerdictPass] == len(i.Datasources):
+		return db.ExitAllSucceeded
+	default:
+		return db.ExitPartial
+	}
+}
+
+// Inspect 根据巡检采集器的指标与运行结果，按阈值对每个启用的数据源生成检查结论
+func Inspect(poolManager *db.DBPoolManager, families []*dto.MetricFamily, runs []collector.CollectorRun, cfg config.ReportConfig, version string, now time.Time) *Inspection {
+	dataSources, targets := enabledDatasources(poolManager)
+
+	// 按数据源与指标名称归集样本
+	samples := make([]map[string][]Sample, len(dataSources))
+	for i := range samples {
+		samples[i] = make(map[string][]Sample)
+	}
+	for _, mf := range families {
+		for _, m := range mf.GetMetric() {
+			value, _ := utils.MetricLabelValue(m, db.DatasourceLabel)
+			t, ok := targets[value]
+			if !ok {
+				continue
+			}
+			for _, s := range flatten(mf, m, t.grouping) {
+				samples[t.index][s.Metric] = append(samples[t.index][s.Metric], s)
+			}
+		}
+	}
+
+	runIndex := make(map[string]collector.CollectorRun, len(runs))
+	for _, run := range runs {
+		runIndex[run.Collector+"|"+run.DataSource] = run
+	}
+
+	inspection := &Inspection{
+		GeneratedAt: now,
+		Version:     version,
+		Verdict:     VerdictPass,
+		Counts:      map[string]int{VerdictPass: 0, VerdictNoData: 0, VerdictWarn: 0, VerdictFail: 0},
+	}
+	seen := make(map[string]bool)
+	for i, ds := range dataSources {
+		in := inspector{
+			cfg:     cfg,
+			now:     now,
+			ds:      ds,
+			samples: samples[i],
+			runs:    runIndex,
+		}
+		result := in.inspect()
+		for _, c := range result.Checks {
+			if !seen[c.Name] {
+				seen[c.Name] = true
+				inspection.Columns = append(inspection.Columns, Column{Name: c.Name, Title: c.Title})
+			}
+		}
+		inspection.Counts[result.Verdict]++
+		inspection.Verdict = worse(inspection.Verdict, result.Verdict)
+		inspection.Datasources = append(inspection.Datasources, result)
+	}
+	return inspection
+}
+
+// worse 返回两个结论中更严重的一个
+func worse(a, b string) string {
+	if verdictSeverity[b] > verdictSeverity[a] {
+		return b
+	}
+	return a
+}
+
+// inspector 单个数据源的检查上下文
+type inspector struct {
+	cfg     config.ReportConfig
+	now     time.Time
+	ds      Datasource
+	samples map[string][]Sample
+	runs    map[string]collector.CollectorRun
+}
+
+// inspect 依次执行各检查项
+func (in *inspector) inspect() DatasourceInspection {
+	result := DatasourceInspection{
+		Name:    in.ds.Name,
+		Host:    in.ds.Host,
+		Labels:  in.ds.Labels,
+		Healthy: in.ds.Healthy,
+		Error:   in.ds.Error,
+		Verdict: VerdictPass,
+	}
+
+	checks := []Check{in.checkConnectivity()}
+	if in.ds.Healthy {
+		checks = append(checks,
+			in.checkTablespace(),
+			in.checkLicense(),
+			in.checkUserExpiry(),
+			in.checkArchive(),
+			in.checkRedoSwitch(),
+			in.checkErrorLog(),
+			in.checkBufferPool(),
+		)
+	}
+	for _, c := range checks {
+		result.Verdict = worse(result.Verdict, c.Verdict)
+	}
+	result.Checks = checks
+	return result
+}
+
+// noData 返回无法判定的检查结果，采集器出错时附带错误原因
+func (in *inspector) noData(c Check, collectorName string) Check {
+	c.Verdict = VerdictNoData
+	c.Value = "未采集到数据"
+	if run, ok := in.runs[collectorName+"|"+in.ds.Name]; ok && run.Error != "" {
+		c.Details = append(c.Details, fmt.Sprintf("采集器 %s: %s", collectorName, run.Error))
+	}
+	return c
+}
+
+// checkConnectivity 检查数据源连接状态
+func (in *inspector) checkConnectivity() Check {
+	c := Check{Name: "connectivity", Title: "数据源连接", Threshold: "可连接"}
+	if in.ds.Healthy {
+		c.Verdict = VerdictPass
+		c.Value = "正常"
+		return c
+	}
+	c.Verdict = VerdictFail
+	c.Value = "不可用"
+	if in.ds.Error != "" {
+		c.Details = []string{in.ds.Error}
+	}
+	return c
+}
+
+// checkTablespace 检查各表空间使用率，取使用率最高的表空间判定
+func (in *inspector) checkTablespace() Check {
+	c := Check{
+		Name:      "tablespace_usage",
+		Title:     "表空间使用率",
+		Threshold: thresholdText(">=", in.cfg.TablespaceUsageWarnPercent, in.cfg.TablespaceUsageFailPercent, "%"),
+	}
+	free := make(map[string]float64)
+	for _, s := range in.samples["dmdbms_tablespace_size_free_info"] {
+		free[s.Labels["tablespace_name"]] = float64(s.Value)
+	}
+	totals := in.samples["dmdbms_tablespace_size_total_info"]
+	if len(totals) == 0 {
+		return in.noData(c, "tablespace")
+	}
+
+	type usage struct {
+		name         string
+		total, used  float64
+		usagePercent float64
+	}
+	var usages []usage
+	for _, s := range totals {
+		name := s.Labels["tablespace_name"]
+		total := float64(s.Value)
+		if total <= 0 {
+			continue
+		}
+		used := total - free[name]
+		usages = append(usages, usage{name: name, total: total, used: used, usagePercent: used / total * 100})
+	}
+	if len(usages) == 0 {
+		return in.noData(c, "tablespace")
+	}
+	sort.Slice(usages, func(i, j int) bool { return usages[i].usagePercent > usages[j].usagePercent })
+
+	top := usages[0]
+	c.Verdict = higherIsWorse(top.usagePercent, in.cfg.TablespaceUsageWarnPercent, in.cfg.TablespaceUsageFailPercent)
+	c.Value = fmt.Sprintf("%s %.1f%%（共 %d 个表空间）", top.name, top.usagePercent, len(usages))
+	for _, u := range usages {
+		if higherIsWorse(u.usagePercent, in.cfg.TablespaceUsageWarnPercent, in.cfg.TablespaceUsageFailPercent) == VerdictPass {
+			break
+		}
+		c.Details = append(c.Details, fmt.Sprintf("%s: %.1f%%（已用 %.0f MB / 共 %.0f MB）", u.name, u.usagePercent, u.used, u.total))
+	}
+	return c
+}
+
+// checkLicense 检查授权剩余天数，无限制授权判定为通过，已过期判定为失败
+func (in *inspector) checkLicense() Check {
+	c := Check{
+		Name:      "license",
+		Title:     "授权有效期",
+		Threshold: thresholdText("<=", float64(in.cfg.LicenseWarnDays), float64(in.cfg.LicenseFailDays), " 天"),
+	}
+	samples := in.samples["dmdbms_license_date"]
+	if len(samples) == 0 {
+		return in.noData(c, "license")
+	}
+	s := samples[0]
+	if s.Labels["date_day_str"] == "无限制" {
+		c.Verdict = VerdictPass
+		c.Value = "无限制"
+		return c
+	}
+	days := float64(s.Value)
+	c.Value = fmt.Sprintf("剩余 %.0f 天", days)
+	if days < 0 {
+		c.Verdict = VerdictFail
+		c.Value = fmt.Sprintf("已过期 %.0f 天", -days)
+		return c
+	}
+	c.Verdict = lowerIsWorse(days, float64(in.cfg.LicenseWarnDays), float64(in.cfg.LicenseFailDays), true)
+	return c
+}
+
+// checkUserExpiry 检查用户密码剩余有效天数，未设置有效期与已锁定的用户不参与判定
+func (in *inspector) checkUserExpiry() Check {
+	c := Check{
+		Name:      "user_expiry",
+		Title:     "用户密码有效期",
+		Threshold: thresholdText("<=", float64(in.cfg.UserExpiryWarnDays), float64(in.cfg.UserExpiryFailDays), " 天"),
+	}
+	samples := in.samples["dmdbms_user_list_info"]
+	if len(samples) == 0 {
+		return in.noData(c, "users")
+	}
+
+	type expiry struct {
+		user string
+		days float64
+	}
+	var expiries []expiry
+	for _, s := range samples {
+		// 值为1表示账户已锁定
+		if s.Value == 1 {
+			continue
+		}
+		days, err := strconv.ParseFloat(strings.TrimSpace(s.Labels["expiry_date_day"]), 64)
+		if err != nil {
+			continue
+		}
+		expiries = append(expiries, expiry{user: s.Labels["username"], days: days})
+	}
+	c.Verdict = VerdictPass
+	if len(expiries) == 0 {
+		c.Value = fmt.Sprintf("%d 个用户均未设置有效期", len(samples))
+		return c
+	}
+	sort.Slice(expiries, func(i, j int) bool { return expiries[i].days < expiries[j].days })
+
+	c.Value = fmt.Sprintf("%s 剩余 %.0f 天（%d 个用户设置了有效期）", expiries[0].user, expiries[0].days, len(expiries))
+	for _, e := range expiries {
+		verdict := lowerIsWorse(e.days, float64(in.cfg.UserExpiryWarnDays), float64(in.cfg.UserExpiryFailDays), true)
+		if e.days < 0 {
+			verdict = VerdictFail
+		}
+		if verdict == VerdictPass {
+			break
+		}
+		c.Verdict = worse(c.Verdict, verdict)
+		if e.days < 0 {
+			c.Details = append(c.Details, fmt.Sprintf("%s: 已过期 %.0f 天", e.user, -e.days))
+		} else {
+			c.Details = append(c.Details, fmt.Sprintf("%s: 剩余 %.0f 天", e.user, e.days))
+		}
+	}
+	return c
+}
+
+// checkArchive 检查归档状态与各归档目标的状态
+func (in *inspector) checkArchive() Check {
+	c := Check{Name: "archive", Title: "归档状态", Threshold: "已开启且有效"}
+	if !in.cfg.ArchiveRequired {
+		c.Threshold = "有效（未开启时不判定）"
+	}
+	samples := in.samples["dmdbms_arch_status"]
+	if len(samples) == 0 {
+		return in.noData(c, "arch_status")
+	}
+
+	// 取值：1 有效，2 无效，-1 未开启
+	switch samples[0].Value {
+	case 1:
+		c.Verdict = VerdictPass
+		c.Value = "有效"
+	case -1:
+		c.Value = "未开启"
+		c.Verdict = VerdictPass
+		if in.cfg.ArchiveRequired {
+			c.Verdict = VerdictFail
+		}
+		return c
+	default:
+		c.Verdict = VerdictFail
+		c.Value = "无效"
+	}
+
+	for _, s := range in.samples["dmdbms_arch_status_info"] {
+		if s.Value == 0 {
+			c.Verdict = VerdictFail
+			c.Details = append(c.Details, fmt.Sprintf("%s 归档 %s 无效", s.Labels["arch_type"], s.Labels["arch_dest"]))
+		}
+	}
+	if len(c.Details) > 0 {
+		c.Value = fmt.Sprintf("%d 个归档目标无效", len(c.Details))
+	}
+	return c
+}
+
+// checkRedoSwitch 检查距最近一次 redo 日志切换的时间
+func (in *inspector) checkRedoSwitch() Check {
+	c := Check{
+		Name:      "redo_switch",
+		Title:     "Redo 日志切换",
+		Threshold: thresholdText(">", float64(in.cfg.RedoSwitchWarnMinutes), float64(in.cfg.RedoSwitchFailMinutes), " 分钟未切换"),
+	}
+	samples := in.samples["dmdbms_redo_last_switch_time_seconds"]
+	if len(samples) == 0 || samples[0].Value <= 0 {
+		return in.noData(c, "log_history")
+	}
+	last := time.Unix(int64(samples[0].Value), 0)
+	minutes := in.now.Sub(last).Minutes()
+	c.Value = fmt.Sprintf("最近切换于 %s（%.0f 分钟前）", last.Format("2006-01-02 15:04:05"), minutes)
+	c.Verdict = higherIsWorseStrict(minutes, float64(in.cfg.RedoSwitchWarnMinutes), float64(in.cfg.RedoSwitchFailMinutes))
+	return c
+}
+
+// checkErrorLog 检查最近5分钟的实例错误日志条数
+func (in *inspector) checkErrorLog() Check {
+	c := Check{
+		Name:      "error_log",
+		Title:     "实例错误日志（最近5分钟）",
+		Threshold: thresholdText(">=", float64(in.cfg.ErrorLogWarnCount), float64(in.cfg.ErrorLogFailCount), " 条"),
+	}
+	// 没有错误日志时采集器不输出样本，以采集器是否成功运行区分无错误与未采集
+	samples := in.samples["dmdbms_instance_log_error_info"]
+	run, ran := in.runs["instance_log_error|"+in.ds.Name]
+	if len(samples) == 0 && (!ran || run.Error != "") {
+		return in.noData(c, "instance_log_error")
+	}
+
+	c.Value = fmt.Sprintf("%d 条", len(samples))
+	c.Verdict = higherIsWorse(float64(len(samples)), float64(in.cfg.ErrorLogWarnCount), float64(in.cfg.ErrorLogFailCount))
+	sort.Slice(samples, func(i, j int) bool { return samples[i].Labels["log_time"] > samples[j].Labels["log_time"] })
+	for i, s := range samples {
+		if i == maxErrorLogDetails {
+			c.Details = append(c.Details, fmt.Sprintf("……另有 %d 条", len(samples)-maxErrorLogDetails))
+			break
+		}
+		c.Details = append(c.Details, fmt.Sprintf("%s [%s] %s", s.Labels["log_time"], s.Labels["level"], s.Labels["txt"]))
+	}
+	return c
+}
+
+// checkBufferPool 检查缓冲池命中率，取命中率最低的缓冲池判定
+func (in *inspector) checkBufferPool() Check {
+	c := Check{
+		Name:      "buffer_pool_hit",
+		Title:     "缓冲池命中率",
+		Threshold: thresholdText("<", in.cfg.BufferPoolHitWarnPercent, in.cfg.BufferPoolHitFailPercent, "%"),
+	}
+	samples := in.samples["dmdbms_bufferpool_info"]
+	if len(samples) == 0 {
+		return in.noData(c, "buffer_pool")
+	}
+	lowest := samples[0]
+	for _, s := range samples[1:] {
+		if s.Value < lowest.Value {
+			lowest = s
+		}
+	}
+	// 命中率取值为0到1
+	percent := float64(lowest.Value) * 100
+	c.Value = fmt.Sprintf("%s %.2f%%", lowest.Labels["buffer_name"], percent)
+	c.Verdict = lowerIsWorse(percent, in.cfg.BufferPoolHitWarnPercent, in.cfg.BufferPoolHitFailPercent, false)
+	return c
+}
+
+// higherIsWorse 数值达到阈值即判定，阈值为0表示不判定该级别
+func higherIsWorse(value, warn, fail float64) string {
+	switch {
+	case fail > 0 && value >= fail:
+		return VerdictFail
+	case warn > 0 && value >= warn:
+		return VerdictWarn
+	default:
+		return VerdictPass
+	}
+}
+
+// higherIsWorseStrict 数值超过阈值才判定，阈值为0表示不判定该级别
+func higherIsWorseStrict(value, warn, fail float64) string {
+	switch {
+	case fail > 0 && value > fail:
+		return VerdictFail
+	case warn > 0 && value > warn:
+		return VerdictWarn
+	default:
+		return VerdictPass
+	}
+}
+
+// lowerIsWorse 数值低于阈值即判定（inclusive 为 true 时包含等于），阈值为0表示不判定该级别
+func lowerIsWorse(value, warn, fail float64, inclusive bool) string {
+	below := func(threshold float64) bool {
+		if inclusive {
+			return value <= threshold
+		}
+		return value < threshold
+	}
+	switch {
+	case fail > 0 && below(fail):
+		return VerdictFail
+	case warn > 0 && below(warn):
+		return VerdictWarn
+	default:
+		return VerdictPass
+	}
+}
+
+// thresholdText 生成阈值说明，未配置的级别不显示
+func thresholdText(op string, warn, fail float64, unit string) string {
+	var parts []string
+	if warn > 0 {
+		parts = append(parts, fmt.Sprintf("警告 %s %s%s", op, strconv.FormatFloat(warn, 'f', -1, 64), unit))
+	}
+	if fail > 0 {
+		parts = append(parts, fmt.Sprintf("失败 %s %s%s", op, strconv.FormatFloat(fail, 'f', -1, 64), unit))
+	}
+	if len(parts) == 0 {
+		return "不判定"
+	}
+	return strings.Join(parts, "，")
+}
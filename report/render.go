@@ -0,0 +1,79 @@
+package report
+
+import (
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// 巡检报告输出格式
+const (
+	FormatHTML     = "html"
+	FormatMarkdown = "markdown"
+)
+
+// InspectionFormats 巡检报告支持的输出格式
+var InspectionFormats = []string{FormatHTML, FormatMarkdown}
+
+//go:embed templates/inspection.html templates/inspection.md
+var templateFS embed.FS
+
+// verdictTexts 检查结论的显示名称
+var verdictTexts = map[string]string{
+	VerdictPass:   "通过",
+	VerdictWarn:   "警告",
+	VerdictFail:   "失败",
+	VerdictNoData: "无数据",
+}
+
+// formatTime 格式化报告中的时间
+func formatTime(t time.Time) string {
+	return t.Format("2006-01-02 15:04:05")
+}
+
+// verdictText 返回检查结论的显示名称
+func verdictText(verdict string) string {
+	if text, ok := verdictTexts[verdict]; ok {
+		return text
+	}
+	return verdict
+}
+
+// htmlInspectionTemplate HTML 巡检报告模板
+var htmlInspectionTemplate = htmltemplate.Must(htmltemplate.New("inspection.html").Funcs(htmltemplate.FuncMap{
+	"formatTime":  formatTime,
+	"verdictText": verdictText,
+}).ParseFS(templateFS, "templates/inspection.html"))
+
+// markdownInspectionTemplate Markdown 巡检报告模板
+var markdownInspectionTemplate = template.Must(template.New("inspection.md").Funcs(template.FuncMap{
+	"formatTime":  formatTime,
+	"verdictText": verdictText,
+	"cell":        markdownCell,
+	"join": func(items []string) string {
+		return strings.Join(items, "；")
+	},
+}).ParseFS(templateFS, "templates/inspection.md"))
+
+// markdownCell 转义表格单元格中的竖线与换行
+func markdownCell(s string) string {
+	s = strings.ReplaceAll(s, "|", `\|`)
+	s = strings.ReplaceAll(s, "\r", "")
+	return strings.ReplaceAll(s, "\n", " ")
+}
+
+// WriteInspection 按指定格式输出巡检报告
+func WriteInspection(w io.Writer, inspection *Inspection, format string) error {
+	switch format {
+	case FormatHTML:
+		return htmlInspectionTemplate.Execute(w, inspection)
+	case FormatMarkdown:
+		return markdownInspectionTemplate.Execute(w, inspection)
+	default:
+		return fmt.Errorf("unsupported inspection format %q, must be one of %s", format, strings.Join(InspectionFormats, ", "))
+	}
+}
@@ -0,0 +1,268 @@
+package report
+
+import (
+	"dameng_exporter/collector"
+	"dameng_exporter/db"
+	"dameng_exporter/utils"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/model"
+)
+
+// Report 一次性采集报告，按数据源与采集器分组
+type Report struct {
+	GeneratedAt time.Time    `json:"generatedAt"`
+	Version     string       `json:"version"`
+	DurationMs  int64        `json:"durationMs"`
+	Exporter    []Sample     `json:"exporter"`         // 不带数据源标签的指标，如 dmdbms_build_info
+	Errors      []string     `json:"errors,omitempty"` // 不属于单个数据源的采集错误
+	Datasources []Datasource `json:"datasources"`
+
+	// families 按采集器分组的原始指标，用于输出 Prometheus 文本格式
+	families []collector.CollectorFamilies
+}
+
+// Datasource 单个数据源的采集结果
+type Datasource struct {
+	Name       string            `json:"name"`
+	Host       string            `json:"host"`
+	Labels     map[string]string `json:"labels,omitempty"`
+	Healthy    bool              `json:"healthy"`
+	Error      string            `json:"error,omitempty"` // 数据源不可用的原因
+	Collectors []Collector       `json:"collectors"`
+}
+
+// Collector 单个采集器在数据源上的采集结果
+type Collector struct {
+	Name       string   `json:"name"`
+	DurationMs int64    `json:"durationMs"`
+	Error      string   `json:"error,omitempty"` // 跳过、超时或 panic 的原因
+	Samples    []Sample `json:"samples"`
+}
+
+// Sample 一个样本，标签中不包含数据源及其附加标签
+type Sample struct {
+	Metric string            `json:"metric"`
+	Type   string            `json:"type"`
+	Labels map[string]string `json:"labels,omitempty"`
+	Value  Value             `json:"value"`
+}
+
+// Value 样本值，NaN 与 ±Inf 在 JSON 中输出为字符串
+type Value float64
+
+// MarshalJSON 实现 json.Marshaler 接口
+func (v Value) MarshalJSON() ([]byte, error) {
+	f := float64(v)
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return json.Marshal(v.String())
+	}
+	return json.Marshal(f)
+}
+
+// String 返回与 Prometheus 文本格式一致的数值表示
+func (v Value) String() string {
+	return formatFloat(float64(v))
+}
+
+// Build 根据按采集器分组的指标、采集器运行结果与数据源状态生成报告，只包含启用的数据源
+func Build(poolManager *db.DBPoolManager, groups []collector.CollectorFamilies, runs []collector.CollectorRun, version string, start time.Time) *Report {
+	r := &Report{
+		GeneratedAt: start,
+		Version:     version,
+		DurationMs:  time.Since(start).Milliseconds(),
+		families:    groups,
+	}
+
+	var targets map[string]target
+	r.Datasources, targets = enabledDatasources(poolManager)
+
+	runIndex := make(map[string]collector.CollectorRun, len(runs))
+	for _, run := range runs {
+		runIndex[run.Collector+"|"+run.DataSource] = run
+	}
+
+	for _, group := range groups {
+		if group.Err != nil {
+			r.Errors = append(r.Errors, fmt.Sprintf("%s: %v", group.Collector, group.Err))
+		}
+
+		samples := make(map[int][]Sample)
+		for _, mf := range group.Families {
+			for _, m := range mf.GetMetric() {
+				value, ok := utils.MetricLabelValue(m, db.DatasourceLabel)
+				t, known := targets[value]
+				if !ok || !known {
+					r.Exporter = append(r.Exporter, flatten(mf, m, nil)...)
+					continue
+				}
+				samples[t.index] = append(samples[t.index], flatten(mf, m, t.grouping)...)
+			}
+		}
+
+		for i := range r.Datasources {
+			ds := &r.Datasources[i]
+			run, ran := runIndex[group.Collector+"|"+ds.Name]
+			if !ran && len(samples[i]) == 0 {
+				continue
+			}
+			ds.Collectors = append(ds.Collectors, Collector{
+				Name:       group.Collector,
+				DurationMs: run.Duration.Milliseconds(),
+				Error:      run.Error,
+				Samples:    samples[i],
+			})
+		}
+	}
+	return r
+}
+
+// target 报告中的数据源位置，以及注入到该数据源指标中、需要从样本中去除的标签
+type target struct {
+	index    int
+	grouping map[string]string
+}
+
+// enabledDatasources 返回启用的数据源，以及按注入的 datasource 标签值定位数据源的索引
+func enabledDatasources(poolManager *db.DBPoolManager) ([]Datasource, map[string]target) {
+	var dataSources []Datasource
+	targets := make(map[string]target)
+	for _, snapshot := range poolManager.DatasourceSnapshots() {
+		if snapshot.Config == nil || !snapshot.Config.Enabled {
+			continue
+		}
+		ds := Datasource{
+			Name:    snapshot.Config.Name,
+			Host:    snapshot.Host,
+			Labels:  snapshot.Labels,
+			Healthy: snapshot.Healthy,
+		}
+		if !snapshot.Healthy {
+			ds.Error = snapshot.LastError
+		}
+		grouping := snapshot.InjectedLabels()
+		targets[grouping[db.DatasourceLabel]] = target{index: len(dataSources), grouping: grouping}
+		dataSources = append(dataSources, ds)
+	}
+	return dataSources, targets
+}
+
+// ExitCode 根据各数据源的结果计算进程退出码：数据源不可用或任一采集器出错视为该数据源失败
+func (r *Report) ExitCode() int {
+	failed := 0
+	for _, ds := range r.Datasources {
+		if !ds.Healthy || ds.failedCollectors() > 0 {
+			failed++
+		}
+	}
+	switch {
+	case len(r.Datasources) == 0 || failed == len(r.Datasources):
+		return db.ExitAllFailed
+	case failed > 0 || len(r.Errors) > 0:
+		return db.ExitPartial
+	default:
+		return db.ExitAllSucceeded
+	}
+}
+
+// failedCollectors 返回出错的采集器数量
+func (ds *Datasource) failedCollectors() int {
+	n := 0
+	for _, c := range ds.Collectors {
+		if c.Error != "" {
+			n++
+		}
+	}
+	return n
+}
+
+// flatten 将指标展开为样本：直方图展开为 _bucket/_sum/_count，摘要展开为分位数/_sum/_count，
+// 去除 strip 中的标签
+func flatten(mf *dto.MetricFamily, m *dto.Metric, strip map[string]string) []Sample {
+	name := mf.GetName()
+	typ := metricType(mf.GetType())
+	var samples []Sample
+	add := func(suffix string, value float64, extra ...string) {
+		labels := make(map[string]string, len(m.GetLabel())+len(extra)/2)
+		for _, lp := range m.GetLabel() {
+			if _, ok := strip[lp.GetName()]; !ok {
+				labels[lp.GetName()] = lp.GetValue()
+			}
+		}
+		for i := 0; i+1 < len(extra); i += 2 {
+			labels[extra[i]] = extra[i+1]
+		}
+		if len(labels) == 0 {
+			labels = nil
+		}
+		samples = append(samples, Sample{Metric: name + suffix, Type: typ, Labels: labels, Value: Value(value)})
+	}
+
+	switch mf.GetType() {
+	case dto.MetricType_COUNTER:
+		add("", m.GetCounter().GetValue())
+	case dto.MetricType_GAUGE:
+		add("", m.GetGauge().GetValue())
+	case dto.MetricType_UNTYPED:
+		add("", m.GetUntyped().GetValue())
+	case dto.MetricType_SUMMARY:
+		s := m.GetSummary()
+		for _, q := range s.GetQuantile() {
+			add("", q.GetValue(), model.QuantileLabel, formatFloat(q.GetQuantile()))
+		}
+		add("_sum", s.GetSampleSum())
+		add("_count", float64(s.GetSampleCount()))
+	case dto.MetricType_HISTOGRAM, dto.MetricType_GAUGE_HISTOGRAM:
+		h := m.GetHistogram()
+		hasInf := false
+		for _, b := range h.GetBucket() {
+			if math.IsInf(b.GetUpperBound(), 1) {
+				hasInf = true
+			}
+			add("_bucket", float64(b.GetCumulativeCount()), model.BucketLabel, formatFloat(b.GetUpperBound()))
+		}
+		if !hasInf {
+			add("_bucket", float64(h.GetSampleCount()), model.BucketLabel, "+Inf")
+		}
+		add("_sum", h.GetSampleSum())
+		add("_count", float64(h.GetSampleCount()))
+	}
+	return samples
+}
+
+// metricType 返回指标类型名称
+func metricType(t dto.MetricType) string {
+	switch t {
+	case dto.MetricType_COUNTER:
+		return "counter"
+	case dto.MetricType_GAUGE:
+		return "gauge"
+	case dto.MetricType_SUMMARY:
+		return "summary"
+	case dto.MetricType_HISTOGRAM:
+		return "histogram"
+	case dto.MetricType_GAUGE_HISTOGRAM:
+		return "gaugehistogram"
+	default:
+		return "untyped"
+	}
+}
+
+// formatFloat 按 Prometheus 文本格式输出浮点数
+func formatFloat(f float64) string {
+	switch {
+	case math.IsInf(f, 1):
+		return "+Inf"
+	case math.IsInf(f, -1):
+		return "-Inf"
+	case math.IsNaN(f):
+		return "NaN"
+	default:
+		return strconv.FormatFloat(f, 'g', -1, 64)
+	}
+}
@@ -16,7 +16,7 @@ import (
 func CheckDBConnectionWithSource(dbConn *sql.DB, dataSource string) error {
 	if dbConn == nil {
 		err := fmt.Errorf("数据库连接未初始化")
-		logger.Logger.Errorf("[%s] 数据库连接未初始化，无法执行检查", dataSource)
+		logger.ForDatasource(dataSource).Errorf("[%s] 数据库连接未初始化，无法执行检查", dataSource)
 		if manager := db.GlobalPoolManager; manager != nil {
 			manager.MarkDatasourceFailed(dataSource, err)
 		}
@@ -31,7 +31,7 @@ func CheckDBConnectionWithSource(dbConn *sql.DB, dataSource string) error {
 	status := manager.GetDatasourceHealthStatus(dataSource)
 	if !status.Registered {
 		err := fmt.Errorf("数据源[%s]未注册或已禁用", dataSource)
-		logger.Logger.Warn(err.Error())
+		logger.ForDatasource(dataSource).Warnf("数据源[%s]未注册或已禁用", dataSource)
 		return err
 	}
 
@@ -41,9 +41,9 @@ func CheckDBConnectionWithSource(dbConn *sql.DB, dataSource string) error {
 			lastCheck = status.LastCheck.Format(time.DateTime)
 		}
 		if status.LastError != "" {
-			logger.Logger.Warnf("[%s] 数据源处于不可用状态，最近检查: %s，最近错误: %s", dataSource, lastCheck, status.LastError)
+			logger.ForDatasource(dataSource).Warnf("[%s] 数据源处于不可用状态，最近检查: %s，最近错误: %s", dataSource, lastCheck, status.LastError)
 		} else {
-			logger.Logger.Warnf("[%s] 数据源处于不可用状态，最近检查: %s", dataSource, lastCheck)
+			logger.ForDatasource(dataSource).Warnf("[%s] 数据源处于不可用状态，最近检查: %s", dataSource, lastCheck)
 		}
 		return fmt.Errorf("数据源[%s]当前不可用", dataSource)
 	}
@@ -53,10 +53,15 @@ func CheckDBConnectionWithSource(dbConn *sql.DB, dataSource string) error {
 
 // 封装通用的错误处理逻辑（带数据源标识）
 func HandleDbQueryErrorWithSource(err error, dataSource string) {
+	// 所有采集器的查询错误共用同一模板，按错误内容去重，避免一种持续出现的错误掩盖其他错误
+	log := logger.ForDatasource(dataSource)
+	if err != nil {
+		log = log.DedupBy(err.Error())
+	}
 	if errors.Is(err, context.DeadlineExceeded) {
-		logger.Logger.Errorf("[%s] 查询超时: %v", dataSource, err)
+		log.Errorf("[%s] 查询超时: %v", dataSource, err)
 	} else {
-		logger.Logger.Errorf("[%s] 查询数据库时发生错误: %v", dataSource, err)
+		log.Errorf("[%s] 查询数据库时发生错误: %v", dataSource, err)
 	}
 	if dataSource == "" || err == nil {
 		return
@@ -96,7 +101,7 @@ func triggerHealthCheckOnError(dataSource string) {
 	defer cancel()
 
 	if err := pool.DB.PingContext(ctx); err != nil {
-		logger.Logger.Warnf("[%s] 确认性健康检查失败，标记数据源不可用: %v", dataSource, err)
+		logger.ForDatasource(dataSource).Warnf("[%s] 确认性健康检查失败，标记数据源不可用: %v", dataSource, err)
 		manager.MarkDatasourceFailed(dataSource, err)
 	}
 }
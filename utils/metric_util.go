@@ -0,0 +1,27 @@
+package utils
+
+import (
+	"sort"
+
+	dto "github.com/prometheus/client_model/go"
+)
+
+// MetricLabelValue 返回指标中指定标签的值
+func MetricLabelValue(m *dto.Metric, name string) (string, bool) {
+	for _, lp := range m.GetLabel() {
+		if lp.GetName() == name {
+			return lp.GetValue(), true
+		}
+	}
+	return "", false
+}
+
+// SortedKeys 按名称排序返回键，保证输出与分组路径稳定
+func SortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
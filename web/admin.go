@@ -0,0 +1,208 @@
+package web
+
+import (
+	"dameng_exporter/config"
+	"dameng_exporter/db"
+	"dameng_exporter/logger"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+const (
+	// DatasourceActionPath 数据源运维操作路径，action 为 enable/disable/reconnect/delete
+	DatasourceActionPath = "/api/v1/datasources/{name}/{action}"
+
+	// maxDatasourceBody 新增数据源请求体的最大字节数
+	maxDatasourceBody = 64 * 1024
+
+	// RequestedWithHeader 运维操作必须携带的自定义请求头，跨站表单无法设置，用于防御 CSRF
+	RequestedWithHeader = "X-Requested-With"
+)
+
+// adminResponse 运维操作结果
+type adminResponse struct {
+	Action       string          `json:"action"`
+	Name         string          `json:"name"`
+	Error        string          `json:"error,omitempty"`        // 操作已生效但连接失败等附带错误
+	Persisted    bool            `json:"persisted"`              // 是否已写回配置文件
+	PersistError string          `json:"persistError,omitempty"` // 写回配置文件失败的原因，运行时修改仍然生效
+	Datasource   *datasourceView `json:"datasource,omitempty"`
+}
+
+// DatasourceActionHandler 数据源运维操作处理器，persist=true 时将启停与删除写回配置文件；
+// 请求必须携带 X-Requested-With 请求头，跨站请求会被拒绝
+func DatasourceActionHandler(poolManager *db.DBPoolManager) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := checkSameSite(r); err != nil {
+			writeJSONError(w, http.StatusForbidden, err.Error())
+			return
+		}
+		if r.Header.Get(RequestedWithHeader) == "" {
+			writeJSONError(w, http.StatusForbidden, "missing "+RequestedWithHeader+" header")
+			return
+		}
+
+		name := r.PathValue("name")
+		action := r.PathValue("action")
+		persist, err := parsePersist(r)
+		if err != nil {
+			writeJSONError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		resp := adminResponse{Action: action, Name: name}
+		switch action {
+		case "enable":
+			err = poolManager.EnableDataSource(name)
+		case "disable":
+			err = poolManager.DisableDataSource(name)
+		case "delete":
+			err = poolManager.RemoveDataSource(name)
+		case "reconnect":
+			persist = false
+			// 重连失败不视为请求错误，数据源已进入失败列表等待后台重试
+			if connErr := poolManager.ReconnectDataSource(name); connErr != nil {
+				if errors.Is(connErr, db.ErrDatasourceNotFound) || errors.Is(connErr, db.ErrDatasourceDisabled) {
+					err = connErr
+				} else {
+					resp.Error = connErr.Error()
+				}
+			}
+		default:
+			writeJSONError(w, http.StatusNotFound, "unknown action: "+action)
+			return
+		}
+		if err != nil {
+			writeAdminError(w, err)
+			return
+		}
+
+		logger.Logger.Infof("Admin API: %s datasource %s from %s", action, name, r.RemoteAddr)
+
+		if persist {
+			configFile := config.GlobalMultiConfig.ConfigFile
+			switch action {
+			case "enable":
+				err = config.PersistDataSourceEnabled(configFile, name, true)
+			case "disable":
+				err = config.PersistDataSourceEnabled(configFile, name, false)
+			case "delete":
+				err = config.PersistDataSourceRemoved(configFile, name)
+			}
+			resp.Persisted, resp.PersistError = persistResult(err)
+		}
+
+		if snapshot, ok := poolManager.DatasourceSnapshot(name); ok {
+			view := newDatasourceView(snapshot)
+			resp.Datasource = &view
+		}
+		writeJSON(w, http.StatusOK, resp)
+	})
+}
+
+// AddDatasourceHandler 运行时新增数据源处理器，请求体为 JSON，字段与配置文件中的 [[datasource]] 一致；
+// Content-Type 必须为 application/json，跨站请求会被拒绝
+func AddDatasourceHandler(poolManager *db.DBPoolManager) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := checkSameSite(r); err != nil {
+			writeJSONError(w, http.StatusForbidden, err.Error())
+			return
+		}
+		if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType != "application/json" {
+			writeJSONError(w, http.StatusUnsupportedMediaType, "Content-Type must be application/json")
+			return
+		}
+
+		persist, err := parsePersist(r)
+		if err != nil {
+			writeJSONError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		body, err := io.ReadAll(io.LimitReader(r.Body, maxDatasourceBody))
+		if err != nil {
+			writeJSONError(w, http.StatusBadRequest, "failed to read request body")
+			return
+		}
+		ds, err := config.ParseDataSourceJSON(body)
+		if err != nil {
+			writeJSONError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		cfg, err := poolManager.AddDataSource(ds)
+		if err != nil {
+			writeAdminError(w, err)
+			return
+		}
+		logger.Logger.Infof("Admin API: add datasource %s from %s", cfg.Name, r.RemoteAddr)
+
+		resp := adminResponse{Action: "add", Name: cfg.Name}
+		if persist {
+			resp.Persisted, resp.PersistError = persistResult(
+				config.PersistDataSourceAdded(config.GlobalMultiConfig.ConfigFile, *cfg))
+		}
+		if snapshot, ok := poolManager.DatasourceSnapshot(cfg.Name); ok {
+			view := newDatasourceView(snapshot)
+			resp.Datasource = &view
+		}
+		writeJSON(w, http.StatusCreated, resp)
+	})
+}
+
+// checkSameSite 拒绝浏览器发起的跨站请求：浏览器会自动携带缓存的 Basic 认证凭据，
+// 因此 Sec-Fetch-Site 不是 same-origin/none，或 Origin 与请求的主机不一致时视为 CSRF；
+// curl 等非浏览器客户端不携带这两个请求头，不受影响
+func checkSameSite(r *http.Request) error {
+	switch site := r.Header.Get("Sec-Fetch-Site"); site {
+	case "", "same-origin", "none":
+	default:
+		return errors.New("cross-site request rejected (Sec-Fetch-Site: " + site + ")")
+	}
+	if origin := r.Header.Get("Origin"); origin != "" {
+		u, err := url.Parse(origin)
+		if err != nil || u.Host != r.Host {
+			return errors.New("cross-origin request rejected (Origin: " + origin + ")")
+		}
+	}
+	return nil
+}
+
+// parsePersist 解析 persist 查询参数
+func parsePersist(r *http.Request) (bool, error) {
+	value := r.URL.Query().Get("persist")
+	if value == "" {
+		return false, nil
+	}
+	persist, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, errors.New("invalid persist parameter")
+	}
+	return persist, nil
+}
+
+// persistResult 将写回配置文件的结果转换为响应字段
+func persistResult(err error) (bool, string) {
+	if err != nil {
+		logger.Logger.Warnf("Admin API: failed to persist datasource change: %v", err)
+		return false, err.Error()
+	}
+	return true, ""
+}
+
+// writeAdminError 根据错误类型输出对应的状态码
+func writeAdminError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, db.ErrDatasourceNotFound):
+		writeJSONError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, db.ErrDiscoveredDatasource), errors.Is(err, db.ErrDatasourceDisabled):
+		writeJSONError(w, http.StatusConflict, err.Error())
+	default:
+		// 名称或地址重复、参数不合法等配置错误
+		writeJSONError(w, http.StatusBadRequest, err.Error())
+	}
+}
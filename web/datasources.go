@@ -0,0 +1,151 @@
+package web
+
+import (
+	"dameng_exporter/config"
+	"dameng_exporter/db"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+const (
+	// DatasourcesPath 数据源清单查询路径
+	DatasourcesPath = "/api/v1/datasources"
+	// DatasourcePath 单个数据源查询路径
+	DatasourcePath = "/api/v1/datasources/{name}"
+)
+
+// poolStatsView 连接池统计
+type poolStatsView struct {
+	MaxOpenConnections  int     `json:"maxOpenConnections"`
+	OpenConnections     int     `json:"openConnections"`
+	InUse               int     `json:"inUse"`
+	Idle                int     `json:"idle"`
+	WaitCount           int64   `json:"waitCount"`
+	WaitDurationSeconds float64 `json:"waitDurationSeconds"`
+	MaxIdleClosed       int64   `json:"maxIdleClosed"`
+	MaxLifetimeClosed   int64   `json:"maxLifetimeClosed"`
+}
+
+// datasourceView 数据源清单中的单个数据源，不包含任何密码信息
+type datasourceView struct {
+	Name            string            `json:"name"`
+	Description     string            `json:"description,omitempty"`
+	Host            string            `json:"host"`
+	Labels          map[string]string `json:"labels"`
+	Enabled         bool              `json:"enabled"`
+	State           string            `json:"state"`
+	Healthy         bool              `json:"healthy"`
+	LastHealthCheck *time.Time        `json:"lastHealthCheck,omitempty"`
+	LastError       string            `json:"lastError,omitempty"`
+	FailedAt        *time.Time        `json:"failedAt,omitempty"`
+	DiscoveredFrom  string            `json:"discoveredFrom,omitempty"`
+	Pool            *poolStatsView    `json:"pool,omitempty"`
+	Collectors      []string          `json:"collectors"`
+}
+
+// datasourcesResponse 数据源清单查询结果
+type datasourcesResponse struct {
+	Total       int              `json:"total"`
+	Healthy     int              `json:"healthy"`
+	Datasources []datasourceView `json:"datasources"`
+}
+
+// DatasourcesHandler 数据源清单处理器，返回配置文件中的数据源与自动发现的成员
+func DatasourcesHandler(poolManager *db.DBPoolManager) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		snapshots := poolManager.DatasourceSnapshots()
+		resp := datasourcesResponse{Datasources: make([]datasourceView, 0, len(snapshots))}
+		for _, snapshot := range snapshots {
+			view := newDatasourceView(snapshot)
+			if view.Healthy {
+				resp.Healthy++
+			}
+			resp.Datasources = append(resp.Datasources, view)
+		}
+		resp.Total = len(resp.Datasources)
+		writeJSON(w, http.StatusOK, resp)
+	})
+}
+
+// DatasourceHandler 单个数据源查询处理器，数据源不存在时返回404
+func DatasourceHandler(poolManager *db.DBPoolManager) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := r.PathValue("name")
+		snapshot, ok := poolManager.DatasourceSnapshot(name)
+		if !ok {
+			writeJSONError(w, http.StatusNotFound, fmt.Sprintf("datasource %q not found", name))
+			return
+		}
+		writeJSON(w, http.StatusOK, newDatasourceView(snapshot))
+	})
+}
+
+// newDatasourceView 将数据源快照转换为接口输出结构
+func newDatasourceView(snapshot db.DatasourceSnapshot) datasourceView {
+	cfg := snapshot.Config
+	view := datasourceView{
+		Name:           cfg.Name,
+		Description:    cfg.Description,
+		Host:           snapshot.Host,
+		Labels:         snapshot.Labels,
+		Enabled:        cfg.Enabled,
+		State:          snapshot.State,
+		Healthy:        snapshot.Healthy,
+		LastError:      snapshot.LastError,
+		DiscoveredFrom: cfg.DiscoveredFrom,
+		Collectors:     enabledCollectorGroups(cfg),
+	}
+	if !snapshot.LastHealthCheck.IsZero() {
+		ts := snapshot.LastHealthCheck
+		view.LastHealthCheck = &ts
+	}
+	if !snapshot.FailedAt.IsZero() {
+		ts := snapshot.FailedAt
+		view.FailedAt = &ts
+	}
+	if stats := snapshot.PoolStats; stats != nil {
+		view.Pool = &poolStatsView{
+			MaxOpenConnections:  stats.MaxOpenConnections,
+			OpenConnections:     stats.OpenConnections,
+			InUse:               stats.InUse,
+			Idle:                stats.Idle,
+			WaitCount:           stats.WaitCount,
+			WaitDurationSeconds: stats.WaitDuration.Seconds(),
+			MaxIdleClosed:       stats.MaxIdleClosed,
+			MaxLifetimeClosed:   stats.MaxLifetimeClosed,
+		}
+	}
+	return view
+}
+
+// enabledCollectorGroups 返回数据源启用的指标分组
+func enabledCollectorGroups(cfg *config.DataSourceConfig) []string {
+	groups := make([]string, 0, 4)
+	if cfg.RegisterHostMetrics {
+		groups = append(groups, "host")
+	}
+	if cfg.RegisterDatabaseMetrics {
+		groups = append(groups, "database")
+	}
+	if cfg.RegisterDmhsMetrics {
+		groups = append(groups, "dmhs")
+	}
+	if cfg.RegisterCustomMetrics {
+		groups = append(groups, "custom")
+	}
+	return groups
+}
+
+// writeJSON 输出 JSON 响应
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+// writeJSONError 输出 JSON 格式的错误响应
+func writeJSONError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
@@ -0,0 +1,49 @@
+package web
+
+import (
+	"dameng_exporter/notify"
+	"net/http"
+	"strconv"
+)
+
+const (
+	// EventsPath 数据源事件历史查询路径
+	EventsPath = "/api/v1/events"
+
+	defaultEventsLimit = 50
+)
+
+// eventsResponse 事件历史查询结果
+type eventsResponse struct {
+	Capacity int             `json:"capacity"`
+	Count    int             `json:"count"`
+	Events   []notify.Record `json:"events"`
+}
+
+// EventsHandler 数据源事件历史查询处理器，支持 datasource 与 limit 参数，按时间倒序返回
+func EventsHandler(history *notify.History) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", http.MethodGet)
+			writeJSONError(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+
+		limit := defaultEventsLimit
+		if value := r.URL.Query().Get("limit"); value != "" {
+			parsed, err := strconv.Atoi(value)
+			if err != nil || parsed <= 0 {
+				writeJSONError(w, http.StatusBadRequest, "invalid limit")
+				return
+			}
+			limit = parsed
+		}
+
+		events := history.List(r.URL.Query().Get("datasource"), limit)
+		writeJSON(w, http.StatusOK, eventsResponse{
+			Capacity: history.Capacity(),
+			Count:    len(events),
+			Events:   events,
+		})
+	})
+}
@@ -0,0 +1,94 @@
+package web
+
+import (
+	"dameng_exporter/db"
+	"dameng_exporter/logger"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	// LogLevelPath 运行时日志级别查询与修改路径
+	LogLevelPath = "/-/loglevel"
+
+	// maxLogLevelBody 修改日志级别请求体的最大字节数
+	maxLogLevelBody = 4 * 1024
+	// levelDefault 恢复为配置的日志级别
+	levelDefault = "default"
+)
+
+// logLevelRequest 修改日志级别的请求
+type logLevelRequest struct {
+	Level           string `json:"level"`                     // debug/info/warn/error，default 表示恢复为配置的级别
+	Datasource      string `json:"datasource,omitempty"`      // 为空时修改全局日志级别，否则只修改该数据源相关日志的级别
+	DurationSeconds int    `json:"durationSeconds,omitempty"` // 大于0时到期后自动恢复
+}
+
+// LogLevelHandler 运行时日志级别处理器：GET 返回当前级别，PUT 修改全局或单个数据源的级别
+func LogLevelHandler(poolManager *db.DBPoolManager) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			writeJSON(w, http.StatusOK, logger.GetLevelStatus())
+			return
+		}
+
+		if err := checkSameSite(r); err != nil {
+			writeJSONError(w, http.StatusForbidden, err.Error())
+			return
+		}
+		req, err := parseLogLevelRequest(r)
+		if err != nil {
+			writeJSONError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if req.Datasource != "" {
+			if _, ok := poolManager.DatasourceSnapshot(req.Datasource); !ok {
+				writeJSONError(w, http.StatusNotFound, fmt.Sprintf("datasource %s not found", req.Datasource))
+				return
+			}
+		}
+		duration := time.Duration(req.DurationSeconds) * time.Second
+
+		switch {
+		case req.Level == levelDefault && req.Datasource != "":
+			logger.ResetDatasourceLevel(req.Datasource)
+		case req.Level == levelDefault:
+			logger.SetLevel(logger.GetConfiguredLevel(), 0)
+		default:
+			level, _ := logger.ParseLevel(req.Level)
+			if req.Datasource != "" {
+				logger.SetDatasourceLevel(req.Datasource, level, duration)
+			} else {
+				logger.SetLevel(level, duration)
+			}
+		}
+		logger.Logger.Infof("Admin API: set log level %s (datasource=%q, duration=%s) from %s",
+			req.Level, req.Datasource, duration, r.RemoteAddr)
+		writeJSON(w, http.StatusOK, logger.GetLevelStatus())
+	})
+}
+
+// parseLogLevelRequest 解析并验证修改日志级别的请求
+func parseLogLevelRequest(r *http.Request) (logLevelRequest, error) {
+	var req logLevelRequest
+	decoder := json.NewDecoder(io.LimitReader(r.Body, maxLogLevelBody))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&req); err != nil {
+		return req, fmt.Errorf("invalid request: %w", err)
+	}
+
+	req.Level = strings.ToLower(strings.TrimSpace(req.Level))
+	if req.Level != levelDefault {
+		if _, err := logger.ParseLevel(req.Level); err != nil {
+			return req, fmt.Errorf("invalid log level %q, must be debug, info, warn, error or default", req.Level)
+		}
+	}
+	if req.DurationSeconds < 0 {
+		return req, fmt.Errorf("durationSeconds must not be negative")
+	}
+	return req, nil
+}
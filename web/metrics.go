@@ -0,0 +1,82 @@
+package web
+
+import (
+	"dameng_exporter/collector"
+	"dameng_exporter/config"
+	"dameng_exporter/db"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	dto "github.com/prometheus/client_model/go"
+)
+
+const (
+	// datasourceParam 按数据源过滤的查询参数，可重复
+	datasourceParam = "datasource"
+	// collectParam 按采集器过滤的查询参数，可重复
+	collectParam = "collect[]"
+)
+
+// MetricsHandler 指标处理器。未携带 datasource 与 collect[] 参数时使用全局注册器，
+// 否则为本次请求创建独立注册器，只对选中的数据源执行选中的采集器。
+// 相同过滤条件的并发抓取由 scrapes 合并为一次采集，超出并发上限时返回503
+func MetricsHandler(reg *prometheus.Registry, poolManager *db.DBPoolManager, scrapes *ScrapeCoordinator) http.Handler {
+	// 启用 OpenMetrics 时，客户端协商后输出计数器的 _created 时间与 exemplar
+	enableOpenMetrics := config.Global.GetEnableOpenMetrics()
+	opts := promhttp.HandlerOpts{
+		EnableOpenMetrics:                   enableOpenMetrics,
+		EnableOpenMetricsTextCreatedSamples: enableOpenMetrics,
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		filter, err := collector.NewScrapeFilter(query[datasourceParam], query[collectParam])
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		var gatherer prometheus.Gatherer = reg
+		if !filter.IsEmpty() && poolManager != nil {
+			for name := range filter.DataSources {
+				if _, ok := poolManager.DatasourceSnapshot(name); !ok {
+					http.Error(w, fmt.Sprintf("unknown datasource %q", name), http.StatusBadRequest)
+					return
+				}
+			}
+			gatherer = collector.NewFilteredRegistry(poolManager, filter)
+		}
+
+		families, err := scrapes.Gather(scrapeKey(filter), gatherer.Gather)
+		if errors.Is(err, errTooManyScrapes) {
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+
+		promhttp.HandlerFor(prometheus.GathererFunc(func() ([]*dto.MetricFamily, error) {
+			return families, err
+		}), opts).ServeHTTP(w, r)
+	})
+}
+
+// scrapeKey 根据过滤条件生成合并抓取使用的键，不带过滤条件时为空字符串
+func scrapeKey(filter *collector.ScrapeFilter) string {
+	if filter.IsEmpty() {
+		return ""
+	}
+	return "datasource=" + joinSorted(filter.DataSources) + ";collect=" + joinSorted(filter.Collectors)
+}
+
+// joinSorted 按名称排序后拼接集合中的元素
+func joinSorted(set map[string]bool) string {
+	names := make([]string, 0, len(set))
+	for name := range set {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ",")
+}
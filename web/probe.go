@@ -0,0 +1,68 @@
+package web
+
+import (
+	"dameng_exporter/config"
+	"dameng_exporter/db"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+)
+
+const (
+	// HealthyPath 存活探针路径，进程存活即返回200
+	HealthyPath = "/-/healthy"
+	// ReadyPath 就绪探针路径，配置加载完成且健康数据源数量达标时返回200
+	ReadyPath = "/-/ready"
+)
+
+// shuttingDown 标记进程是否已进入停机流程，停机期间就绪探针始终返回503
+var shuttingDown atomic.Bool
+
+// MarkShuttingDown 标记进入停机流程，使负载均衡及时摘除流量
+func MarkShuttingDown() {
+	shuttingDown.Store(true)
+}
+
+// IsShuttingDown 返回是否处于停机流程中
+func IsShuttingDown() bool {
+	return shuttingDown.Load()
+}
+
+// HealthyHandler 存活探针处理器
+func HealthyHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintln(w, "DAMENG Exporter is Healthy.")
+	})
+}
+
+// ReadyHandler 就绪探针处理器
+func ReadyHandler(poolManager *db.DBPoolManager) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+
+		if IsShuttingDown() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			fmt.Fprintln(w, "DAMENG Exporter is shutting down.")
+			return
+		}
+
+		if config.GlobalMultiConfig == nil || poolManager == nil {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			fmt.Fprintln(w, "DAMENG Exporter is not ready: configuration not loaded.")
+			return
+		}
+
+		required := config.Global.GetReadyMinHealthyDatasources()
+		healthy := len(poolManager.GetPools())
+		if healthy < required {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			fmt.Fprintf(w, "DAMENG Exporter is not ready: %d healthy datasource(s), %d required.\n", healthy, required)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintf(w, "DAMENG Exporter is Ready. (%d healthy datasource(s))\n", healthy)
+	})
+}
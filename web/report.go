@@ -0,0 +1,65 @@
+package web
+
+import (
+	"bytes"
+	"dameng_exporter/collector"
+	"dameng_exporter/config"
+	"dameng_exporter/db"
+	"dameng_exporter/logger"
+	"dameng_exporter/report"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+// ReportPath 巡检报告路径
+const ReportPath = "/report"
+
+// ReportHandler 巡检报告处理器，执行一次巡检所需的采集器并按阈值生成报告，
+// format 参数可选 html（默认）或 markdown。采集与 /metrics 共用合并与并发限制，超出并发上限时返回503
+func ReportHandler(poolManager *db.DBPoolManager, scrapes *ScrapeCoordinator) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		format := r.URL.Query().Get("format")
+		if format == "" {
+			format = report.FormatHTML
+		}
+		if !slices.Contains(report.InspectionFormats, format) {
+			http.Error(w, fmt.Sprintf("unsupported format %q, must be one of %s", format, strings.Join(report.InspectionFormats, ", ")), http.StatusBadRequest)
+			return
+		}
+
+		filter, err := collector.NewScrapeFilter(nil, report.InspectionCollectors)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		families, err := scrapes.Gather(scrapeKey(filter), collector.NewFilteredRegistry(poolManager, filter).Gather)
+		if errors.Is(err, errTooManyScrapes) {
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+		if err != nil {
+			// 部分采集失败时仍按已采集到的数据生成报告，缺失的检查项显示为无数据
+			logger.Logger.Warnf("Inspection report collection finished with errors: %v", err)
+		}
+
+		inspection := report.Inspect(poolManager, families, collector.CollectorRuns(), config.Global.GetReport(), config.GetVersion(), time.Now())
+
+		// 先渲染到缓冲区，模板出错时返回500而不是输出半个页面
+		var buf bytes.Buffer
+		if err := report.WriteInspection(&buf, inspection, format); err != nil {
+			logger.Logger.Errorf("Failed to render inspection report: %v", err)
+			http.Error(w, "failed to render inspection report", http.StatusInternalServerError)
+			return
+		}
+		if format == report.FormatMarkdown {
+			w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+		} else {
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		}
+		w.Write(buf.Bytes())
+	})
+}
@@ -0,0 +1,137 @@
+package web
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// errTooManyScrapes 同时执行的采集数量已达上限
+var errTooManyScrapes = errors.New("too many concurrent scrapes")
+
+// 抓取结果分类
+const (
+	scrapeResultCollected = "collected" // 实际执行了采集
+	scrapeResultCoalesced = "coalesced" // 复用了其他抓取的采集结果
+	scrapeResultRejected  = "rejected"  // 超出并发上限被拒绝
+)
+
+// scrapeCall 一次采集及其结果
+type scrapeCall struct {
+	done     chan struct{} // 采集完成后关闭
+	families []*dto.MetricFamily
+	err      error
+	expires  time.Time // 结果可复用的截止时间，采集完成前为零值
+}
+
+// ScrapeCoordinator 合并相同的并发抓取并限制同时执行的采集数量，
+// 同一组过滤条件在采集进行中或完成后的合并窗口内到达的抓取直接复用同一份结果，不再重复查询数据库
+type ScrapeCoordinator struct {
+	mu     sync.Mutex
+	calls  map[string]*scrapeCall
+	window time.Duration
+	slots  chan struct{} // 并发采集名额，nil 表示不限制
+
+	scrapes  *prometheus.CounterVec
+	inFlight prometheus.Gauge
+}
+
+// NewScrapeCoordinator 创建抓取协调器，window 为0时不合并，maxConcurrent 为0时不限制并发
+func NewScrapeCoordinator(window time.Duration, maxConcurrent int) *ScrapeCoordinator {
+	c := &ScrapeCoordinator{
+		calls:  make(map[string]*scrapeCall),
+		window: window,
+		scrapes: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "dameng_exporter_scrapes_total",
+			Help: "Total number of metrics scrapes by result (collected, coalesced, rejected)",
+		}, []string{"result"}),
+		inFlight: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "dameng_exporter_scrapes_in_flight",
+			Help: "Current number of collections being executed",
+		}),
+	}
+	if maxConcurrent > 0 {
+		c.slots = make(chan struct{}, maxConcurrent)
+	}
+	// 预先初始化各分类，保证从0开始输出
+	for _, result := range []string{scrapeResultCollected, scrapeResultCoalesced, scrapeResultRejected} {
+		c.scrapes.WithLabelValues(result)
+	}
+	return c
+}
+
+// Describe 实现 prometheus.Collector 接口
+func (c *ScrapeCoordinator) Describe(ch chan<- *prometheus.Desc) {
+	c.scrapes.Describe(ch)
+	c.inFlight.Describe(ch)
+}
+
+// Collect 实现 prometheus.Collector 接口
+func (c *ScrapeCoordinator) Collect(ch chan<- prometheus.Metric) {
+	c.scrapes.Collect(ch)
+	c.inFlight.Collect(ch)
+}
+
+// Gather 执行或复用 key 对应的采集，超出并发上限时返回 errTooManyScrapes
+func (c *ScrapeCoordinator) Gather(key string, gather func() ([]*dto.MetricFamily, error)) ([]*dto.MetricFamily, error) {
+	now := time.Now()
+
+	c.mu.Lock()
+	if call, ok := c.calls[key]; ok && (call.expires.IsZero() || now.Before(call.expires)) {
+		c.mu.Unlock()
+		<-call.done
+		c.scrapes.WithLabelValues(scrapeResultCoalesced).Inc()
+		return call.families, call.err
+	}
+
+	if c.slots != nil {
+		select {
+		case c.slots <- struct{}{}:
+		default:
+			c.mu.Unlock()
+			c.scrapes.WithLabelValues(scrapeResultRejected).Inc()
+			return nil, errTooManyScrapes
+		}
+	}
+
+	call := &scrapeCall{done: make(chan struct{})}
+	if c.window > 0 {
+		c.removeExpiredLocked(now)
+		c.calls[key] = call
+	}
+	c.mu.Unlock()
+
+	c.inFlight.Inc()
+	call.families, call.err = gather()
+	c.inFlight.Dec()
+	if c.slots != nil {
+		<-c.slots
+	}
+	c.scrapes.WithLabelValues(scrapeResultCollected).Inc()
+
+	c.mu.Lock()
+	if call.err != nil {
+		// 失败的结果不复用，后续抓取重新采集
+		if c.calls[key] == call {
+			delete(c.calls, key)
+		}
+	} else {
+		call.expires = time.Now().Add(c.window)
+	}
+	c.mu.Unlock()
+	close(call.done)
+
+	return call.families, call.err
+}
+
+// removeExpiredLocked 清理已过期的采集结果，调用方需持有 c.mu
+func (c *ScrapeCoordinator) removeExpiredLocked(now time.Time) {
+	for key, call := range c.calls {
+		if !call.expires.IsZero() && !now.Before(call.expires) {
+			delete(c.calls, key)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package web
+
+import (
+	"bytes"
+	"dameng_exporter/collector"
+	"dameng_exporter/config"
+	"dameng_exporter/db"
+	"dameng_exporter/logger"
+	"embed"
+	"html/template"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+)
+
+//go:embed templates/status.html
+var templateFS embed.FS
+
+// statusTemplate 状态页模板
+var statusTemplate = template.Must(template.New("status.html").Funcs(template.FuncMap{
+	"formatTime": func(t time.Time) string {
+		if t.IsZero() {
+			return "-"
+		}
+		return t.Format("2006-01-02 15:04:05")
+	},
+	"milliseconds": func(d time.Duration) int64 {
+		return d.Milliseconds()
+	},
+}).ParseFS(templateFS, "templates/status.html"))
+
+// secretPattern 匹配配置摘要中的密码、密钥类字段（encodeConfigPwd 为开关，不在其中）
+var secretPattern = regexp.MustCompile(`(?i)\b(\w*password=|\w*secret=|\w*token=|dbPwd=|discoveryPwd=)[^,\s]+`)
+
+// StatusOptions 状态页展示的进程信息
+type StatusOptions struct {
+	Version    string
+	MetricPath string
+	StartTime  time.Time
+	AdminAPI   bool // 是否显示运维操作按钮
+}
+
+// statusPage 状态页渲染数据
+type statusPage struct {
+	StatusOptions
+	Now             time.Time
+	Uptime          time.Duration
+	Summary         string
+	Datasources     []datasourceView
+	Healthy         int
+	Runs            []collector.CollectorRun
+	CustomFiles     []collector.CustomMetricsFile
+	DatasourcesPath string
+	EventsPath      string
+	HealthyPath     string
+	ReadyPath       string
+}
+
+// StatusHandler 状态页处理器，展示配置摘要、数据源状态、采集器最近运行结果与自定义指标文件
+func StatusHandler(poolManager *db.DBPoolManager, opts StatusOptions) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+
+		now := time.Now()
+		page := statusPage{
+			StatusOptions:   opts,
+			Now:             now,
+			Uptime:          now.Sub(opts.StartTime).Truncate(time.Second),
+			Runs:            collector.CollectorRuns(),
+			CustomFiles:     collector.CustomMetricsFiles(),
+			DatasourcesPath: DatasourcesPath,
+			EventsPath:      EventsPath,
+			HealthyPath:     HealthyPath,
+			ReadyPath:       ReadyPath,
+		}
+		if config.GlobalMultiConfig != nil {
+			page.Summary = maskSecrets(config.GlobalMultiConfig.StringCategorized())
+		}
+		if poolManager != nil {
+			for _, snapshot := range poolManager.DatasourceSnapshots() {
+				view := newDatasourceView(snapshot)
+				if view.Healthy {
+					page.Healthy++
+				}
+				page.Datasources = append(page.Datasources, view)
+			}
+		}
+
+		// 先渲染到缓冲区，模板出错时返回500而不是输出半个页面
+		var buf bytes.Buffer
+		if err := statusTemplate.Execute(&buf, page); err != nil {
+			logger.Logger.Errorf("Failed to render status page: %v", err)
+			http.Error(w, "failed to render status page", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write(buf.Bytes())
+	})
+}
+
+// maskSecrets 屏蔽配置摘要中密码、密钥类字段的值
+func maskSecrets(summary string) string {
+	return strings.TrimSpace(secretPattern.ReplaceAllString(summary, "${1}******"))
+}
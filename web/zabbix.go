@@ -0,0 +1,65 @@
+package web
+
+import (
+	"dameng_exporter/collector"
+	"dameng_exporter/config"
+	"dameng_exporter/db"
+	"dameng_exporter/logger"
+	"dameng_exporter/push"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// ZabbixDiscoveryPath Zabbix 低级别发现路径，entity 为发现实体名称
+const ZabbixDiscoveryPath = "/zabbix/discovery/{entity}"
+
+// ZabbixDiscoveryHandler Zabbix 低级别发现处理器，返回 {"data":[...]} 格式的发现数据，
+// 供 Zabbix HTTP agent 类型的发现规则使用。实体数据来自对应采集器的标签，只运行该实体的采集器，
+// 可通过 datasource 参数限定单个数据源
+func ZabbixDiscoveryHandler(poolManager *db.DBPoolManager, scrapes *ScrapeCoordinator) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		entity, ok := push.FindDiscoveryEntity(r.PathValue("entity"))
+		if !ok {
+			writeJSONError(w, http.StatusNotFound, fmt.Sprintf("unknown discovery entity %q, available: %s",
+				r.PathValue("entity"), strings.Join(push.DiscoveryEntityNames(), ", ")))
+			return
+		}
+
+		snapshots := poolManager.DatasourceSnapshots()
+		var dataSources []string
+		if name := r.URL.Query().Get(datasourceParam); name != "" {
+			snapshot, ok := poolManager.DatasourceSnapshot(name)
+			if !ok {
+				writeJSONError(w, http.StatusBadRequest, fmt.Sprintf("unknown datasource %q", name))
+				return
+			}
+			snapshots = []db.DatasourceSnapshot{snapshot}
+			dataSources = []string{name}
+		}
+
+		var families []*dto.MetricFamily
+		if entity.Collector != "" {
+			filter, err := collector.NewScrapeFilter(dataSources, []string{entity.Collector})
+			if err != nil {
+				writeJSONError(w, http.StatusInternalServerError, err.Error())
+				return
+			}
+			var gatherer prometheus.Gatherer = collector.NewFilteredRegistry(poolManager, filter)
+			families, err = scrapes.Gather(scrapeKey(filter), gatherer.Gather)
+			if errors.Is(err, errTooManyScrapes) {
+				writeJSONError(w, http.StatusServiceUnavailable, err.Error())
+				return
+			}
+			if err != nil {
+				// 部分数据源采集失败时仍返回已采集到的实体
+				logger.Logger.Warnf("Zabbix discovery of %s finished with errors: %v", entity.Name, err)
+			}
+		}
+		writeJSON(w, http.StatusOK, push.BuildDiscovery(entity, families, snapshots, config.Global.GetZabbixHostLabel(), ""))
+	})
+}